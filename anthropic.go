@@ -0,0 +1,613 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropic.go translates chat completions to the Anthropic Messages API
+// (https://docs.anthropic.com/en/api/messages) for clients configured with
+// APITypeAnthropic, so callers keep using ChatCompletionRequest,
+// ChatCompletionResponse and ChatCompletionStream.
+
+const (
+	anthropicMessagesSuffix = "/messages"
+	// AnthropicAPIKeyHeader is the header used to authenticate against the Anthropic API.
+	AnthropicAPIKeyHeader = "x-api-key"
+	// anthropicDefaultMaxTokens is sent when the request sets neither MaxCompletionTokens
+	// nor MaxTokens, because max_tokens is required by the Messages API.
+	anthropicDefaultMaxTokens = 4096
+)
+
+var (
+	ErrAnthropicMultipleChoices = errors.New("the Anthropic Messages API does not support N > 1")
+	ErrAnthropicUnsupportedRole = errors.New("message role is not supported by the Anthropic Messages API")
+)
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// image
+	Source *anthropicImageSource `json:"source,omitempty"`
+	// tool_use
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+	// tool_result
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	// thinking
+	Thinking string `json:"thinking,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type anthropicMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+type anthropicRequest struct {
+	Model         string               `json:"model"`
+	Messages      []anthropicMessage   `json:"messages"`
+	System        string               `json:"system,omitempty"`
+	MaxTokens     int                  `json:"max_tokens"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Temperature   *float32             `json:"temperature,omitempty"`
+	TopP          *float32             `json:"top_p,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Metadata      *anthropicMetadata   `json:"metadata,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u anthropicUsage) toUsage() Usage {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	usage := Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      prompt + u.OutputTokens,
+	}
+	if u.CacheReadInputTokens > 0 {
+		usage.PromptTokensDetails = &PromptTokensDetails{CachedTokens: u.CacheReadInputTokens}
+	}
+	return usage
+}
+
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	Role       string                  `json:"role"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+
+	httpHeader
+}
+
+func anthropicFinishReason(stopReason string) FinishReason {
+	switch stopReason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return FinishReasonStop
+	case "max_tokens":
+		return FinishReasonLength
+	case "tool_use":
+		return FinishReasonToolCalls
+	case "refusal":
+		return FinishReasonContentFilter
+	case "":
+		return FinishReasonNull
+	default:
+		return FinishReason(stopReason)
+	}
+}
+
+// toChatCompletionResponse converts a Messages API response to a chat completion.
+func (r *anthropicResponse) toChatCompletionResponse() ChatCompletionResponse {
+	message := ChatCompletionMessage{Role: ChatMessageRoleAssistant}
+	var text, reasoning strings.Builder
+	for _, block := range r.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		case "tool_use":
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:   block.ID,
+				Type: ToolTypeFunction,
+				Function: FunctionCall{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+	message.Content = text.String()
+	message.ReasoningContent = reasoning.String()
+
+	return ChatCompletionResponse{
+		ID:      r.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   r.Model,
+		Choices: []ChatCompletionChoice{{
+			Index:        0,
+			Message:      message,
+			FinishReason: anthropicFinishReason(r.StopReason),
+		}},
+		Usage:      r.Usage.toUsage(),
+		httpHeader: r.httpHeader,
+	}
+}
+
+// newAnthropicRequest converts a chat completion request to a Messages API request.
+func newAnthropicRequest(request ChatCompletionRequest) (anthropicRequest, error) {
+	if request.N > 1 {
+		return anthropicRequest{}, ErrAnthropicMultipleChoices
+	}
+
+	req := anthropicRequest{
+		Model:         request.Model,
+		MaxTokens:     request.MaxCompletionTokens,
+		StopSequences: request.Stop,
+		Stream:        request.Stream,
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = request.MaxTokens
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = anthropicDefaultMaxTokens
+	}
+	if request.Temperature != 0 {
+		temperature := request.Temperature
+		req.Temperature = &temperature
+	}
+	if request.TopP != 0 {
+		topP := request.TopP
+		req.TopP = &topP
+	}
+	if request.User != "" {
+		req.Metadata = &anthropicMetadata{UserID: request.User}
+	}
+
+	var system []string
+	for _, message := range request.Messages {
+		switch message.Role {
+		case ChatMessageRoleSystem, ChatMessageRoleDeveloper:
+			system = append(system, messageText(message))
+		case ChatMessageRoleUser, ChatMessageRoleAssistant, ChatMessageRoleTool:
+			converted, err := newAnthropicMessage(message)
+			if err != nil {
+				return anthropicRequest{}, err
+			}
+			req.Messages = appendAnthropicMessage(req.Messages, converted)
+		default:
+			return anthropicRequest{}, fmt.Errorf("%w: %s", ErrAnthropicUnsupportedRole, message.Role)
+		}
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	for _, tool := range request.Tools {
+		if tool.Type != ToolTypeFunction || tool.Function == nil {
+			continue
+		}
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	req.ToolChoice = newAnthropicToolChoice(request.ToolChoice, request.ParallelToolCalls)
+	return req, nil
+}
+
+// appendAnthropicMessage merges consecutive messages with the same role,
+// since the Messages API expects user and assistant turns to alternate.
+func appendAnthropicMessage(messages []anthropicMessage, message anthropicMessage) []anthropicMessage {
+	if n := len(messages); n > 0 && messages[n-1].Role == message.Role {
+		messages[n-1].Content = append(messages[n-1].Content, message.Content...)
+		return messages
+	}
+	return append(messages, message)
+}
+
+func messageText(message ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+	parts := make([]string, 0, len(message.MultiContent))
+	for _, part := range message.MultiContent {
+		if part.Type == ChatMessagePartTypeText {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func newAnthropicMessage(message ChatCompletionMessage) (anthropicMessage, error) {
+	if message.Role == ChatMessageRoleTool {
+		return anthropicMessage{
+			Role: ChatMessageRoleUser,
+			Content: []anthropicContentBlock{{
+				Type:      "tool_result",
+				ToolUseID: message.ToolCallID,
+				Content:   messageText(message),
+			}},
+		}, nil
+	}
+
+	converted := anthropicMessage{Role: message.Role}
+	if message.Content != "" {
+		converted.Content = append(converted.Content, anthropicContentBlock{Type: "text", Text: message.Content})
+	}
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case ChatMessagePartTypeText:
+			converted.Content = append(converted.Content, anthropicContentBlock{Type: "text", Text: part.Text})
+		case ChatMessagePartTypeImageURL:
+			if part.ImageURL == nil {
+				continue
+			}
+			converted.Content = append(converted.Content, anthropicContentBlock{
+				Type:   "image",
+				Source: newAnthropicImageSource(part.ImageURL.URL),
+			})
+		}
+	}
+	for _, call := range message.ToolCalls {
+		input := json.RawMessage(call.Function.Arguments)
+		if len(bytes.TrimSpace(input)) == 0 {
+			input = json.RawMessage("{}")
+		}
+		if !json.Valid(input) {
+			return anthropicMessage{}, fmt.Errorf("tool call %s has invalid JSON arguments", call.ID)
+		}
+		converted.Content = append(converted.Content, anthropicContentBlock{
+			Type:  "tool_use",
+			ID:    call.ID,
+			Name:  call.Function.Name,
+			Input: input,
+		})
+	}
+	return converted, nil
+}
+
+// newAnthropicImageSource converts an image URL or a base64 data URL
+// (data:image/png;base64,...) to an image source.
+func newAnthropicImageSource(imageURL string) *anthropicImageSource {
+	if !strings.HasPrefix(imageURL, "data:") {
+		return &anthropicImageSource{Type: "url", URL: imageURL}
+	}
+	meta, data, _ := strings.Cut(strings.TrimPrefix(imageURL, "data:"), ",")
+	return &anthropicImageSource{
+		Type:      "base64",
+		MediaType: strings.TrimSuffix(meta, ";base64"),
+		Data:      data,
+	}
+}
+
+func newAnthropicToolChoice(toolChoice, parallelToolCalls any) *anthropicToolChoice {
+	var choice *anthropicToolChoice
+	switch tc := toolChoice.(type) {
+	case string:
+		switch tc {
+		case "none":
+			choice = &anthropicToolChoice{Type: "none"}
+		case "required":
+			choice = &anthropicToolChoice{Type: "any"}
+		case "auto":
+			choice = &anthropicToolChoice{Type: "auto"}
+		}
+	case ToolChoice:
+		choice = &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+	case *ToolChoice:
+		if tc != nil {
+			choice = &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+		}
+	}
+
+	if parallel, ok := parallelToolCalls.(bool); ok && !parallel {
+		if choice == nil {
+			choice = &anthropicToolChoice{Type: "auto"}
+		}
+		choice.DisableParallelToolUse = choice.Type != "none"
+	}
+	return choice
+}
+
+func (c *Client) createAnthropicChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (response ChatCompletionResponse, err error) {
+	anthropicReq, err := newAnthropicRequest(request)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(anthropicMessagesSuffix),
+		withBody(anthropicReq),
+		withOptions(opts...),
+	)
+	if err != nil {
+		return
+	}
+
+	var anthropicResp anthropicResponse
+	err = c.sendRequest(req, &anthropicResp)
+	if err != nil {
+		return
+	}
+	response = anthropicResp.toChatCompletionResponse()
+	return
+}
+
+func (c *Client) createAnthropicChatCompletionStream(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (stream *ChatCompletionStream, err error) {
+	request.Stream = true
+	anthropicReq, err := newAnthropicRequest(request)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(anthropicMessagesSuffix),
+		withBody(anthropicReq),
+		withOptions(opts...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[ChatCompletionStreamResponse](c, req)
+	if err != nil {
+		return
+	}
+	includeUsage := request.StreamOptions != nil && request.StreamOptions.IncludeUsage
+	resp.reader = bufio.NewReader(newAnthropicStreamTranslator(resp.response.Body, includeUsage))
+	stream = &ChatCompletionStream{
+		streamReader: resp,
+	}
+	return
+}
+
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Message      *anthropicResponse     `json:"message,omitempty"`
+	Index        int                    `json:"index"`
+	ContentBlock *anthropicContentBlock `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		Thinking    string `json:"thinking"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+// anthropicStreamTranslator rewrites the named-event SSE stream of the Messages
+// API into the data-only chat completion chunk stream read by streamReader.
+type anthropicStreamTranslator struct {
+	source       *bufio.Reader
+	includeUsage bool
+
+	pending  bytes.Buffer
+	finished bool
+
+	id        string
+	model     string
+	created   int64
+	usage     anthropicUsage
+	toolIndex map[int]int
+}
+
+func newAnthropicStreamTranslator(body io.Reader, includeUsage bool) *anthropicStreamTranslator {
+	return &anthropicStreamTranslator{
+		source:       bufio.NewReader(body),
+		includeUsage: includeUsage,
+		created:      time.Now().Unix(),
+		toolIndex:    make(map[int]int),
+	}
+}
+
+func (t *anthropicStreamTranslator) Read(p []byte) (int, error) {
+	for t.pending.Len() == 0 {
+		if t.finished {
+			return 0, io.EOF
+		}
+		if err := t.translateNextEvent(); err != nil {
+			if t.pending.Len() > 0 {
+				break
+			}
+			return 0, err
+		}
+	}
+	return t.pending.Read(p)
+}
+
+// translateNextEvent reads one SSE event and queues the chunks it translates to.
+func (t *anthropicStreamTranslator) translateNextEvent() error {
+	var data []byte
+	for {
+		line, err := t.source.ReadBytes('\n')
+		trimmed := bytes.TrimSpace(line)
+		if bytes.HasPrefix(trimmed, []byte("data:")) {
+			data = append(data, bytes.TrimSpace(bytes.TrimPrefix(trimmed, []byte("data:")))...)
+		}
+		if len(trimmed) == 0 && len(data) > 0 {
+			break
+		}
+		if err != nil {
+			if len(data) > 0 {
+				break
+			}
+			return err
+		}
+	}
+
+	var event anthropicStreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+	return t.translate(&event)
+}
+
+//nolint:gocognit // one branch per Messages API event type
+func (t *anthropicStreamTranslator) translate(event *anthropicStreamEvent) error {
+	switch event.Type {
+	case "message_start":
+		if event.Message != nil {
+			t.id = event.Message.ID
+			t.model = event.Message.Model
+			t.usage = event.Message.Usage
+		}
+		return t.writeChunk(ChatCompletionStreamChoiceDelta{Role: ChatMessageRoleAssistant}, "")
+	case "content_block_start":
+		block := event.ContentBlock
+		if block == nil {
+			return nil
+		}
+		switch block.Type {
+		case "text":
+			if block.Text == "" {
+				return nil
+			}
+			return t.writeChunk(ChatCompletionStreamChoiceDelta{Content: block.Text}, "")
+		case "tool_use":
+			index := len(t.toolIndex)
+			t.toolIndex[event.Index] = index
+			return t.writeChunk(ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCall{{
+				Index:    &index,
+				ID:       block.ID,
+				Type:     ToolTypeFunction,
+				Function: FunctionCall{Name: block.Name},
+			}}}, "")
+		}
+		return nil
+	case "content_block_delta":
+		switch event.Delta.Type {
+		case "text_delta":
+			return t.writeChunk(ChatCompletionStreamChoiceDelta{Content: event.Delta.Text}, "")
+		case "thinking_delta":
+			return t.writeChunk(ChatCompletionStreamChoiceDelta{ReasoningContent: event.Delta.Thinking}, "")
+		case "input_json_delta":
+			index := t.toolIndex[event.Index]
+			return t.writeChunk(ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCall{{
+				Index:    &index,
+				Function: FunctionCall{Arguments: event.Delta.PartialJSON},
+			}}}, "")
+		}
+		return nil
+	case "message_delta":
+		if event.Usage != nil {
+			t.usage.OutputTokens = event.Usage.OutputTokens
+		}
+		if event.Delta.StopReason == "" {
+			return nil
+		}
+		return t.writeChunk(ChatCompletionStreamChoiceDelta{}, anthropicFinishReason(event.Delta.StopReason))
+	case "message_stop":
+		if t.includeUsage {
+			usage := t.usage.toUsage()
+			if err := t.writeData(ChatCompletionStreamResponse{
+				ID:      t.id,
+				Object:  "chat.completion.chunk",
+				Created: t.created,
+				Model:   t.model,
+				Choices: []ChatCompletionStreamChoice{},
+				Usage:   &usage,
+			}); err != nil {
+				return err
+			}
+		}
+		t.pending.WriteString("data: [DONE]\n\n")
+		t.finished = true
+		return nil
+	case "error":
+		t.pending.WriteString(`data: {"error":`)
+		t.pending.Write(event.Error)
+		t.pending.WriteString("}\n\n")
+		t.finished = true
+		return nil
+	default:
+		// ping, content_block_stop and unknown events carry nothing to translate.
+		return nil
+	}
+}
+
+func (t *anthropicStreamTranslator) writeChunk(delta ChatCompletionStreamChoiceDelta, finishReason FinishReason) error {
+	return t.writeData(ChatCompletionStreamResponse{
+		ID:      t.id,
+		Object:  "chat.completion.chunk",
+		Created: t.created,
+		Model:   t.model,
+		Choices: []ChatCompletionStreamChoice{{
+			Index:        0,
+			Delta:        delta,
+			FinishReason: finishReason,
+		}},
+	})
+}
+
+func (t *anthropicStreamTranslator) writeData(chunk ChatCompletionStreamResponse) error {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	t.pending.WriteString("data: ")
+	t.pending.Write(data)
+	t.pending.WriteString("\n\n")
+	return nil
+}
@@ -0,0 +1,287 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+func TestAnthropicChatCompletionRequestTranslation(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+
+	var got map[string]any
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(openai.AnthropicAPIKeyHeader) == "" || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("missing Anthropic auth headers: %v", r.Header)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-0",
+			"content":[{"type":"text","text":"It is sunny."}],"stop_reason":"end_turn",
+			"usage":{"input_tokens":10,"output_tokens":4}}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:               "claude-sonnet-4-0",
+		MaxCompletionTokens: 256,
+		Stop:                []string{"END"},
+		Temperature:         0.5,
+		User:                "user-1",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is in this image?"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{
+					URL: "data:image/png;base64,aGVsbG8=",
+				}},
+			}},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+				ID:       "toolu_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "toolu_1", Content: "sunny"},
+			{Role: openai.ChatMessageRoleUser, Content: "Summarize."},
+		},
+		Tools: []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{
+			Name: "get_weather",
+			Parameters: jsonschema.Definition{
+				Type:       jsonschema.Object,
+				Properties: map[string]jsonschema.Definition{"city": {Type: jsonschema.String}},
+			},
+		}}},
+		ToolChoice: "required",
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	want := map[string]any{
+		"model":          "claude-sonnet-4-0",
+		"system":         "Be brief.",
+		"max_tokens":     float64(256),
+		"stop_sequences": []any{"END"},
+		"temperature":    0.5,
+		"metadata":       map[string]any{"user_id": "user-1"},
+		"tool_choice":    map[string]any{"type": "any"},
+		"tools": []any{map[string]any{
+			"name": "get_weather",
+			"input_schema": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			},
+		}},
+		"messages": []any{
+			map[string]any{"role": "user", "content": []any{
+				map[string]any{"type": "text", "text": "What is in this image?"},
+				map[string]any{"type": "image", "source": map[string]any{
+					"type": "base64", "media_type": "image/png", "data": "aGVsbG8=",
+				}},
+			}},
+			map[string]any{"role": "assistant", "content": []any{
+				map[string]any{"type": "tool_use", "id": "toolu_1", "name": "get_weather",
+					"input": map[string]any{"city": "Paris"}},
+			}},
+			map[string]any{"role": "user", "content": []any{
+				map[string]any{"type": "tool_result", "tool_use_id": "toolu_1", "content": "sunny"},
+				map[string]any{"type": "text", "text": "Summarize."},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Fatalf("unexpected Anthropic request:\n%s", gotJSON)
+	}
+}
+
+func TestAnthropicChatCompletionResponse(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-0",
+			"content":[{"type":"text","text":"Let me check."},
+				{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":4}}`)
+	})
+
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-0",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Weather?"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if resp.ID != "msg_1" || resp.Model != "claude-sonnet-4-0" || len(resp.Choices) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason != openai.FinishReasonToolCalls || choice.Message.Content != "Let me check." {
+		t.Fatalf("unexpected choice: %+v", choice)
+	}
+	if len(choice.Message.ToolCalls) != 1 || choice.Message.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("unexpected tool calls: %+v", choice.Message.ToolCalls)
+	}
+	if resp.Usage.PromptTokens != 10 || resp.Usage.CompletionTokens != 4 || resp.Usage.TotalTokens != 14 {
+		t.Fatalf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicChatCompletionErrors(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens too large"}}`)
+	})
+
+	request := openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-0",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest ||
+		apiErr.Message != "max_tokens too large" {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+
+	request.N = 2
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	if !errors.Is(err, openai.ErrAnthropicMultipleChoices) {
+		t.Fatalf("expected ErrAnthropicMultipleChoices, got %v", err)
+	}
+
+	request.N = 0
+	request.Messages = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleFunction, Content: "x"}}
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	if !errors.Is(err, openai.ErrAnthropicUnsupportedRole) {
+		t.Fatalf("expected ErrAnthropicUnsupportedRole, got %v", err)
+	}
+}
+
+func TestAnthropicChatCompletionStream(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["stream"] != true {
+			t.Errorf("expected stream to be requested, got %v", req["stream"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant",` +
+				`"model":"claude-sonnet-4-0","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}`,
+			`event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`event: ping
+data: {"type":"ping"}`,
+			`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}`,
+			`event: content_block_stop
+data: {"type":"content_block_stop","index":0}`,
+			`event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1",` +
+				`"name":"get_weather","input":{}}}`,
+			`event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+			`event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			`event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":9}}`,
+			`event: message_stop
+data: {"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			fmt.Fprint(w, event+"\n\n")
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:         "claude-sonnet-4-0",
+		Messages:      []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Weather?"}},
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var (
+		content, arguments, toolID string
+		finishReason               openai.FinishReason
+		usage                      *openai.Usage
+	)
+	for {
+		chunk, streamErr := stream.Recv()
+		if errors.Is(streamErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, streamErr, "stream.Recv error")
+		if chunk.ID != "msg_1" || chunk.Model != "claude-sonnet-4-0" {
+			t.Fatalf("unexpected chunk metadata: %+v", chunk)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			content += choice.Delta.Content
+			for _, call := range choice.Delta.ToolCalls {
+				if call.Index == nil || *call.Index != 0 {
+					t.Fatalf("unexpected tool call index: %+v", call)
+				}
+				if call.ID != "" {
+					toolID = call.ID
+				}
+				arguments += call.Function.Arguments
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+
+	if content != "Checking" || toolID != "toolu_1" || arguments != `{"city":"Paris"}` {
+		t.Fatalf("unexpected stream content %q, tool %q, arguments %q", content, toolID, arguments)
+	}
+	if finishReason != openai.FinishReasonToolCalls {
+		t.Fatalf("unexpected finish reason %q", finishReason)
+	}
+	if usage == nil || usage.PromptTokens != 12 || usage.CompletionTokens != 9 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+}
+
+func TestAnthropicChatCompletionStreamError(t *testing.T) {
+	client, server, teardown := setupAnthropicTestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/messages", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: error\n"+
+			`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`+"\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-0",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Overloaded" {
+		t.Fatalf("expected stream APIError, got %v", err)
+	}
+}
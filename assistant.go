@@ -130,14 +130,22 @@ type AssistantFileRequest struct {
 
 type AssistantFilesList struct {
 	AssistantFiles []AssistantFile `json:"data"`
+	LastID         *string         `json:"last_id"`
+	FirstID        *string         `json:"first_id"`
+	HasMore        bool            `json:"has_more"`
 
 	httpHeader
 }
 
 // CreateAssistant creates a new assistant.
-func (c *Client) CreateAssistant(ctx context.Context, request AssistantRequest) (response Assistant, err error) {
+func (c *Client) CreateAssistant(
+	ctx context.Context,
+	request AssistantRequest,
+	opts ...RequestOption,
+) (response Assistant, err error) {
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(assistantsSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -150,10 +158,12 @@ func (c *Client) CreateAssistant(ctx context.Context, request AssistantRequest)
 func (c *Client) RetrieveAssistant(
 	ctx context.Context,
 	assistantID string,
+	opts ...RequestOption,
 ) (response Assistant, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
+	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, url.PathEscape(assistantID))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -167,10 +177,12 @@ func (c *Client) ModifyAssistant(
 	ctx context.Context,
 	assistantID string,
 	request AssistantRequest,
+	opts ...RequestOption,
 ) (response Assistant, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
+	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, url.PathEscape(assistantID))
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -183,10 +195,12 @@ func (c *Client) ModifyAssistant(
 func (c *Client) DeleteAssistant(
 	ctx context.Context,
 	assistantID string,
+	opts ...RequestOption,
 ) (response AssistantDeleteResponse, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, assistantID)
+	urlSuffix := fmt.Sprintf("%s/%s", assistantsSuffix, url.PathEscape(assistantID))
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -198,33 +212,13 @@ func (c *Client) DeleteAssistant(
 // ListAssistants Lists the currently available assistants.
 func (c *Client) ListAssistants(
 	ctx context.Context,
-	limit *int,
-	order *string,
-	after *string,
-	before *string,
+	pagination Pagination,
+	opts ...RequestOption,
 ) (response AssistantsList, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if order != nil {
-		urlValues.Add("order", *order)
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	if before != nil {
-		urlValues.Add("before", *before)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	urlSuffix := fmt.Sprintf("%s%s", assistantsSuffix, encodedValues)
+	urlSuffix := assistantsSuffix + encodeQuery(pagination.values())
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -238,11 +232,13 @@ func (c *Client) CreateAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	request AssistantFileRequest,
+	opts ...RequestOption,
 ) (response AssistantFile, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s", assistantsSuffix, assistantID, assistantsFilesSuffix)
+	urlSuffix := fmt.Sprintf("%s/%s%s", assistantsSuffix, url.PathEscape(assistantID), assistantsFilesSuffix)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -256,10 +252,13 @@ func (c *Client) RetrieveAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	fileID string,
+	opts ...RequestOption,
 ) (response AssistantFile, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix, assistantID, assistantsFilesSuffix, fileID)
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix,
+		url.PathEscape(assistantID), assistantsFilesSuffix, url.PathEscape(fileID))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -273,10 +272,13 @@ func (c *Client) DeleteAssistantFile(
 	ctx context.Context,
 	assistantID string,
 	fileID string,
+	opts ...RequestOption,
 ) (err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix, assistantID, assistantsFilesSuffix, fileID)
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s", assistantsSuffix,
+		url.PathEscape(assistantID), assistantsFilesSuffix, url.PathEscape(fileID))
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -289,33 +291,14 @@ func (c *Client) DeleteAssistantFile(
 func (c *Client) ListAssistantFiles(
 	ctx context.Context,
 	assistantID string,
-	limit *int,
-	order *string,
-	after *string,
-	before *string,
+	pagination Pagination,
+	opts ...RequestOption,
 ) (response AssistantFilesList, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if order != nil {
-		urlValues.Add("order", *order)
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	if before != nil {
-		urlValues.Add("before", *before)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	urlSuffix := fmt.Sprintf("%s/%s%s%s", assistantsSuffix, assistantID, assistantsFilesSuffix, encodedValues)
+	urlSuffix := fmt.Sprintf("%s/%s%s%s", assistantsSuffix,
+		url.PathEscape(assistantID), assistantsFilesSuffix, encodeQuery(pagination.values()))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -184,7 +184,7 @@ When asked a question, write and run Python code to answer the question.`
 	})
 
 	t.Run("list_assistant", func(t *testing.T) {
-		_, err := client.ListAssistants(ctx, &limit, &order, &after, &before)
+		_, err := client.ListAssistants(ctx, openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before})
 		checks.NoError(t, err, "ListAssistants error")
 	})
 
@@ -196,7 +196,8 @@ When asked a question, write and run Python code to answer the question.`
 	})
 
 	t.Run("list_assistant_files", func(t *testing.T) {
-		_, err := client.ListAssistantFiles(ctx, assistantID, &limit, &order, &after, &before)
+		_, err := client.ListAssistantFiles(ctx, assistantID,
+			openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before})
 		checks.NoError(t, err, "ListAssistantFiles error")
 	})
 
@@ -428,7 +429,7 @@ When asked a question, write and run Python code to answer the question.`
 	_, err = client.DeleteAssistant(ctx, assistantID)
 	checks.NoError(t, err, "DeleteAssistant error")
 
-	_, err = client.ListAssistants(ctx, &limit, &order, &after, &before)
+	_, err = client.ListAssistants(ctx, openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before})
 	checks.NoError(t, err, "ListAssistants error")
 
 	_, err = client.CreateAssistantFile(ctx, assistantID, openai.AssistantFileRequest{
@@ -436,7 +437,8 @@ When asked a question, write and run Python code to answer the question.`
 	})
 	checks.NoError(t, err, "CreateAssistantFile error")
 
-	_, err = client.ListAssistantFiles(ctx, assistantID, &limit, &order, &after, &before)
+	_, err = client.ListAssistantFiles(ctx, assistantID,
+		openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before})
 	checks.NoError(t, err, "ListAssistantFiles error")
 
 	_, err = client.RetrieveAssistantFile(ctx, assistantID, assistantFileID)
@@ -1,7 +1,6 @@
 package openai
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -96,16 +95,18 @@ func (r *audioTextResponse) ToAudioResponse() AudioResponse {
 func (c *Client) CreateTranscription(
 	ctx context.Context,
 	request AudioRequest,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	return c.callAudioAPI(ctx, request, "transcriptions")
+	return c.callAudioAPI(ctx, request, "transcriptions", opts...)
 }
 
 // CreateTranslation — API call to translate audio into English.
 func (c *Client) CreateTranslation(
 	ctx context.Context,
 	request AudioRequest,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	return c.callAudioAPI(ctx, request, "translations")
+	return c.callAudioAPI(ctx, request, "translations", opts...)
 }
 
 // callAudioAPI — API call to an audio endpoint.
@@ -113,21 +114,25 @@ func (c *Client) callAudioAPI(
 	ctx context.Context,
 	request AudioRequest,
 	endpointSuffix string,
+	opts ...RequestOption,
 ) (response AudioResponse, err error) {
-	var formBody bytes.Buffer
-	builder := c.createFormBuilder(&formBody)
-
-	if err = audioMultipartForm(request, builder); err != nil {
-		return AudioResponse{}, err
+	// A file at FilePath is opened again for every pass, only a Reader must be rewound.
+	rewind := func() error { return nil }
+	if request.Reader != nil {
+		rewind = rewindReaders(request.Reader)
 	}
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		return audioMultipartForm(request, builder)
+	}, rewind)
+	defer form.close()
 
 	urlSuffix := fmt.Sprintf("/audio/%s", endpointSuffix)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
-		withBody(&formBody),
-		withContentType(builder.FormDataContentType()),
+		withBody(form),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return AudioResponse{}, err
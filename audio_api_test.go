@@ -26,7 +26,7 @@ func TestAudio(t *testing.T) {
 
 	testcases := []struct {
 		name     string
-		createFn func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		createFn func(context.Context, openai.AudioRequest, ...openai.RequestOption) (openai.AudioResponse, error)
 	}{
 		{
 			"transcribe",
@@ -73,7 +73,7 @@ func TestAudioWithOptionalArgs(t *testing.T) {
 
 	testcases := []struct {
 		name     string
-		createFn func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)
+		createFn func(context.Context, openai.AudioRequest, ...openai.RequestOption) (openai.AudioResponse, error)
 	}{
 		{
 			"transcribe",
@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// azureEndpoint describes how an API path is routed by Azure OpenAI.
+type azureEndpoint struct {
+	path string
+	// deploymentScoped endpoints live under /openai/deployments/{deployment},
+	// all others directly under /openai.
+	deploymentScoped bool
+}
+
+// azureEndpoints is the Azure routing table. Paths which aren't listed are
+// resource-scoped.
+var azureEndpoints = []azureEndpoint{
+	// Inference endpoints are served by a model deployment.
+	{path: "/chat/completions", deploymentScoped: true},
+	{path: "/completions", deploymentScoped: true},
+	{path: "/embeddings", deploymentScoped: true},
+	{path: "/audio/transcriptions", deploymentScoped: true},
+	{path: "/audio/translations", deploymentScoped: true},
+	{path: "/audio/speech", deploymentScoped: true},
+	{path: "/images/generations", deploymentScoped: true},
+	{path: "/images/edits", deploymentScoped: true},
+	{path: "/images/variations", deploymentScoped: true},
+	{path: "/moderations", deploymentScoped: true},
+	// Stateful APIs belong to the resource; the model, if any, is part of the body.
+	{path: "/assistants"},
+	{path: "/threads"},
+	{path: "/vector_stores"},
+	{path: "/files"},
+	{path: "/uploads"},
+	{path: "/batches"},
+	{path: "/fine_tuning"},
+	{path: "/models"},
+}
+
+// hasPathPrefix reports whether path is prefix or a sub-path of it.
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// lookupAzureEndpoint returns the routing table entry for the path of suffix.
+func lookupAzureEndpoint(suffix string) (azureEndpoint, bool) {
+	path, _, _ := strings.Cut(suffix, "?")
+	for _, endpoint := range azureEndpoints {
+		if hasPathPrefix(path, endpoint.path) {
+			return endpoint, true
+		}
+	}
+	return azureEndpoint{}, false
+}
+
+// apiVersionForPath returns the api-version to send for path: the
+// AzureAPIVersions entry with the longest matching prefix, or APIVersion.
+func (c ClientConfig) apiVersionForPath(path string) string {
+	version, matched := c.APIVersion, ""
+	for prefix, override := range c.AzureAPIVersions {
+		if hasPathPrefix(path, prefix) && len(prefix) > len(matched) {
+			version, matched = override, prefix
+		}
+	}
+	return version
+}
+
+func (c *Client) baseURLWithAzureDeployment(baseURL, suffix, model string) (newBaseURL string) {
+	baseURL = fmt.Sprintf("%s/%s", strings.TrimRight(baseURL, "/"), azureAPIPrefix)
+	if endpoint, ok := lookupAzureEndpoint(suffix); ok && endpoint.deploymentScoped {
+		azureDeploymentName := c.config.GetAzureDeploymentByModel(model)
+		if azureDeploymentName == "" {
+			azureDeploymentName = "UNKNOWN"
+		}
+		baseURL = fmt.Sprintf("%s/%s/%s", baseURL, azureDeploymentsPrefix, azureDeploymentName)
+	}
+	return baseURL
+}
@@ -0,0 +1,189 @@
+package openai
+
+import "encoding/json"
+
+// Azure OpenAI "On Your Data" lets chat completions ground answers in an
+// external index. See
+// https://learn.microsoft.com/en-us/azure/ai-services/openai/references/on-your-data
+
+type ChatDataSourceType string
+
+const (
+	ChatDataSourceTypeAzureSearch   ChatDataSourceType = "azure_search"
+	ChatDataSourceTypeAzureCosmosDB ChatDataSourceType = "azure_cosmos_db"
+	ChatDataSourceTypeElasticsearch ChatDataSourceType = "elasticsearch"
+)
+
+// ChatDataSource is an entry of ChatCompletionRequest.DataSources. Parameters
+// holds the configuration matching Type, e.g. AzureSearchDataSourceParameters.
+type ChatDataSource struct {
+	Type       ChatDataSourceType `json:"type"`
+	Parameters any                `json:"parameters"`
+}
+
+// NewAzureSearchDataSource returns an Azure AI Search data source.
+func NewAzureSearchDataSource(parameters AzureSearchDataSourceParameters) ChatDataSource {
+	return ChatDataSource{Type: ChatDataSourceTypeAzureSearch, Parameters: parameters}
+}
+
+// NewAzureCosmosDBDataSource returns an Azure Cosmos DB for MongoDB vCore data source.
+func NewAzureCosmosDBDataSource(parameters AzureCosmosDBDataSourceParameters) ChatDataSource {
+	return ChatDataSource{Type: ChatDataSourceTypeAzureCosmosDB, Parameters: parameters}
+}
+
+// NewElasticsearchDataSource returns an Elasticsearch data source.
+func NewElasticsearchDataSource(parameters ElasticsearchDataSourceParameters) ChatDataSource {
+	return ChatDataSource{Type: ChatDataSourceTypeElasticsearch, Parameters: parameters}
+}
+
+type DataSourceAuthenticationType string
+
+const (
+	DataSourceAuthenticationAPIKey                        DataSourceAuthenticationType = "api_key"
+	DataSourceAuthenticationSystemAssignedManagedIdentity DataSourceAuthenticationType = "system_assigned_managed_identity"
+	DataSourceAuthenticationUserAssignedManagedIdentity   DataSourceAuthenticationType = "user_assigned_managed_identity"
+	DataSourceAuthenticationAccessToken                   DataSourceAuthenticationType = "access_token"
+	DataSourceAuthenticationConnectionString              DataSourceAuthenticationType = "connection_string"
+	DataSourceAuthenticationKeyAndKeyID                   DataSourceAuthenticationType = "key_and_key_id"
+	DataSourceAuthenticationEncodedAPIKey                 DataSourceAuthenticationType = "encoded_api_key"
+)
+
+// DataSourceAuthentication describes how Azure OpenAI authenticates against a
+// data source or an embedding endpoint. Only the fields of Type are sent.
+type DataSourceAuthentication struct {
+	Type                      DataSourceAuthenticationType `json:"type"`
+	Key                       string                       `json:"key,omitempty"`
+	KeyID                     string                       `json:"key_id,omitempty"`
+	EncodedAPIKey             string                       `json:"encoded_api_key,omitempty"`
+	AccessToken               string                       `json:"access_token,omitempty"`
+	ConnectionString          string                       `json:"connection_string,omitempty"`
+	ManagedIdentityResourceID string                       `json:"managed_identity_resource_id,omitempty"`
+}
+
+type DataSourceEmbeddingDependencyType string
+
+const (
+	DataSourceEmbeddingDeploymentName DataSourceEmbeddingDependencyType = "deployment_name"
+	DataSourceEmbeddingEndpoint       DataSourceEmbeddingDependencyType = "endpoint"
+	DataSourceEmbeddingModelID        DataSourceEmbeddingDependencyType = "model_id"
+)
+
+// DataSourceEmbeddingDependency selects the embedding model used for vector search.
+type DataSourceEmbeddingDependency struct {
+	Type           DataSourceEmbeddingDependencyType `json:"type"`
+	DeploymentName string                            `json:"deployment_name,omitempty"`
+	Endpoint       string                            `json:"endpoint,omitempty"`
+	ModelID        string                            `json:"model_id,omitempty"`
+	Dimensions     int                               `json:"dimensions,omitempty"`
+	Authentication *DataSourceAuthentication         `json:"authentication,omitempty"`
+}
+
+// DataSourceFieldsMapping maps index fields to the parts of a citation.
+type DataSourceFieldsMapping struct {
+	TitleField             string   `json:"title_field,omitempty"`
+	URLField               string   `json:"url_field,omitempty"`
+	FilepathField          string   `json:"filepath_field,omitempty"`
+	ContentFields          []string `json:"content_fields,omitempty"`
+	ContentFieldsSeparator string   `json:"content_fields_separator,omitempty"`
+	VectorFields           []string `json:"vector_fields,omitempty"`
+	ImageVectorFields      []string `json:"image_vector_fields,omitempty"`
+}
+
+type DataSourceQueryType string
+
+const (
+	DataSourceQueryTypeSimple               DataSourceQueryType = "simple"
+	DataSourceQueryTypeSemantic             DataSourceQueryType = "semantic"
+	DataSourceQueryTypeVector               DataSourceQueryType = "vector"
+	DataSourceQueryTypeVectorSimpleHybrid   DataSourceQueryType = "vector_simple_hybrid"
+	DataSourceQueryTypeVectorSemanticHybrid DataSourceQueryType = "vector_semantic_hybrid"
+)
+
+// DataSourceOptions are the retrieval options shared by every data source type.
+type DataSourceOptions struct {
+	// InScope limits responses to the grounding data. Defaults to true.
+	InScope *bool `json:"in_scope,omitempty"`
+	// Strictness from 1 to 5; higher values filter out more documents.
+	Strictness         int  `json:"strictness,omitempty"`
+	TopNDocuments      int  `json:"top_n_documents,omitempty"`
+	MaxSearchQueries   int  `json:"max_search_queries,omitempty"`
+	AllowPartialResult bool `json:"allow_partial_result,omitempty"`
+	// IncludeContexts selects the context properties returned on the message:
+	// "citations", "intent" and "all_retrieved_documents".
+	IncludeContexts []string `json:"include_contexts,omitempty"`
+}
+
+type AzureSearchDataSourceParameters struct {
+	DataSourceOptions
+
+	Endpoint              string                         `json:"endpoint"`
+	IndexName             string                         `json:"index_name"`
+	Authentication        *DataSourceAuthentication      `json:"authentication,omitempty"`
+	FieldsMapping         *DataSourceFieldsMapping       `json:"fields_mapping,omitempty"`
+	QueryType             DataSourceQueryType            `json:"query_type,omitempty"`
+	SemanticConfiguration string                         `json:"semantic_configuration,omitempty"`
+	Filter                string                         `json:"filter,omitempty"`
+	EmbeddingDependency   *DataSourceEmbeddingDependency `json:"embedding_dependency,omitempty"`
+}
+
+type AzureCosmosDBDataSourceParameters struct {
+	DataSourceOptions
+
+	DatabaseName        string                         `json:"database_name"`
+	ContainerName       string                         `json:"container_name"`
+	IndexName           string                         `json:"index_name"`
+	Authentication      *DataSourceAuthentication      `json:"authentication,omitempty"`
+	FieldsMapping       *DataSourceFieldsMapping       `json:"fields_mapping,omitempty"`
+	EmbeddingDependency *DataSourceEmbeddingDependency `json:"embedding_dependency,omitempty"`
+}
+
+type ElasticsearchDataSourceParameters struct {
+	DataSourceOptions
+
+	Endpoint            string                         `json:"endpoint"`
+	IndexName           string                         `json:"index_name"`
+	Authentication      *DataSourceAuthentication      `json:"authentication,omitempty"`
+	FieldsMapping       *DataSourceFieldsMapping       `json:"fields_mapping,omitempty"`
+	QueryType           DataSourceQueryType            `json:"query_type,omitempty"`
+	EmbeddingDependency *DataSourceEmbeddingDependency `json:"embedding_dependency,omitempty"`
+}
+
+// ChatMessageContext is returned on assistant messages, and on the first
+// streamed delta, when the request used DataSources.
+type ChatMessageContext struct {
+	Citations []ChatCitation `json:"citations,omitempty"`
+	// Intent is the JSON-encoded list of search queries the model generated.
+	Intent                string                  `json:"intent,omitempty"`
+	AllRetrievedDocuments []ChatRetrievedDocument `json:"all_retrieved_documents,omitempty"`
+}
+
+// Intents decodes Intent into the list of search queries.
+func (c *ChatMessageContext) Intents() ([]string, error) {
+	if c == nil || c.Intent == "" {
+		return nil, nil
+	}
+	var intents []string
+	if err := json.Unmarshal([]byte(c.Intent), &intents); err != nil {
+		return nil, err
+	}
+	return intents, nil
+}
+
+type ChatCitation struct {
+	Content     string  `json:"content"`
+	Title       string  `json:"title,omitempty"`
+	URL         string  `json:"url,omitempty"`
+	Filepath    string  `json:"filepath,omitempty"`
+	ChunkID     string  `json:"chunk_id,omitempty"`
+	RerankScore float64 `json:"rerank_score,omitempty"`
+}
+
+type ChatRetrievedDocument struct {
+	ChatCitation
+
+	SearchQueries       []string `json:"search_queries,omitempty"`
+	DataSourceIndex     int      `json:"data_source_index"`
+	OriginalSearchScore float64  `json:"original_search_score,omitempty"`
+	// FilterReason is "score" or "rerank" when the document was filtered out.
+	FilterReason string `json:"filter_reason,omitempty"`
+}
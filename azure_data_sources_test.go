@@ -0,0 +1,214 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const azureOnYourDataPath = "/openai/deployments/gpt-4o/chat/completions"
+
+func TestAzureChatCompletionWithDataSources(t *testing.T) {
+	client, server, teardown := setupAzureTestServer()
+	defer teardown()
+
+	var gotDataSources []map[string]any
+	server.RegisterHandler(azureOnYourDataPath, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			DataSources []map[string]any `json:"data_sources"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		gotDataSources = body.DataSources
+		fmt.Fprint(w, `{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"model": "gpt-4o",
+			"choices": [{
+				"index": 0,
+				"finish_reason": "stop",
+				"message": {
+					"role": "assistant",
+					"content": "The warranty lasts two years [doc1].",
+					"context": {
+						"citations": [{
+							"content": "All devices have a two year warranty.",
+							"title": "Warranty",
+							"url": "https://contoso.com/warranty",
+							"filepath": "warranty.md",
+							"chunk_id": "0"
+						}],
+						"intent": "[\"warranty length\", \"device warranty\"]"
+					}
+				},
+				"content_filter_results": {
+					"protected_material_text": {"filtered": false, "detected": false},
+					"protected_material_code": {
+						"filtered": false,
+						"detected": true,
+						"citation": {"license": "MIT", "URL": "https://github.com/contoso/repo"}
+					},
+					"custom_blocklists": {"filtered": true, "details": [{"filtered": true, "id": "brands"}]}
+				}
+			}],
+			"prompt_filter_results": [{
+				"prompt_index": 0,
+				"content_filter_results": {
+					"jailbreak": {"filtered": true, "detected": true},
+					"indirect_attack": {"filtered": false, "detected": true},
+					"custom_blocklists": [{"filtered": false, "id": "brands"}, {"filtered": true, "id": "competitors"}]
+				}
+			}]
+		}`)
+	})
+
+	inScope := true
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "How long is the warranty?"}},
+		DataSources: []openai.ChatDataSource{openai.NewAzureSearchDataSource(openai.AzureSearchDataSourceParameters{
+			DataSourceOptions: openai.DataSourceOptions{InScope: &inScope, TopNDocuments: 5},
+			Endpoint:          "https://contoso.search.windows.net",
+			IndexName:         "docs",
+			Authentication: &openai.DataSourceAuthentication{
+				Type: openai.DataSourceAuthenticationAPIKey,
+				Key:  "search-key",
+			},
+			QueryType: openai.DataSourceQueryTypeVectorSemanticHybrid,
+			EmbeddingDependency: &openai.DataSourceEmbeddingDependency{
+				Type:           openai.DataSourceEmbeddingDeploymentName,
+				DeploymentName: "text-embedding-3-small",
+			},
+		})},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	wantDataSources := []map[string]any{{
+		"type": "azure_search",
+		"parameters": map[string]any{
+			"in_scope":        true,
+			"top_n_documents": float64(5),
+			"endpoint":        "https://contoso.search.windows.net",
+			"index_name":      "docs",
+			"authentication":  map[string]any{"type": "api_key", "key": "search-key"},
+			"query_type":      "vector_semantic_hybrid",
+			"embedding_dependency": map[string]any{
+				"type":            "deployment_name",
+				"deployment_name": "text-embedding-3-small",
+			},
+		},
+	}}
+	if !reflect.DeepEqual(gotDataSources, wantDataSources) {
+		t.Fatalf("unexpected data_sources %v", gotDataSources)
+	}
+
+	msgContext := resp.Choices[0].Message.Context
+	if msgContext == nil || len(msgContext.Citations) != 1 {
+		t.Fatalf("expected one citation, got %+v", msgContext)
+	}
+	if citation := msgContext.Citations[0]; citation.Title != "Warranty" || citation.Filepath != "warranty.md" {
+		t.Fatalf("unexpected citation %+v", citation)
+	}
+	intents, err := msgContext.Intents()
+	checks.NoError(t, err, "Intents error")
+	if !reflect.DeepEqual(intents, []string{"warranty length", "device warranty"}) {
+		t.Fatalf("unexpected intents %v", intents)
+	}
+
+	// The context of a response is not sent back with the conversation.
+	history, err := json.Marshal(resp.Choices[0].Message)
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(history), `"context"`) {
+		t.Fatalf("expected the message context to be left out of requests, got %s", history)
+	}
+
+	filter := resp.Choices[0].ContentFilterResults
+	if !filter.ProtectedMaterialCode.Detected || filter.ProtectedMaterialCode.Citation == nil ||
+		filter.ProtectedMaterialCode.Citation.License != "MIT" {
+		t.Fatalf("unexpected protected material results %+v", filter.ProtectedMaterialCode)
+	}
+	if !filter.CustomBlocklists.Filtered || filter.CustomBlocklists.Details[0].ID != "brands" {
+		t.Fatalf("unexpected custom blocklist results %+v", filter.CustomBlocklists)
+	}
+
+	promptFilter := resp.PromptFilterResults[0].ContentFilterResults
+	if !promptFilter.JailBreak.Detected || !promptFilter.IndirectAttack.Detected {
+		t.Fatalf("unexpected prompt filter results %+v", promptFilter)
+	}
+	if !promptFilter.CustomBlocklists.Filtered || len(promptFilter.CustomBlocklists.Details) != 2 {
+		t.Fatalf("custom blocklists list form not parsed: %+v", promptFilter.CustomBlocklists)
+	}
+}
+
+func TestAzureChatCompletionStreamWithDataSources(t *testing.T) {
+	client, server, teardown := setupAzureTestServer()
+	defer teardown()
+
+	server.RegisterHandler(azureOnYourDataPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunks := []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","context":{"citations":[` +
+				`{"content":"Two year warranty.","title":"Warranty","url":"https://contoso.com/warranty"}],` +
+				`"intent":"[\"warranty\"]"}}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":"Two years [doc1]."}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"stop","content_filter_results":` +
+				`{"protected_material_text":{"filtered":false,"detected":true}}}]}`,
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Warranty?"}},
+		DataSources: []openai.ChatDataSource{openai.NewElasticsearchDataSource(openai.ElasticsearchDataSourceParameters{
+			Endpoint:  "https://contoso.es.example.com",
+			IndexName: "docs",
+			Authentication: &openai.DataSourceAuthentication{
+				Type:          openai.DataSourceAuthenticationEncodedAPIKey,
+				EncodedAPIKey: "encoded",
+			},
+		})},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	var (
+		msgContext      *openai.ChatMessageContext
+		content         string
+		protectedDetail bool
+	)
+	for {
+		chunk, streamErr := stream.Recv()
+		if errors.Is(streamErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, streamErr, "stream.Recv error")
+		delta := chunk.Choices[0].Delta
+		if delta.Context != nil {
+			msgContext = delta.Context
+		}
+		content += delta.Content
+		protectedDetail = protectedDetail || chunk.Choices[0].ContentFilterResults.ProtectedMaterialText.Detected
+	}
+
+	if msgContext == nil || len(msgContext.Citations) != 1 ||
+		msgContext.Citations[0].URL != "https://contoso.com/warranty" {
+		t.Fatalf("expected the citation from the first delta, got %+v", msgContext)
+	}
+	if content != "Two years [doc1]." || !protectedDetail {
+		t.Fatalf("unexpected stream content %q, protected material detected %v", content, protectedDetail)
+	}
+}
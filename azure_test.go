@@ -0,0 +1,520 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const (
+	azureTestAPIVersion     = "2024-10-21"
+	azureTestPreviewVersion = "2024-05-01-preview"
+	azureTestDeployment     = "test-deployment"
+)
+
+type azureURLTestCase struct {
+	name string
+	// path is the API path the call must reach, relative to the Azure /openai prefix.
+	path             string
+	deploymentScoped bool
+	call             func(ctx context.Context, c *openai.Client) error
+}
+
+func discard[T any](_ T, err error) error {
+	return err
+}
+
+func azureURLTestCases(t *testing.T) []azureURLTestCase {
+	t.Helper()
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "image.png")
+	checks.NoError(t, os.WriteFile(imagePath, []byte("png"), 0o600), "WriteFile error")
+	openImage := func() *os.File {
+		f, err := os.Open(imagePath)
+		checks.NoError(t, err, "Open error")
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+	audio := openai.AudioRequest{Model: openai.Whisper1, FilePath: "audio.mp3", Reader: strings.NewReader("mp3")}
+	model := openai.GPT4o
+	completion := openai.CompletionRequest{Model: openai.GPT3Dot5TurboInstruct, Prompt: "hi"}
+	batchLine := openai.BatchChatCompletionRequest{
+		CustomID: "1",
+		Body:     openai.ChatCompletionRequest{Model: model},
+		Method:   http.MethodPost,
+		URL:      openai.BatchEndpointChatCompletions,
+	}
+	pagination := openai.Pagination{}
+
+	return []azureURLTestCase{
+		{"CreateChatCompletion", "/chat/completions", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateChatCompletion(ctx, openai.ChatCompletionRequest{Model: model}))
+		}},
+		{"CreateChatCompletionStream", "/chat/completions", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{Model: model}))
+		}},
+		{"CreateCompletion", "/completions", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateCompletion(ctx, completion))
+		}},
+		{"CreateCompletionStream", "/completions", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateCompletionStream(ctx, completion))
+		}},
+		{"CreateEmbeddings", "/embeddings", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateEmbeddings(ctx, openai.EmbeddingRequest{Model: openai.AdaEmbeddingV2}))
+		}},
+		{"CreateEmbeddingsBatched", "/embeddings", true, func(ctx context.Context, c *openai.Client) error {
+			// The recorder answers {}, a response without embeddings.
+			_, err := c.CreateEmbeddingsBatched(ctx, openai.EmbeddingRequestStrings{
+				Input: []string{"a"}, Model: openai.AdaEmbeddingV2,
+			}, openai.EmbeddingBatchOptions{})
+			if errors.Is(err, openai.ErrEmbeddingBatchIncomplete) {
+				return nil
+			}
+			return err
+		}},
+		{"NewCachedEmbedder", "/embeddings", true, func(ctx context.Context, c *openai.Client) error {
+			embedder := c.NewCachedEmbedder(openai.NewLRUEmbeddingCache(1))
+			_, err := embedder.CreateEmbeddings(ctx, openai.EmbeddingRequest{Input: "a", Model: openai.AdaEmbeddingV2})
+			if errors.Is(err, openai.ErrEmbeddingBatchIncomplete) {
+				return nil
+			}
+			return err
+		}},
+		{"CreateTranscription", "/audio/transcriptions", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateTranscription(ctx, audio))
+		}},
+		{"CreateTranslation", "/audio/translations", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateTranslation(ctx, audio))
+		}},
+		{"CreateSpeech", "/audio/speech", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateSpeech(ctx, openai.CreateSpeechRequest{
+				Model: openai.TTSModel1, Input: "hi", Voice: openai.VoiceAlloy,
+			}))
+		}},
+		{"CreateImage", "/images/generations", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateImage(ctx, openai.ImageRequest{Model: openai.CreateImageModelDallE3}))
+		}},
+		{"CreateEditImage", "/images/edits", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateEditImage(ctx, openai.ImageEditRequest{
+				Model: openai.CreateImageModelGptImage1, Image: openImage(),
+			}))
+		}},
+		{"CreateVariImage", "/images/variations", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateVariImage(ctx, openai.ImageVariRequest{
+				Model: openai.CreateImageModelDallE2, Image: openImage(),
+			}))
+		}},
+		{"Moderations", "/moderations", true, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.Moderations(ctx, openai.ModerationRequest{Model: openai.ModerationOmniLatest}))
+		}},
+		{"Edits", "/edits", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.Edits(ctx, openai.EditsRequest{Model: &model}))
+		}},
+		{"ListEngines", "/engines", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListEngines(ctx))
+		}},
+		{"GetEngine", "/engines/e1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.GetEngine(ctx, "e1"))
+		}},
+		{"ListModels", "/models", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListModels(ctx))
+		}},
+		{"GetModel", "/models/m1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.GetModel(ctx, "m1"))
+		}},
+		{"DeleteFineTuneModel", "/models/m1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteFineTuneModel(ctx, "m1"))
+		}},
+		{"CreateFile", "/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateFile(ctx, openai.FileRequest{FilePath: imagePath, Purpose: "assistants"}))
+		}},
+		{"CreateFileBytes", "/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateFileBytes(ctx, openai.FileBytesRequest{Name: "a.jsonl", Bytes: []byte("{}")}))
+		}},
+		{"CreateUpload", "/uploads", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateUpload(ctx, openai.UploadRequest{Filename: "a.jsonl", Purpose: openai.PurposeBatch}))
+		}},
+		{"AddUploadPart", "/uploads/u1/parts", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.AddUploadPart(ctx, "u1", strings.NewReader("{}")))
+		}},
+		{"CompleteUpload", "/uploads/u1/complete", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CompleteUpload(ctx, "u1", openai.CompleteUploadRequest{PartIDs: []string{"p1"}}))
+		}},
+		{"CancelUpload", "/uploads/u1/cancel", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CancelUpload(ctx, "u1"))
+		}},
+		{"UploadLargeFile", "/uploads", false, func(ctx context.Context, c *openai.Client) error {
+			// The recorder answers {}, an upload without an ID, so UploadLargeFile stops after CreateUpload.
+			_, err := c.UploadLargeFile(ctx, strings.NewReader("{}"), 2, openai.PurposeBatch,
+				openai.UploadLargeFileOptions{Filename: "a.jsonl"})
+			if errors.Is(err, openai.ErrUploadMissingID) {
+				return nil
+			}
+			return err
+		}},
+		{"ListFiles", "/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListFiles(ctx))
+		}},
+		{"GetFile", "/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.GetFile(ctx, "f1"))
+		}},
+		{"GetFileContent", "/files/f1/content", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.GetFileContent(ctx, "f1"))
+		}},
+		{"DeleteFile", "/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return c.DeleteFile(ctx, "f1")
+		}},
+		{"CreateFineTune", "/fine-tunes", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateFineTune(ctx, openai.FineTuneRequest{}))
+		}},
+		{"ListFineTunes", "/fine-tunes", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListFineTunes(ctx))
+		}},
+		{"GetFineTune", "/fine-tunes/ft1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.GetFineTune(ctx, "ft1"))
+		}},
+		{"CancelFineTune", "/fine-tunes/ft1/cancel", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CancelFineTune(ctx, "ft1"))
+		}},
+		{"DeleteFineTune", "/fine-tunes/ft1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteFineTune(ctx, "ft1"))
+		}},
+		{"ListFineTuneEvents", "/fine-tunes/ft1/events", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListFineTuneEvents(ctx, "ft1"))
+		}},
+		{"CreateFineTuningJob", "/fine_tuning/jobs", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateFineTuningJob(ctx, openai.FineTuningJobRequest{Model: model}))
+		}},
+		{"RetrieveFineTuningJob", "/fine_tuning/jobs/j1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveFineTuningJob(ctx, "j1"))
+		}},
+		{"CancelFineTuningJob", "/fine_tuning/jobs/j1/cancel", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CancelFineTuningJob(ctx, "j1"))
+		}},
+		{"ListFineTuningJobEvents", "/fine_tuning/jobs/j1/events", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListFineTuningJobEvents(ctx, "j1", openai.Pagination{}))
+		}},
+		{"ListFineTuningJobEventsPager", "/fine_tuning/jobs/j1/events", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.ListFineTuningJobEventsPager(ctx, "j1", pagination, openai.PagerOptions{}).Collect())
+			}},
+		{"CreateBatch", "/batches", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateBatch(ctx, openai.CreateBatchRequest{InputFileID: "f1"}))
+		}},
+		{"UploadBatchFile", "/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.UploadBatchFile(ctx, openai.UploadBatchFileRequest{Lines: []openai.BatchLineItem{batchLine}}))
+		}},
+		{"CreateBatchWithUploadFile", "/batches", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateBatchWithUploadFile(ctx, openai.CreateBatchWithUploadFileRequest{
+				UploadBatchFileRequest: openai.UploadBatchFileRequest{Lines: []openai.BatchLineItem{batchLine}},
+			}))
+		}},
+		{"ReadBatchResults", "/files/f1/content", false, func(ctx context.Context, c *openai.Client) error {
+			outputFileID := "f1"
+			return discard(c.ReadBatchResults(ctx, openai.Batch{OutputFileID: &outputFileID}).CollectByCustomID())
+		}},
+		{"NewBatchRunner", "/batches", false, func(ctx context.Context, c *openai.Client) error {
+			// The recorder answers {}, a batch without a status, so Run stops after CreateBatch.
+			_, err := c.NewBatchRunner(openai.BatchRunnerOptions{}).Run(ctx, []openai.BatchLineItem{batchLine})
+			if errors.Is(err, openai.ErrBatchUnexpectedStatus) {
+				return nil
+			}
+			return err
+		}},
+		{"RunLocalBatch", "/chat/completions", true, func(ctx context.Context, c *openai.Client) error {
+			request := openai.UploadBatchFileRequest{Lines: []openai.BatchLineItem{batchLine}}
+			return discard(c.RunLocalBatch(ctx, request, openai.LocalBatchOptions{}))
+		}},
+		{"RunLocalBatchFile", "/chat/completions", true, func(ctx context.Context, c *openai.Client) error {
+			jsonl := strings.NewReader(string(batchLine.MarshalBatchLineItem()))
+			return discard(c.RunLocalBatchFile(ctx, jsonl, openai.LocalBatchOptions{}))
+		}},
+		{"RetrieveBatch", "/batches/b1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveBatch(ctx, "b1"))
+		}},
+		{"CancelBatch", "/batches/b1/cancel", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CancelBatch(ctx, "b1"))
+		}},
+		{"ListBatch", "/batches", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListBatch(ctx, openai.Pagination{}))
+		}},
+		{"ListBatchPager", "/batches", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListBatchPager(ctx, pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateAssistant", "/assistants", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateAssistant(ctx, openai.AssistantRequest{Model: model}))
+		}},
+		{"RetrieveAssistant", "/assistants/a1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveAssistant(ctx, "a1"))
+		}},
+		{"ModifyAssistant", "/assistants/a1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ModifyAssistant(ctx, "a1", openai.AssistantRequest{Model: model}))
+		}},
+		{"DeleteAssistant", "/assistants/a1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteAssistant(ctx, "a1"))
+		}},
+		{"ListAssistants", "/assistants", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListAssistants(ctx, openai.Pagination{}))
+		}},
+		{"ListAssistantsPager", "/assistants", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListAssistantsPager(ctx, pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateAssistantFile", "/assistants/a1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateAssistantFile(ctx, "a1", openai.AssistantFileRequest{FileID: "f1"}))
+		}},
+		{"RetrieveAssistantFile", "/assistants/a1/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveAssistantFile(ctx, "a1", "f1"))
+		}},
+		{"DeleteAssistantFile", "/assistants/a1/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return c.DeleteAssistantFile(ctx, "a1", "f1")
+		}},
+		{"ListAssistantFiles", "/assistants/a1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListAssistantFiles(ctx, "a1", openai.Pagination{}))
+		}},
+		{"ListAssistantFilesPager", "/assistants/a1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListAssistantFilesPager(ctx, "a1", pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateThread", "/threads", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateThread(ctx, openai.ThreadRequest{}))
+		}},
+		{"RetrieveThread", "/threads/t1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveThread(ctx, "t1"))
+		}},
+		{"ModifyThread", "/threads/t1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ModifyThread(ctx, "t1", openai.ModifyThreadRequest{}))
+		}},
+		{"DeleteThread", "/threads/t1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteThread(ctx, "t1"))
+		}},
+		{"CreateMessage", "/threads/t1/messages", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateMessage(ctx, "t1", openai.MessageRequest{Role: "user"}))
+		}},
+		{"ListMessage", "/threads/t1/messages", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListMessage(ctx, "t1", openai.ListMessageOptions{}))
+		}},
+		{"ListMessagePager", "/threads/t1/messages", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListMessagePager(ctx, "t1", openai.ListMessageOptions{}, openai.PagerOptions{}).Collect())
+		}},
+		{"RetrieveMessage", "/threads/t1/messages/m1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveMessage(ctx, "t1", "m1"))
+		}},
+		{"ModifyMessage", "/threads/t1/messages/m1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ModifyMessage(ctx, "t1", "m1", nil))
+		}},
+		{"DeleteMessage", "/threads/t1/messages/m1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteMessage(ctx, "t1", "m1"))
+		}},
+		{"RetrieveMessageFile", "/threads/t1/messages/m1/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveMessageFile(ctx, "t1", "m1", "f1"))
+		}},
+		{"ListMessageFiles", "/threads/t1/messages/m1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListMessageFiles(ctx, "t1", "m1"))
+		}},
+		{"CreateRun", "/threads/t1/runs", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateRun(ctx, "t1", openai.RunRequest{AssistantID: "a1"}))
+		}},
+		{"RetrieveRun", "/threads/t1/runs/r1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveRun(ctx, "t1", "r1"))
+		}},
+		{"ModifyRun", "/threads/t1/runs/r1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ModifyRun(ctx, "t1", "r1", openai.RunModifyRequest{}))
+		}},
+		{"ListRuns", "/threads/t1/runs", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListRuns(ctx, "t1", pagination))
+		}},
+		{"ListRunsPager", "/threads/t1/runs", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListRunsPager(ctx, "t1", pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"SubmitToolOutputs", "/threads/t1/runs/r1/submit_tool_outputs", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.SubmitToolOutputs(ctx, "t1", "r1", openai.SubmitToolOutputsRequest{}))
+			}},
+		{"CancelRun", "/threads/t1/runs/r1/cancel", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CancelRun(ctx, "t1", "r1"))
+		}},
+		{"RunAndWait", "/threads/t1/runs", false, func(ctx context.Context, c *openai.Client) error {
+			// The recorder answers {}, a run without a status, so RunAndWait stops after CreateRun.
+			_, err := c.RunAndWait(ctx, "t1", openai.RunRequest{AssistantID: "a1"}, nil, openai.RunPollOptions{})
+			if errors.Is(err, openai.ErrRunUnexpectedStatus) {
+				return nil
+			}
+			return err
+		}},
+		{"CreateThreadAndRun", "/threads/runs", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateThreadAndRun(ctx, openai.CreateThreadAndRunRequest{}))
+		}},
+		{"RetrieveRunStep", "/threads/t1/runs/r1/steps/s1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveRunStep(ctx, "t1", "r1", "s1"))
+		}},
+		{"ListRunSteps", "/threads/t1/runs/r1/steps", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListRunSteps(ctx, "t1", "r1", pagination))
+		}},
+		{"ListRunStepsPager", "/threads/t1/runs/r1/steps", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListRunStepsPager(ctx, "t1", "r1", pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateVectorStore", "/vector_stores", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateVectorStore(ctx, openai.VectorStoreRequest{}))
+		}},
+		{"RetrieveVectorStore", "/vector_stores/vs1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveVectorStore(ctx, "vs1"))
+		}},
+		{"ModifyVectorStore", "/vector_stores/vs1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ModifyVectorStore(ctx, "vs1", openai.VectorStoreRequest{}))
+		}},
+		{"DeleteVectorStore", "/vector_stores/vs1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.DeleteVectorStore(ctx, "vs1"))
+		}},
+		{"ListVectorStores", "/vector_stores", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListVectorStores(ctx, pagination))
+		}},
+		{"ListVectorStoresPager", "/vector_stores", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListVectorStoresPager(ctx, pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateVectorStoreFile", "/vector_stores/vs1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.CreateVectorStoreFile(ctx, "vs1", openai.VectorStoreFileRequest{FileID: "f1"}))
+		}},
+		{"RetrieveVectorStoreFile", "/vector_stores/vs1/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.RetrieveVectorStoreFile(ctx, "vs1", "f1"))
+		}},
+		{"DeleteVectorStoreFile", "/vector_stores/vs1/files/f1", false, func(ctx context.Context, c *openai.Client) error {
+			return c.DeleteVectorStoreFile(ctx, "vs1", "f1")
+		}},
+		{"ListVectorStoreFiles", "/vector_stores/vs1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListVectorStoreFiles(ctx, "vs1", pagination))
+		}},
+		{"ListVectorStoreFilesPager", "/vector_stores/vs1/files", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.ListVectorStoreFilesPager(ctx, "vs1", pagination, openai.PagerOptions{}).Collect())
+		}},
+		{"CreateVectorStoreFileBatch", "/vector_stores/vs1/file_batches", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.CreateVectorStoreFileBatch(ctx, "vs1", openai.VectorStoreFileBatchRequest{}))
+			}},
+		{"RetrieveVectorStoreFileBatch", "/vector_stores/vs1/file_batches/b1", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.RetrieveVectorStoreFileBatch(ctx, "vs1", "b1"))
+			}},
+		{"CancelVectorStoreFileBatch", "/vector_stores/vs1/file_batches/b1/cancel", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.CancelVectorStoreFileBatch(ctx, "vs1", "b1"))
+			}},
+		{"ListVectorStoreFilesInBatch", "/vector_stores/vs1/file_batches/b1/files", false,
+			func(ctx context.Context, c *openai.Client) error {
+				return discard(c.ListVectorStoreFilesInBatch(ctx, "vs1", "b1", pagination))
+			}},
+		{"SearchVectorStore", "/vector_stores/vs1/search", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.SearchVectorStore(ctx, "vs1", openai.VectorStoreSearchRequest{Query: "q"}))
+		}},
+		{"UploadFilesAndPoll", "/vector_stores/vs1/file_batches", false, func(ctx context.Context, c *openai.Client) error {
+			return discard(c.UploadFilesAndPoll(ctx, "vs1", []string{imagePath}, nil))
+		}},
+	}
+}
+
+type recordedRequest struct {
+	path       string
+	apiVersion string
+}
+
+// newAzureURLRecorder returns a server answering every request with an empty
+// JSON object and a function returning the last request it received.
+func newAzureURLRecorder(t *testing.T) (*httptest.Server, func() recordedRequest) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		last recordedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = recordedRequest{path: r.URL.Path, apiVersion: r.URL.Query().Get("api-version")}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestAzureURLTestCasesCoverClient(t *testing.T) {
+	covered := map[string]bool{}
+	for _, tc := range azureURLTestCases(t) {
+		covered[tc.name] = true
+	}
+	clientType := reflect.TypeOf(&openai.Client{})
+	for i := 0; i < clientType.NumMethod(); i++ {
+		if name := clientType.Method(i).Name; !covered[name] {
+			t.Errorf("Client.%s has no Azure URL test case", name)
+		}
+	}
+}
+
+func TestAzureURLs(t *testing.T) {
+	server, lastRequest := newAzureURLRecorder(t)
+	const cloudflarePrefix = "/v1/account/gateway/azure-openai/resource/" + azureTestDeployment
+
+	configs := []struct {
+		apiType openai.APIType
+		baseURL string
+		// prefix is the path every request is sent under.
+		prefix string
+		// deploymentPrefix is added for deployment-scoped endpoints.
+		deploymentPrefix string
+	}{
+		{openai.APITypeAzure, server.URL, "/openai", "/deployments/" + azureTestDeployment},
+		{openai.APITypeAzureAD, server.URL, "/openai", "/deployments/" + azureTestDeployment},
+		{openai.APITypeCloudflareAzure, server.URL + cloudflarePrefix, cloudflarePrefix, ""},
+	}
+
+	for _, cfg := range configs {
+		config := openai.DefaultAzureConfig("token", cfg.baseURL)
+		config.APIType = cfg.apiType
+		config.APIVersion = azureTestAPIVersion
+		config.AzureAPIVersions = map[string]string{
+			"/assistants": azureTestPreviewVersion,
+			"/threads":    azureTestPreviewVersion,
+		}
+		config.AzureModelMapperFunc = func(model string) string {
+			if model == "" {
+				return ""
+			}
+			return azureTestDeployment
+		}
+		client := openai.NewClientWithConfig(config)
+
+		for _, tc := range azureURLTestCases(t) {
+			t.Run(string(cfg.apiType)+"/"+tc.name, func(t *testing.T) {
+				checks.NoError(t, tc.call(context.Background(), client), "call error")
+
+				wantPath := cfg.prefix + tc.path
+				if tc.deploymentScoped {
+					wantPath = cfg.prefix + cfg.deploymentPrefix + tc.path
+				}
+				wantVersion := azureTestAPIVersion
+				if strings.HasPrefix(tc.path, "/assistants") || strings.HasPrefix(tc.path, "/threads") {
+					wantVersion = azureTestPreviewVersion
+				}
+
+				got := lastRequest()
+				if got.path != wantPath {
+					t.Errorf("path = %q, want %q", got.path, wantPath)
+				}
+				if got.apiVersion != wantVersion {
+					t.Errorf("api-version = %q, want %q", got.apiVersion, wantVersion)
+				}
+			})
+		}
+	}
+}
@@ -112,12 +112,13 @@ type BatchResponse struct {
 func (c *Client) CreateBatch(
 	ctx context.Context,
 	request CreateBatchRequest,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	if request.CompletionWindow == "" {
 		request.CompletionWindow = "24h"
 	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(batchesSuffix), withBody(request))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(batchesSuffix), withBody(request), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -170,7 +171,11 @@ func (r *UploadBatchFileRequest) AddEmbedding(customerID string, body EmbeddingR
 }
 
 // UploadBatchFile — upload batch file.
-func (c *Client) UploadBatchFile(ctx context.Context, request UploadBatchFileRequest) (File, error) {
+func (c *Client) UploadBatchFile(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	opts ...RequestOption,
+) (File, error) {
 	if request.FileName == "" {
 		request.FileName = "@batchinput.jsonl"
 	}
@@ -178,7 +183,7 @@ func (c *Client) UploadBatchFile(ctx context.Context, request UploadBatchFileReq
 		Name:    request.FileName,
 		Bytes:   request.MarshalJSONL(),
 		Purpose: PurposeBatch,
-	})
+	}, opts...)
 }
 
 type CreateBatchWithUploadFileRequest struct {
@@ -192,12 +197,13 @@ type CreateBatchWithUploadFileRequest struct {
 func (c *Client) CreateBatchWithUploadFile(
 	ctx context.Context,
 	request CreateBatchWithUploadFileRequest,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
 	var file File
 	file, err = c.UploadBatchFile(ctx, UploadBatchFileRequest{
 		FileName: request.FileName,
 		Lines:    request.Lines,
-	})
+	}, opts...)
 	if err != nil {
 		return
 	}
@@ -206,16 +212,17 @@ func (c *Client) CreateBatchWithUploadFile(
 		Endpoint:         request.Endpoint,
 		CompletionWindow: request.CompletionWindow,
 		Metadata:         request.Metadata,
-	})
+	}, opts...)
 }
 
 // RetrieveBatch — API call to Retrieve batch.
 func (c *Client) RetrieveBatch(
 	ctx context.Context,
 	batchID string,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", batchesSuffix, batchID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	urlSuffix := fmt.Sprintf("%s/%s", batchesSuffix, url.PathEscape(batchID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -227,9 +234,10 @@ func (c *Client) RetrieveBatch(
 func (c *Client) CancelBatch(
 	ctx context.Context,
 	batchID string,
+	opts ...RequestOption,
 ) (response BatchResponse, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s/cancel", batchesSuffix, batchID)
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix))
+	urlSuffix := fmt.Sprintf("%s/%s/cancel", batchesSuffix, url.PathEscape(batchID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -247,21 +255,13 @@ type ListBatchResponse struct {
 }
 
 // ListBatch API call to List batch.
-func (c *Client) ListBatch(ctx context.Context, after *string, limit *int) (response ListBatchResponse, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	urlSuffix := fmt.Sprintf("%s%s", batchesSuffix, encodedValues)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+func (c *Client) ListBatch(
+	ctx context.Context,
+	pagination Pagination,
+	opts ...RequestOption,
+) (response ListBatchResponse, err error) {
+	urlSuffix := batchesSuffix + encodeQuery(pagination.values())
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -0,0 +1,417 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLocalBatchConcurrency = 8
+	defaultLocalBatchMaxRetries  = 3
+	defaultLocalBatchRetryDelay  = time.Second
+	maxLocalBatchLineSize        = 64 << 20
+
+	localBatchOutputFileID = "local_batch_output"
+	localBatchErrorFileID  = "local_batch_errors"
+)
+
+var (
+	ErrLocalBatchFileNotFound = errors.New("file is not part of the local batch")
+	ErrBatchMixedEndpoints    = errors.New("batch lines have different urls")
+
+	errLocalBatchUnsupported = errors.New("unsupported batch request")
+	errLocalBatchInvalidBody = errors.New("invalid batch request body")
+)
+
+// LocalBatchOptions configures RunLocalBatch. Zero values use the defaults.
+type LocalBatchOptions struct {
+	// Concurrency is the number of requests sent at once. Defaults to 8.
+	Concurrency int
+	// MaxRetries is how often a request failing with a retryable error is
+	// sent again. Defaults to 3; a negative value disables retries.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry of a request, doubled
+	// for every further retry. Defaults to 1s.
+	RetryDelay time.Duration
+}
+
+func (o LocalBatchOptions) withDefaults() LocalBatchOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultLocalBatchConcurrency
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultLocalBatchMaxRetries
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = defaultLocalBatchRetryDelay
+	}
+	return o
+}
+
+// LocalBatch is a batch run by RunLocalBatch. Batch describes it like a
+// completed batch of the Batch API, and Output and Errors hold the contents
+// of its output and error files, in the format of the Batch API.
+type LocalBatch struct {
+	Batch
+	Output []byte
+	Errors []byte
+}
+
+// GetFileContent returns the output or error file of the batch, so a
+// LocalBatch can stand in for the client reading batch files.
+func (b *LocalBatch) GetFileContent(_ context.Context, fileID string, _ ...RequestOption) (RawResponse, error) {
+	var content []byte
+	switch {
+	case b.OutputFileID != nil && fileID == *b.OutputFileID:
+		content = b.Output
+	case b.ErrorFileID != nil && fileID == *b.ErrorFileID:
+		content = b.Errors
+	default:
+		return RawResponse{}, fmt.Errorf("%w: %s", ErrLocalBatchFileNotFound, fileID)
+	}
+	return RawResponse{ReadCloser: io.NopCloser(bytes.NewReader(content))}, nil
+}
+
+// ReadResults returns a reader of the results of the batch, like
+// Client.ReadBatchResults does for batches of the Batch API.
+func (b *LocalBatch) ReadResults(ctx context.Context) *BatchResultReader {
+	return newBatchResultReader(ctx, b, b.Batch, nil)
+}
+
+// localBatchAPI is the part of Client and Router RunLocalBatch sends requests with.
+type localBatchAPI interface {
+	CreateChatCompletion(ctx context.Context, request ChatCompletionRequest, opts ...RequestOption) (
+		ChatCompletionResponse, error)
+	CreateCompletion(ctx context.Context, request CompletionRequest, opts ...RequestOption) (CompletionResponse, error)
+	CreateEmbeddings(ctx context.Context, conv EmbeddingRequestConverter, opts ...RequestOption) (
+		EmbeddingResponse, error)
+}
+
+// RunLocalBatch runs the lines of request against the client instead of the
+// Batch API, for backends which don't implement it. Chat completion,
+// completion and embedding lines are sent concurrently, retrying requests
+// which fail with retryable errors, and their results are returned as the
+// output and error files of a completed batch, holding the response bodies
+// as the API returned them. Like the Batch API, it requires
+// every line to have the same url and returns ErrBatchMixedEndpoints otherwise.
+func (c *Client) RunLocalBatch(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	options LocalBatchOptions,
+	opts ...RequestOption,
+) (*LocalBatch, error) {
+	return runLocalBatch(ctx, c, bytes.NewReader(request.MarshalJSONL()), options, opts)
+}
+
+// RunLocalBatchFile is RunLocalBatch for a batch input file in JSONL format.
+func (c *Client) RunLocalBatchFile(
+	ctx context.Context,
+	jsonl io.Reader,
+	options LocalBatchOptions,
+	opts ...RequestOption,
+) (*LocalBatch, error) {
+	return runLocalBatch(ctx, c, jsonl, options, opts)
+}
+
+// localBatchLine is a line of a batch input file.
+type localBatchLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      BatchEndpoint   `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// localBatchResult is a line of a batch output or error file.
+type localBatchResult struct {
+	ID       string               `json:"id"`
+	CustomID string               `json:"custom_id"`
+	Response *localBatchResponse  `json:"response"`
+	Error    *localBatchLineError `json:"error"`
+}
+
+type localBatchResponse struct {
+	StatusCode int    `json:"status_code"`
+	RequestID  string `json:"request_id"`
+	Body       any    `json:"body"`
+}
+
+type localBatchLineError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func readLocalBatchLines(jsonl io.Reader) ([]localBatchLine, error) {
+	var lines []localBatchLine
+	scanner := bufio.NewScanner(jsonl)
+	scanner.Buffer(nil, maxLocalBatchLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var line localBatchLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("batch input line %d: %w", n, err)
+		}
+		if len(lines) > 0 && line.URL != lines[0].URL {
+			return nil, fmt.Errorf("%w: batch input line %d is for %s, not %s",
+				ErrBatchMixedEndpoints, n, line.URL, lines[0].URL)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, ErrBatchNoLines
+	}
+	return lines, nil
+}
+
+func runLocalBatch(
+	ctx context.Context,
+	api localBatchAPI,
+	jsonl io.Reader,
+	options LocalBatchOptions,
+	opts []RequestOption,
+) (*LocalBatch, error) {
+	lines, err := readLocalBatchLines(jsonl)
+	if err != nil {
+		return nil, err
+	}
+	options = options.withDefaults()
+	batch := &LocalBatch{Batch: Batch{
+		ID:               fmt.Sprintf("batch_local_%d", time.Now().UnixNano()),
+		Object:           "batch",
+		Endpoint:         lines[0].URL,
+		CompletionWindow: "24h",
+		CreatedAt:        int(time.Now().Unix()),
+		RequestCounts:    BatchRequestCounts{Total: len(lines)},
+	}}
+
+	results := make([]localBatchResult, len(lines))
+	sem := make(chan struct{}, options.Concurrency)
+	var wg sync.WaitGroup
+	for i := range lines {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = runLocalBatchLine(ctx, api, lines[i], options, opts)
+			results[i].ID = fmt.Sprintf("batch_req_%d", i+1)
+		}(i)
+	}
+	wg.Wait()
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var output, errorsFile bytes.Buffer
+	for _, result := range results {
+		file := &output
+		if result.Error != nil || result.Response.StatusCode >= http.StatusBadRequest {
+			file = &errorsFile
+			batch.RequestCounts.Failed++
+		} else {
+			batch.RequestCounts.Completed++
+		}
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		file.Write(data)
+		file.WriteByte('\n')
+	}
+
+	batch.Status = batchStatusCompleted
+	completedAt := int(time.Now().Unix())
+	batch.CompletedAt = &completedAt
+	batch.Output, batch.Errors = output.Bytes(), errorsFile.Bytes()
+	if output.Len() > 0 {
+		outputFileID := localBatchOutputFileID
+		batch.OutputFileID = &outputFileID
+	}
+	if errorsFile.Len() > 0 {
+		errorFileID := localBatchErrorFileID
+		batch.ErrorFileID = &errorFileID
+	}
+	return batch, nil
+}
+
+// runLocalBatchLine sends the request of line, retrying retryable errors.
+func runLocalBatchLine(
+	ctx context.Context,
+	api localBatchAPI,
+	line localBatchLine,
+	options LocalBatchOptions,
+	opts []RequestOption,
+) localBatchResult {
+	delay := options.RetryDelay
+	for attempt := 0; ; attempt++ {
+		body, header, err := sendLocalBatchLine(ctx, api, line, opts)
+		if err == nil || attempt >= options.MaxRetries || !isRetryableError(err) {
+			return localBatchLineResult(line.CustomID, body, header, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return localBatchLineResult(line.CustomID, nil, nil, ctx.Err())
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// responseBodyRecorderKey is the context key of the responseBodyRecorder
+// Client.do copies response bodies into.
+type responseBodyRecorderKey struct{}
+
+// responseBodyRecorder keeps the raw body of the last response to a request,
+// so local batch output holds the bytes the API returned rather than the
+// typed response marshalled again, which drops the fields it doesn't model.
+type responseBodyRecorder struct {
+	mu   sync.Mutex
+	body *bytes.Buffer
+}
+
+func (r *responseBodyRecorder) record(body io.ReadCloser) io.ReadCloser {
+	buf := &bytes.Buffer{}
+	r.mu.Lock()
+	r.body = buf
+	r.mu.Unlock()
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.TeeReader(body, &recordedBody{recorder: r, buf: buf}), body}
+}
+
+// bytes returns the recorded body if it is valid JSON, and nil otherwise.
+func (r *responseBodyRecorder) bytes() json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.body == nil || !json.Valid(r.body.Bytes()) {
+		return nil
+	}
+	return append(json.RawMessage(nil), r.body.Bytes()...)
+}
+
+type recordedBody struct {
+	recorder *responseBodyRecorder
+	buf      *bytes.Buffer
+}
+
+func (b *recordedBody) Write(p []byte) (int, error) {
+	b.recorder.mu.Lock()
+	defer b.recorder.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// sendLocalBatchLine sends the request of line and returns the raw body of its
+// response, falling back to the typed response when none was recorded, and
+// to no body for failed requests.
+func sendLocalBatchLine(
+	ctx context.Context,
+	api localBatchAPI,
+	line localBatchLine,
+	opts []RequestOption,
+) (any, http.Header, error) {
+	recorder := &responseBodyRecorder{}
+	ctx = context.WithValue(ctx, responseBodyRecorderKey{}, recorder)
+	body, header, err := sendLocalBatchRequest(ctx, api, line, opts)
+	if raw := recorder.bytes(); raw != nil {
+		return raw, header, err
+	}
+	if err != nil {
+		return nil, header, err
+	}
+	return body, header, nil
+}
+
+func sendLocalBatchRequest(
+	ctx context.Context,
+	api localBatchAPI,
+	line localBatchLine,
+	opts []RequestOption,
+) (any, http.Header, error) {
+	if line.Method != "" && !strings.EqualFold(line.Method, http.MethodPost) {
+		return nil, nil, fmt.Errorf("%w: %s %s", errLocalBatchUnsupported, line.Method, line.URL)
+	}
+	switch line.URL {
+	case BatchEndpointChatCompletions:
+		var request ChatCompletionRequest
+		if err := json.Unmarshal(line.Body, &request); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errLocalBatchInvalidBody, err)
+		}
+		response, err := api.CreateChatCompletion(ctx, request, opts...)
+		return response, response.Header(), err
+	case BatchEndpointCompletions:
+		var request CompletionRequest
+		if err := json.Unmarshal(line.Body, &request); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errLocalBatchInvalidBody, err)
+		}
+		response, err := api.CreateCompletion(ctx, request, opts...)
+		return response, response.Header(), err
+	case BatchEndpointEmbeddings:
+		var request EmbeddingRequest
+		if err := json.Unmarshal(line.Body, &request); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", errLocalBatchInvalidBody, err)
+		}
+		response, err := api.CreateEmbeddings(ctx, request, opts...)
+		return response, response.Header(), err
+	default:
+		return nil, nil, fmt.Errorf("%w: %s %s", errLocalBatchUnsupported, line.Method, line.URL)
+	}
+}
+
+// localBatchLineResult converts the outcome of a request into a line of the
+// batch files: responses, including API errors, are recorded with their
+// status code, while requests which got no response are recorded as errors.
+func localBatchLineResult(customID string, body any, header http.Header, err error) localBatchResult {
+	result := localBatchResult{CustomID: customID}
+	var (
+		apiErr *APIError
+		reqErr *RequestError
+	)
+	switch {
+	case err == nil:
+		result.Response = &localBatchResponse{StatusCode: http.StatusOK, Body: body}
+	case errors.As(err, &apiErr) && apiErr.HTTPStatusCode != 0:
+		if body == nil {
+			body = ErrorResponse{Error: apiErr}
+		}
+		result.Response = &localBatchResponse{StatusCode: apiErr.HTTPStatusCode, Body: body}
+	case errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0:
+		if body == nil {
+			body = ErrorResponse{Error: &APIError{Message: reqErr.Error()}}
+		}
+		result.Response = &localBatchResponse{StatusCode: reqErr.HTTPStatusCode, Body: body}
+	case errors.Is(err, errLocalBatchUnsupported):
+		result.Error = &localBatchLineError{Code: "invalid_url", Message: err.Error()}
+	case errors.Is(err, errLocalBatchInvalidBody):
+		result.Error = &localBatchLineError{Code: "invalid_request", Message: err.Error()}
+	default:
+		result.Error = &localBatchLineError{Code: "request_failed", Message: err.Error()}
+	}
+	if result.Response != nil && header != nil {
+		result.Response.RequestID = header.Get("X-Request-Id")
+	}
+	return result
+}
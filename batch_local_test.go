@@ -0,0 +1,175 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func localBatchChatLine(id, content string) openai.BatchChatCompletionRequest {
+	return openai.BatchChatCompletionRequest{
+		CustomID: id,
+		Method:   http.MethodPost,
+		URL:      openai.BatchEndpointChatCompletions,
+		Body: openai.ChatCompletionRequest{
+			Model:    openai.GPT4oMini,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: content}},
+		},
+	}
+}
+
+func TestRunLocalBatch(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var (
+		mu    sync.Mutex
+		calls = map[string]int{}
+	)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		content := request.Messages[0].Content
+		mu.Lock()
+		calls[content]++
+		n := calls[content]
+		mu.Unlock()
+
+		switch {
+		case content == "flaky" && n == 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"message":"slow down","type":"requests"}}`)
+		case content == "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad request","type":"invalid_request_error"}}`)
+		default:
+			w.Header().Set("X-Request-Id", "req_"+content)
+			fmt.Fprintf(w, `{"id":"chatcmpl","choices":[{"message":{"role":"assistant","content":"re: %s"}}]}`, content)
+		}
+	})
+
+	request := openai.UploadBatchFileRequest{}
+	request.Lines = append(request.Lines,
+		localBatchChatLine("a", "hello"),
+		localBatchChatLine("b", "flaky"),
+		localBatchChatLine("c", "bad"),
+		openai.BatchChatCompletionRequest{CustomID: "d", Method: http.MethodGet, URL: openai.BatchEndpointChatCompletions},
+	)
+	batch, err := client.RunLocalBatch(context.Background(), request, openai.LocalBatchOptions{
+		Concurrency: 2,
+		RetryDelay:  time.Millisecond,
+	})
+	checks.NoError(t, err, "RunLocalBatch error")
+
+	if batch.Status != "completed" || batch.Endpoint != openai.BatchEndpointChatCompletions ||
+		batch.RequestCounts != (openai.BatchRequestCounts{Total: 4, Completed: 2, Failed: 2}) {
+		t.Fatalf("unexpected batch %+v", batch.Batch)
+	}
+	if calls["flaky"] != 2 || calls["bad"] != 1 {
+		t.Fatalf("expected only the rate limited request to be retried, got %v", calls)
+	}
+	if lines := strings.Count(string(batch.Output), "\n"); lines != 2 {
+		t.Fatalf("expected 2 output lines, got %q", batch.Output)
+	}
+
+	results, err := batch.ReadResults(context.Background()).Join(request).CollectByCustomID()
+	checks.NoError(t, err, "ReadResults error")
+	if a := results["a"]; a.Error != nil || a.RequestID != "req_hello" ||
+		a.ChatCompletion.Choices[0].Message.Content != "re: hello" || a.Request == nil {
+		t.Fatalf("unexpected result %+v", a)
+	}
+	if b := results["b"]; b.Error != nil || b.StatusCode != http.StatusOK {
+		t.Fatalf("expected the retry to succeed, got %+v", b)
+	}
+	if c := results["c"]; c.Error == nil || c.StatusCode != http.StatusBadRequest || c.Error.Message != "bad request" {
+		t.Fatalf("expected the API error, got %+v", c)
+	}
+	if d := results["d"]; d.Error == nil || d.Error.Code != "invalid_url" || d.StatusCode != 0 {
+		t.Fatalf("expected an unsupported request error, got %+v", d)
+	}
+}
+
+func TestRunLocalBatchFile(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"object":"list","data":[{"object":"embedding","embedding":[0.5,1],"index":0}]}`)
+	})
+
+	line := `{"custom_id":"e%d","method":"POST","url":"/v1/embeddings","body":{"model":"text-embedding-3-small"}}`
+	jsonl := fmt.Sprintf(line, 1) + "\n\n" + fmt.Sprintf(line, 2)
+	batch, err := client.RunLocalBatchFile(context.Background(), strings.NewReader(jsonl), openai.LocalBatchOptions{})
+	checks.NoError(t, err, "RunLocalBatchFile error")
+	if batch.ErrorFileID != nil || len(batch.Errors) != 0 {
+		t.Fatalf("expected no error file, got %q", batch.Errors)
+	}
+
+	results, err := batch.ReadResults(context.Background()).CollectByCustomID()
+	checks.NoError(t, err, "ReadResults error")
+	if len(results) != 2 || results["e2"].Embedding == nil || results["e2"].Embedding.Data[0].Embedding[1] != 1 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	_, err = client.RunLocalBatchFile(context.Background(), strings.NewReader("not json"), openai.LocalBatchOptions{})
+	if err == nil {
+		t.Fatal("expected a malformed line to fail the batch")
+	}
+
+	completionLine := `{"custom_id":"c1","method":"POST","url":"/v1/completions","body":{}}`
+	jsonl = fmt.Sprintf(line, 1) + "\n" + completionLine
+	_, err = client.RunLocalBatchFile(context.Background(), strings.NewReader(jsonl), openai.LocalBatchOptions{})
+	if !errors.Is(err, openai.ErrBatchMixedEndpoints) {
+		t.Fatalf("expected ErrBatchMixedEndpoints, got %v", err)
+	}
+}
+
+func TestRunLocalBatchKeepsRawResponseBodies(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	const (
+		chatBody = `{"id":"chatcmpl","unmodelled":{"kept":true},` +
+			`"choices":[{"message":{"role":"assistant","content":"hi","context":{"citations":[]}}}]}`
+		embeddingBody = `{"object":"list","data":[{"object":"embedding","embedding":"AAAAPwAAgD8=","index":0}]}`
+		errorBody     = `{"error":{"message":"bad request","type":"invalid_request_error","extra":"kept"}}`
+	)
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		if request.Messages[0].Content == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, errorBody)
+			return
+		}
+		fmt.Fprint(w, chatBody)
+	})
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, embeddingBody)
+	})
+
+	request := openai.UploadBatchFileRequest{}
+	request.Lines = append(request.Lines, localBatchChatLine("a", "hello"), localBatchChatLine("b", "bad"))
+	batch, err := client.RunLocalBatch(context.Background(), request, openai.LocalBatchOptions{})
+	checks.NoErrorF(t, err, "RunLocalBatch error")
+	if !strings.Contains(string(batch.Output), `"body":`+chatBody) {
+		t.Fatalf("expected the raw chat completion body in %q", batch.Output)
+	}
+	if !strings.Contains(string(batch.Errors), `"body":`+errorBody) {
+		t.Fatalf("expected the raw error body in %q", batch.Errors)
+	}
+
+	line := `{"custom_id":"e1","method":"POST","url":"/v1/embeddings",` +
+		`"body":{"model":"text-embedding-3-small","encoding_format":"base64"}}`
+	batch, err = client.RunLocalBatchFile(context.Background(), strings.NewReader(line), openai.LocalBatchOptions{})
+	checks.NoErrorF(t, err, "RunLocalBatchFile error")
+	if !strings.Contains(string(batch.Output), `"body":`+embeddingBody) {
+		t.Fatalf("expected the raw base64 embedding body in %q", batch.Output)
+	}
+}
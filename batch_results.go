@@ -0,0 +1,264 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BatchResult is one line of the output or error file of a batch.
+type BatchResult struct {
+	// ID is the ID of the batch request, not of the response.
+	ID       string
+	CustomID string
+	// StatusCode and RequestID describe the response. They are zero for
+	// requests which failed before being sent, like expired ones.
+	StatusCode int
+	RequestID  string
+	// Body is the raw response body. One of ChatCompletion, Completion and
+	// Embedding holds it decoded, according to the endpoint of the batch,
+	// when the request succeeded.
+	Body           json.RawMessage
+	ChatCompletion *ChatCompletionResponse
+	Completion     *CompletionResponse
+	Embedding      *EmbeddingResponse
+	// Error is set when the request failed.
+	Error *APIError
+	// Request is the line of the input with the same custom_id, if the
+	// reader was given the input with BatchResultReader.Join.
+	Request BatchLineItem
+}
+
+// batchResultLine is the JSON of a line of a batch output or error file.
+type batchResultLine struct {
+	ID       string `json:"id"`
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *APIError `json:"error"`
+}
+
+// batchFileAPI is the part of Client and Router a BatchResultReader uses.
+type batchFileAPI interface {
+	GetFileContent(ctx context.Context, fileID string, opts ...RequestOption) (RawResponse, error)
+}
+
+// BatchResultReader streams the results of a batch, first the lines of its
+// output file and then those of its error file.
+//
+//	results := client.ReadBatchResults(ctx, batch.Batch).Join(input)
+//	defer results.Close()
+//	for results.Next() {
+//		result := results.Current()
+//		...
+//	}
+//	if err := results.Err(); err != nil {
+//		return err
+//	}
+type BatchResultReader struct {
+	ctx      context.Context
+	api      batchFileAPI
+	opts     []RequestOption
+	endpoint BatchEndpoint
+	fileIDs  []string
+	requests map[string]BatchLineItem
+
+	file    io.ReadCloser
+	reader  *bufio.Reader
+	fileID  string
+	line    int
+	current BatchResult
+	err     error
+}
+
+// ReadBatchResults returns a reader of the results of batch. Results are
+// decoded according to batch.Endpoint, so batch should be retrieved after it
+// completed. Files are downloaded as the reader advances.
+func (c *Client) ReadBatchResults(ctx context.Context, batch Batch, opts ...RequestOption) *BatchResultReader {
+	return newBatchResultReader(ctx, c, batch, opts)
+}
+
+func newBatchResultReader(
+	ctx context.Context,
+	api batchFileAPI,
+	batch Batch,
+	opts []RequestOption,
+) *BatchResultReader {
+	r := &BatchResultReader{ctx: ctx, api: api, opts: opts, endpoint: batch.Endpoint}
+	for _, fileID := range []*string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID != nil && *fileID != "" {
+			r.fileIDs = append(r.fileIDs, *fileID)
+		}
+	}
+	return r
+}
+
+// Join sets BatchResult.Request of every result to the line of request with
+// the same custom_id.
+func (r *BatchResultReader) Join(request UploadBatchFileRequest) *BatchResultReader {
+	r.requests = make(map[string]BatchLineItem, len(request.Lines))
+	for _, line := range request.Lines {
+		var ids struct {
+			CustomID string `json:"custom_id"`
+		}
+		if json.Unmarshal(line.MarshalBatchLineItem(), &ids) == nil {
+			r.requests[ids.CustomID] = line
+		}
+	}
+	return r
+}
+
+// Next advances to the next result. It returns false at the end of the
+// files or when an error occurred.
+func (r *BatchResultReader) Next() bool {
+	for r.err == nil {
+		if r.reader == nil {
+			if len(r.fileIDs) == 0 {
+				return false
+			}
+			r.err = r.openNext()
+			continue
+		}
+
+		data, err := r.reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			r.line++
+			r.current, r.err = r.decode(data)
+			return r.err == nil
+		}
+		if errors.Is(err, io.EOF) {
+			r.err = r.closeFile()
+			continue
+		}
+		if err != nil {
+			r.err = err
+		}
+	}
+	return false
+}
+
+func (r *BatchResultReader) openNext() error {
+	r.fileID, r.fileIDs = r.fileIDs[0], r.fileIDs[1:]
+	file, err := r.api.GetFileContent(r.ctx, r.fileID, r.opts...)
+	if err != nil {
+		return fmt.Errorf("downloading batch results %s: %w", r.fileID, err)
+	}
+	r.file, r.reader, r.line = file, bufio.NewReader(file), 0
+	return nil
+}
+
+func (r *BatchResultReader) closeFile() error {
+	file := r.file
+	r.file, r.reader = nil, nil
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
+func (r *BatchResultReader) decode(data []byte) (BatchResult, error) {
+	var line batchResultLine
+	if err := json.Unmarshal(data, &line); err != nil {
+		return BatchResult{}, fmt.Errorf("batch results %s line %d: %w", r.fileID, r.line, err)
+	}
+	result := BatchResult{
+		ID:       line.ID,
+		CustomID: line.CustomID,
+		Error:    line.Error,
+		Request:  r.requests[line.CustomID],
+	}
+	if line.Response != nil {
+		result.StatusCode = line.Response.StatusCode
+		result.RequestID = line.Response.RequestID
+		result.Body = line.Response.Body
+	}
+
+	if result.StatusCode >= http.StatusBadRequest && result.Error == nil {
+		var errRes ErrorResponse
+		if err := json.Unmarshal(result.Body, &errRes); err == nil && errRes.Error != nil {
+			result.Error = errRes.Error
+		}
+	}
+	if result.Error != nil {
+		result.Error.HTTPStatusCode = result.StatusCode
+		if result.StatusCode != 0 {
+			result.Error.HTTPStatus = http.StatusText(result.StatusCode)
+		}
+		return result, nil
+	}
+	if len(result.Body) == 0 {
+		return result, nil
+	}
+	return result, r.decodeBody(&result)
+}
+
+func (r *BatchResultReader) decodeBody(result *BatchResult) error {
+	var target any
+	switch r.endpoint {
+	case BatchEndpointChatCompletions:
+		result.ChatCompletion = &ChatCompletionResponse{}
+		target = result.ChatCompletion
+	case BatchEndpointCompletions:
+		result.Completion = &CompletionResponse{}
+		target = result.Completion
+	case BatchEndpointEmbeddings:
+		result.Embedding = &EmbeddingResponse{}
+		target = result.Embedding
+	default:
+		return nil
+	}
+	if err := json.Unmarshal(result.Body, target); err != nil {
+		return fmt.Errorf("batch results %s line %d: %w", r.fileID, r.line, err)
+	}
+	return nil
+}
+
+// Current returns the result Next advanced to.
+func (r *BatchResultReader) Current() BatchResult {
+	return r.current
+}
+
+// Err returns the error which stopped the reader, if any.
+func (r *BatchResultReader) Err() error {
+	return r.err
+}
+
+// Close closes the file being read. It is only needed when the reader is
+// not read to the end.
+func (r *BatchResultReader) Close() error {
+	return r.closeFile()
+}
+
+// All returns a sequence of the remaining results. An error is yielded once
+// and ends the sequence. The reader is closed when the sequence ends.
+func (r *BatchResultReader) All() Seq2[BatchResult, error] {
+	return func(yield func(BatchResult, error) bool) {
+		defer r.Close()
+		for r.Next() {
+			if !yield(r.Current(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(BatchResult{}, err)
+		}
+	}
+}
+
+// CollectByCustomID reads the remaining results into a map keyed by custom_id.
+func (r *BatchResultReader) CollectByCustomID() (map[string]BatchResult, error) {
+	defer r.Close()
+	results := map[string]BatchResult{}
+	for r.Next() {
+		results[r.Current().CustomID] = r.Current()
+	}
+	return results, r.Err()
+}
@@ -0,0 +1,122 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const (
+	batchOutputFile = `{"id":"batch_req_1","custom_id":"ok","response":{"status_code":200,"request_id":"req_1",` +
+		`"body":{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Hi!"}}]}},"error":null}
+{"id":"batch_req_2","custom_id":"limited","response":{"status_code":429,"request_id":"req_2",` +
+		`"body":{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}},"error":null}
+`
+	batchErrorFile = `{"id":"batch_req_3","custom_id":"expired","response":null,` +
+		`"error":{"code":"batch_expired","message":"This request could not be executed before the batch expired."}}`
+)
+
+func registerBatchFiles(server *test.ServerTest) {
+	server.RegisterHandler("/v1/files/file_out/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, batchOutputFile)
+	})
+	server.RegisterHandler("/v1/files/file_err/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, batchErrorFile)
+	})
+}
+
+func completedChatBatch() openai.Batch {
+	outputFileID, errorFileID := "file_out", "file_err"
+	return openai.Batch{
+		ID:           "batch_1",
+		Endpoint:     openai.BatchEndpointChatCompletions,
+		OutputFileID: &outputFileID,
+		ErrorFileID:  &errorFileID,
+	}
+}
+
+func TestReadBatchResults(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	registerBatchFiles(server)
+
+	input := openai.UploadBatchFileRequest{}
+	for _, id := range []string{"ok", "limited", "expired"} {
+		input.AddChatCompletion(id, openai.ChatCompletionRequest{Model: openai.GPT4oMini})
+	}
+
+	results, err := client.ReadBatchResults(context.Background(), completedChatBatch()).Join(input).CollectByCustomID()
+	checks.NoError(t, err, "ReadBatchResults error")
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	ok := results["ok"]
+	if ok.Error != nil || ok.ChatCompletion == nil || ok.ChatCompletion.Choices[0].Message.Content != "Hi!" {
+		t.Fatalf("unexpected successful result %+v", ok)
+	}
+	if line, isChat := ok.Request.(openai.BatchChatCompletionRequest); !isChat || line.CustomID != "ok" {
+		t.Fatalf("expected the joined request, got %+v", ok.Request)
+	}
+
+	limited := results["limited"]
+	if limited.Error == nil || limited.Error.HTTPStatusCode != http.StatusTooManyRequests ||
+		limited.Error.Code != "rate_limit_exceeded" || limited.ChatCompletion != nil {
+		t.Fatalf("unexpected failed result %+v", limited)
+	}
+
+	expired := results["expired"]
+	if expired.Error == nil || expired.Error.Code != "batch_expired" || expired.StatusCode != 0 {
+		t.Fatalf("unexpected expired result %+v", expired)
+	}
+}
+
+func TestReadBatchResultsStreamsInOrder(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	registerBatchFiles(server)
+
+	var ids []string
+	results := client.ReadBatchResults(context.Background(), completedChatBatch())
+	results.All()(func(result openai.BatchResult, err error) bool {
+		checks.NoError(t, err, "ReadBatchResults error")
+		ids = append(ids, result.CustomID)
+		return true
+	})
+	if fmt.Sprint(ids) != "[ok limited expired]" {
+		t.Fatalf("unexpected order %v", ids)
+	}
+}
+
+func TestReadBatchResultsErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/files/file_out/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "{\"custom_id\":\"ok\"}\nnot json\n")
+	})
+
+	batch := completedChatBatch()
+	batch.ErrorFileID = nil
+	reader := client.ReadBatchResults(context.Background(), batch)
+	defer reader.Close()
+	if !reader.Next() || reader.Current().CustomID != "ok" {
+		t.Fatalf("expected the first line, got %+v, %v", reader.Current(), reader.Err())
+	}
+	if reader.Next() || reader.Err() == nil {
+		t.Fatal("expected the malformed line to stop the reader")
+	}
+
+	missing := "missing"
+	batch.OutputFileID = &missing
+	_, err := client.ReadBatchResults(context.Background(), batch).CollectByCustomID()
+	var reqErr *openai.RequestError
+	if !errors.As(err, &reqErr) || reqErr.HTTPStatusCode != http.StatusNotFound {
+		t.Fatalf("expected a download error, got %v", err)
+	}
+}
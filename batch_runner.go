@@ -0,0 +1,485 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// maxBatchRequestsPerFile and maxBatchBytesPerFile are the limits of a batch input file.
+	maxBatchRequestsPerFile     = 50000
+	maxBatchBytesPerFile        = 200 << 20
+	defaultBatchPollInterval    = 5 * time.Second
+	defaultBatchMaxPollInterval = time.Minute
+	defaultBatchMaxRetries      = 1
+	batchFileDeleteTimeout      = 30 * time.Second
+
+	batchStatusValidating = "validating"
+	batchStatusInProgress = "in_progress"
+	batchStatusFinalizing = "finalizing"
+	batchStatusCompleted  = "completed"
+	batchStatusFailed     = "failed"
+	batchStatusExpired    = "expired"
+	batchStatusCancelling = "cancelling"
+	batchStatusCancelled  = "cancelled"
+)
+
+var (
+	ErrBatchNoLines           = errors.New("no batch lines to run")
+	ErrBatchDuplicateCustomID = errors.New("duplicate batch custom_id")
+	ErrBatchLineTooLarge      = errors.New("batch line is larger than the file size limit")
+	ErrBatchUnexpectedStatus  = errors.New("unexpected batch status")
+	ErrBatchStateMismatch     = errors.New("batch state file was written for other lines")
+)
+
+// BatchRunnerOptions configures a BatchRunner. Zero values use the defaults.
+type BatchRunnerOptions struct {
+	// CompletionWindow and Metadata are set on every batch created.
+	CompletionWindow string
+	Metadata         map[string]any
+	// MaxRequestsPerFile defaults to 50,000, the API limit.
+	MaxRequestsPerFile int
+	// MaxBytesPerFile defaults to 200MB, the API limit.
+	MaxBytesPerFile int64
+	// PollInterval is the delay before the first poll of the batches,
+	// doubled after every poll up to MaxPollInterval. Defaults to 5s and 1m.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	// MaxRetries is how many follow-up batches are submitted for lines which
+	// failed with a retryable error or didn't run. Defaults to 1; a negative
+	// value disables retries.
+	MaxRetries int
+	// OnProgress is called after every poll.
+	OnProgress func(progress BatchRunProgress)
+	// StatePath, if set, is a JSON file recording the created batches. Run
+	// resumes the batches it lists instead of submitting their lines again.
+	// It is removed once Run returns the results. Run returns
+	// ErrBatchStateMismatch if the file was written for other lines.
+	StatePath string
+}
+
+func (o BatchRunnerOptions) withDefaults() BatchRunnerOptions {
+	if o.MaxRequestsPerFile <= 0 || o.MaxRequestsPerFile > maxBatchRequestsPerFile {
+		o.MaxRequestsPerFile = maxBatchRequestsPerFile
+	}
+	if o.MaxBytesPerFile <= 0 || o.MaxBytesPerFile > maxBatchBytesPerFile {
+		o.MaxBytesPerFile = maxBatchBytesPerFile
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultBatchPollInterval
+	}
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = defaultBatchMaxPollInterval
+		if o.MaxPollInterval < o.PollInterval {
+			o.MaxPollInterval = o.PollInterval
+		}
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultBatchMaxRetries
+	}
+	return o
+}
+
+// BatchRunProgress is the state of the batches of one round of a run.
+type BatchRunProgress struct {
+	// Round is 0 for the first batches and counts the follow-up batches after it.
+	Round   int
+	Batches []Batch
+	// Counts sums the request counts of Batches.
+	Counts BatchRequestCounts
+}
+
+// BatchRunResult holds the outcome of every line of a run.
+type BatchRunResult struct {
+	// Results maps every custom_id to its result. Lines which failed in
+	// every round hold their last failure.
+	Results map[string]BatchResult
+	// Batches are all batches created by the run, as last retrieved.
+	Batches []Batch
+}
+
+// BatchRunState is what a BatchRunner persists to StatePath.
+type BatchRunState struct {
+	// Lines and CustomIDsDigest identify the lines of the run, so its batches
+	// are never resumed for other lines.
+	Lines           int                  `json:"lines"`
+	CustomIDsDigest string               `json:"custom_ids_digest"`
+	Batches         []BatchRunStateBatch `json:"batches"`
+}
+
+type BatchRunStateBatch struct {
+	ID        string   `json:"id"`
+	Round     int      `json:"round"`
+	CustomIDs []string `json:"custom_ids"`
+}
+
+// batchRunnerAPI is the part of Client and Router a BatchRunner drives.
+type batchRunnerAPI interface {
+	batchFileAPI
+	UploadBatchFile(ctx context.Context, request UploadBatchFileRequest, opts ...RequestOption) (File, error)
+	CreateBatch(ctx context.Context, request CreateBatchRequest, opts ...RequestOption) (BatchResponse, error)
+	RetrieveBatch(ctx context.Context, batchID string, opts ...RequestOption) (BatchResponse, error)
+	DeleteFile(ctx context.Context, fileID string, opts ...RequestOption) error
+}
+
+// BatchRunner runs any number of batch lines through the Batch API: it
+// splits them into files within the batch limits, submits the batches,
+// waits for them and collects their results, retrying failed lines.
+type BatchRunner struct {
+	api     batchRunnerAPI
+	options BatchRunnerOptions
+	opts    []RequestOption
+}
+
+// NewBatchRunner returns a BatchRunner using the client. opts are passed to every call it makes.
+func (c *Client) NewBatchRunner(options BatchRunnerOptions, opts ...RequestOption) *BatchRunner {
+	return &BatchRunner{api: c, options: options.withDefaults(), opts: opts}
+}
+
+// batchRunLine is a parsed input line.
+type batchRunLine struct {
+	item     BatchLineItem
+	customID string
+	url      BatchEndpoint
+	model    string
+	size     int64
+}
+
+// runBatch is a created batch with the lines it was given.
+type runBatch struct {
+	batch     Batch
+	customIDs []string
+}
+
+// Run submits lines and returns the result of every one. Every line must
+// have a unique custom_id. Lines are grouped by endpoint and model, as a
+// batch file may only hold one of each. When Run fails, the Batches of the
+// result are those it created so far, which keep running unless cancelled.
+func (r *BatchRunner) Run(ctx context.Context, lines []BatchLineItem) (BatchRunResult, error) {
+	parsed, order, err := parseBatchRunLines(lines, r.options.MaxBytesPerFile)
+	if err != nil {
+		return BatchRunResult{}, err
+	}
+	state := BatchRunState{Lines: len(order), CustomIDsDigest: batchCustomIDsDigest(order)}
+	if r.options.StatePath != "" {
+		saved := state
+		if err = readJSONFile(r.options.StatePath, &saved); err != nil {
+			return BatchRunResult{}, err
+		}
+		if saved.Lines != state.Lines || saved.CustomIDsDigest != state.CustomIDsDigest {
+			return BatchRunResult{}, fmt.Errorf("%w: %s holds %d lines", ErrBatchStateMismatch,
+				r.options.StatePath, saved.Lines)
+		}
+		state = saved
+	}
+
+	result := BatchRunResult{Results: make(map[string]BatchResult, len(order))}
+	pending := order
+	for round := 0; len(pending) > 0; round++ {
+		var batches []runBatch
+		batches, err = r.submitRound(ctx, round, pending, parsed, &state)
+		if err == nil {
+			batches, err = r.wait(ctx, round, batches)
+		}
+		for _, b := range batches {
+			result.Batches = append(result.Batches, b.batch)
+		}
+		if err != nil {
+			return result, err
+		}
+
+		var retry []string
+		retry, err = r.collect(ctx, batches, parsed, result.Results)
+		if err != nil {
+			return result, err
+		}
+		if round >= r.options.MaxRetries {
+			break
+		}
+		pending = retry
+	}
+
+	if r.options.StatePath != "" {
+		if err = os.Remove(r.options.StatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func parseBatchRunLines(lines []BatchLineItem, maxBytes int64) (map[string]batchRunLine, []string, error) {
+	if len(lines) == 0 {
+		return nil, nil, ErrBatchNoLines
+	}
+	parsed := make(map[string]batchRunLine, len(lines))
+	order := make([]string, 0, len(lines))
+	for _, item := range lines {
+		data := item.MarshalBatchLineItem()
+		var fields struct {
+			CustomID string        `json:"custom_id"`
+			URL      BatchEndpoint `json:"url"`
+			Body     struct {
+				Model string `json:"model"`
+			} `json:"body"`
+		}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, nil, fmt.Errorf("parsing batch line: %w", err)
+		}
+		if _, ok := parsed[fields.CustomID]; ok {
+			return nil, nil, fmt.Errorf("%w %q", ErrBatchDuplicateCustomID, fields.CustomID)
+		}
+		// Every line is followed by a newline in the file.
+		size := int64(len(data) + 1)
+		if size > maxBytes {
+			return nil, nil, fmt.Errorf("%w: %q is %d bytes", ErrBatchLineTooLarge, fields.CustomID, size)
+		}
+		parsed[fields.CustomID] = batchRunLine{
+			item:     item,
+			customID: fields.CustomID,
+			url:      fields.URL,
+			model:    fields.Body.Model,
+			size:     size,
+		}
+		order = append(order, fields.CustomID)
+	}
+	return parsed, order, nil
+}
+
+// batchCustomIDsDigest hashes customIDs in order.
+func batchCustomIDsDigest(customIDs []string) string {
+	hash := sha256.New()
+	for _, id := range customIDs {
+		fmt.Fprintf(hash, "%d:%s", len(id), id)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// shardBatchLines splits customIDs into files holding a single endpoint and
+// model, within the request and size limits, keeping their order.
+func shardBatchLines(
+	customIDs []string,
+	parsed map[string]batchRunLine,
+	maxRequests int,
+	maxBytes int64,
+) [][]batchRunLine {
+	type group struct {
+		shards [][]batchRunLine
+		size   int64
+	}
+	groups := map[string]*group{}
+	var keys []string
+	for _, id := range customIDs {
+		line := parsed[id]
+		key := string(line.url) + "\x00" + line.model
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		last := len(g.shards) - 1
+		if last < 0 || len(g.shards[last]) >= maxRequests || g.size+line.size > maxBytes {
+			g.shards = append(g.shards, nil)
+			last++
+			g.size = 0
+		}
+		g.shards[last] = append(g.shards[last], line)
+		g.size += line.size
+	}
+
+	var shards [][]batchRunLine
+	for _, key := range keys {
+		shards = append(shards, groups[key].shards...)
+	}
+	return shards
+}
+
+// submitRound resumes the batches state lists for round and submits the
+// lines of pending none of them holds.
+func (r *BatchRunner) submitRound(
+	ctx context.Context,
+	round int,
+	pending []string,
+	parsed map[string]batchRunLine,
+	state *BatchRunState,
+) ([]runBatch, error) {
+	var batches []runBatch
+	covered := map[string]bool{}
+	for _, saved := range state.Batches {
+		if saved.Round != round {
+			continue
+		}
+		response, err := r.api.RetrieveBatch(ctx, saved.ID, r.opts...)
+		if err != nil {
+			return batches, fmt.Errorf("resuming batch %s: %w", saved.ID, err)
+		}
+		batches = append(batches, runBatch{batch: response.Batch, customIDs: saved.CustomIDs})
+		for _, id := range saved.CustomIDs {
+			covered[id] = true
+		}
+	}
+
+	var missing []string
+	for _, id := range pending {
+		if !covered[id] {
+			missing = append(missing, id)
+		}
+	}
+	for i, shard := range shardBatchLines(missing, parsed, r.options.MaxRequestsPerFile, r.options.MaxBytesPerFile) {
+		created, err := r.create(ctx, round, i, shard)
+		if err != nil {
+			return batches, err
+		}
+		batches = append(batches, created)
+		state.Batches = append(state.Batches, BatchRunStateBatch{
+			ID:        created.batch.ID,
+			Round:     round,
+			CustomIDs: created.customIDs,
+		})
+		if r.options.StatePath != "" {
+			if err = writeJSONFile(r.options.StatePath, state); err != nil {
+				return batches, err
+			}
+		}
+	}
+	return batches, nil
+}
+
+func (r *BatchRunner) create(ctx context.Context, round, index int, shard []batchRunLine) (runBatch, error) {
+	request := UploadBatchFileRequest{FileName: fmt.Sprintf("batch_round%d_%d.jsonl", round, index)}
+	customIDs := make([]string, len(shard))
+	for i, line := range shard {
+		request.Lines = append(request.Lines, line.item)
+		customIDs[i] = line.customID
+	}
+	file, err := r.api.UploadBatchFile(ctx, request, r.opts...)
+	if err != nil {
+		return runBatch{}, fmt.Errorf("uploading batch file: %w", err)
+	}
+	response, err := r.api.CreateBatch(ctx, CreateBatchRequest{
+		InputFileID:      file.ID,
+		Endpoint:         shard[0].url,
+		CompletionWindow: r.options.CompletionWindow,
+		Metadata:         r.options.Metadata,
+	}, r.opts...)
+	if err != nil {
+		r.deleteFile(file.ID)
+		return runBatch{}, fmt.Errorf("creating batch: %w", err)
+	}
+	return runBatch{batch: response.Batch, customIDs: customIDs}, nil
+}
+
+// deleteFile deletes the input file of a batch which couldn't be created. It
+// runs after ctx may have ended, and the error of creating the batch is the
+// one returned, so it has a context and ignores errors of its own.
+func (r *BatchRunner) deleteFile(fileID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), batchFileDeleteTimeout)
+	defer cancel()
+	_ = r.api.DeleteFile(ctx, fileID, r.opts...)
+}
+
+// batchDone reports whether batch reached a terminal status.
+func batchDone(batch Batch) (bool, error) {
+	switch batch.Status {
+	case batchStatusCompleted, batchStatusFailed, batchStatusExpired, batchStatusCancelled:
+		return true, nil
+	case batchStatusValidating, batchStatusInProgress, batchStatusFinalizing, batchStatusCancelling:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w %q of batch %q", ErrBatchUnexpectedStatus, batch.Status, batch.ID)
+	}
+}
+
+// wait polls batches until all of them reached a terminal status.
+func (r *BatchRunner) wait(ctx context.Context, round int, batches []runBatch) ([]runBatch, error) {
+	interval := r.options.PollInterval
+	for {
+		done := true
+		progress := BatchRunProgress{Round: round}
+		for _, b := range batches {
+			finished, err := batchDone(b.batch)
+			if err != nil {
+				return batches, err
+			}
+			done = done && finished
+			progress.Batches = append(progress.Batches, b.batch)
+			progress.Counts.Total += b.batch.RequestCounts.Total
+			progress.Counts.Completed += b.batch.RequestCounts.Completed
+			progress.Counts.Failed += b.batch.RequestCounts.Failed
+		}
+		if r.options.OnProgress != nil {
+			r.options.OnProgress(progress)
+		}
+		if done {
+			return batches, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return batches, ctx.Err()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > r.options.MaxPollInterval {
+			interval = r.options.MaxPollInterval
+		}
+
+		for i, b := range batches {
+			if finished, _ := batchDone(b.batch); finished {
+				continue
+			}
+			response, err := r.api.RetrieveBatch(ctx, b.batch.ID, r.opts...)
+			if err != nil {
+				return batches, err
+			}
+			batches[i].batch = response.Batch
+		}
+	}
+}
+
+// collect reads the results of batches into results and returns the lines to retry.
+func (r *BatchRunner) collect(
+	ctx context.Context,
+	batches []runBatch,
+	parsed map[string]batchRunLine,
+	results map[string]BatchResult,
+) ([]string, error) {
+	var retry []string
+	for _, b := range batches {
+		input := UploadBatchFileRequest{}
+		for _, id := range b.customIDs {
+			input.Lines = append(input.Lines, parsed[id].item)
+		}
+		read, err := newBatchResultReader(ctx, r.api, b.batch, r.opts).Join(input).CollectByCustomID()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range b.customIDs {
+			result, ok := read[id]
+			if !ok {
+				message := fmt.Sprintf("batch %s %s without a result", b.batch.ID, b.batch.Status)
+				result = BatchResult{CustomID: id, Request: parsed[id].item, Error: &APIError{Message: message}}
+			}
+			results[id] = result
+			if result.Error != nil && retryableBatchResult(result) {
+				retry = append(retry, id)
+			}
+		}
+	}
+	return retry, nil
+}
+
+// retryableBatchResult reports whether a failed line may succeed in another
+// batch: it wasn't run, was rate limited or hit a server error.
+func retryableBatchResult(result BatchResult) bool {
+	return result.StatusCode == 0 || result.StatusCode == http.StatusTooManyRequests ||
+		result.StatusCode >= http.StatusInternalServerError
+}
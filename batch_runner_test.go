@@ -0,0 +1,331 @@
+package openai_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// batchServer is a fake of the Files and Batch APIs. Batches are in progress
+// on the first poll and complete on the second.
+type batchServer struct {
+	mu      sync.Mutex
+	files   map[string]string
+	batches map[string]*fakeBatch
+	// failOnce holds the custom_ids answered with a 500 the first time they run.
+	failOnce map[string]bool
+	uploads  int
+	// maxBatches, if set, is the number of batches created before creating
+	// another one fails.
+	maxBatches int
+}
+
+type fakeBatch struct {
+	openai.Batch
+	customIDs []string
+	models    map[string]bool
+	polls     int
+}
+
+func newBatchServer(server *test.ServerTest, failOnce ...string) *batchServer {
+	s := &batchServer{files: map[string]string{}, batches: map[string]*fakeBatch{}, failOnce: map[string]bool{}}
+	for _, id := range failOnce {
+		s.failOnce[id] = true
+	}
+	server.RegisterHandler("/v1/files", s.uploadFile)
+	server.RegisterHandler("/v1/files/*/content", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/files/"), "/content")
+		fmt.Fprint(w, s.files[id])
+	})
+	server.RegisterHandler("/v1/batches", s.createBatch)
+	server.RegisterHandler("/v1/batches/*", s.retrieveBatch)
+	return s
+}
+
+func (s *batchServer) uploadFile(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	content, _ := io.ReadAll(file)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads++
+	id := fmt.Sprintf("file_in%d", s.uploads)
+	s.files[id] = string(content)
+	fmt.Fprintf(w, `{"id":%q,"purpose":"batch"}`, id)
+}
+
+func (s *batchServer) createBatch(w http.ResponseWriter, r *http.Request) {
+	var request openai.CreateBatchRequest
+	_ = json.NewDecoder(r.Body).Decode(&request)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxBatches > 0 && len(s.batches) >= s.maxBatches {
+		http.Error(w, `{"error":{"message":"batch quota exceeded"}}`, http.StatusBadRequest)
+		return
+	}
+
+	batch := &fakeBatch{models: map[string]bool{}}
+	batch.ID = fmt.Sprintf("batch_%d", len(s.batches)+1)
+	batch.Endpoint = request.Endpoint
+	batch.InputFileID = request.InputFileID
+	batch.Status = "validating"
+	scanner := bufio.NewScanner(strings.NewReader(s.files[request.InputFileID]))
+	for scanner.Scan() {
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Body     struct {
+				Model string `json:"model"`
+			} `json:"body"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &line)
+		batch.customIDs = append(batch.customIDs, line.CustomID)
+		batch.models[line.Body.Model] = true
+	}
+	batch.RequestCounts.Total = len(batch.customIDs)
+	s.batches[batch.ID] = batch
+	_ = json.NewEncoder(w).Encode(batch.Batch)
+}
+
+func (s *batchServer) retrieveBatch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, ok := s.batches[strings.TrimPrefix(r.URL.Path, "/v1/batches/")]
+	if !ok {
+		http.Error(w, `{"error":{"message":"no such batch"}}`, http.StatusNotFound)
+		return
+	}
+	batch.polls++
+	switch {
+	case batch.polls == 1:
+		batch.Status = "in_progress"
+	case batch.Status == "in_progress":
+		s.complete(batch)
+	}
+	_ = json.NewEncoder(w).Encode(batch.Batch)
+}
+
+func (s *batchServer) complete(batch *fakeBatch) {
+	var output strings.Builder
+	for _, id := range batch.customIDs {
+		if s.failOnce[id] {
+			delete(s.failOnce, id)
+			batch.RequestCounts.Failed++
+			fmt.Fprintf(&output, `{"custom_id":%q,"response":{"status_code":500,`+
+				`"body":{"error":{"message":"server error"}}}}`+"\n", id)
+			continue
+		}
+		batch.RequestCounts.Completed++
+		fmt.Fprintf(&output, `{"custom_id":%q,"response":{"status_code":200,"body":{"id":"resp_%s"}}}`+"\n", id, id)
+	}
+	outputFileID := "file_out_" + batch.ID
+	s.files[outputFileID] = output.String()
+	batch.OutputFileID = &outputFileID
+	batch.Status = "completed"
+}
+
+func batchRunnerLines() []openai.BatchLineItem {
+	chat := func(id, model string) openai.BatchLineItem {
+		return openai.BatchChatCompletionRequest{
+			CustomID: id,
+			Method:   http.MethodPost,
+			URL:      openai.BatchEndpointChatCompletions,
+			Body:     openai.ChatCompletionRequest{Model: model},
+		}
+	}
+	return []openai.BatchLineItem{
+		chat("c1", openai.GPT4oMini),
+		chat("c2", openai.GPT4oMini),
+		chat("c3", openai.GPT4o),
+		chat("c4", openai.GPT4oMini),
+		openai.BatchEmbeddingRequest{
+			CustomID: "e1",
+			Method:   http.MethodPost,
+			URL:      openai.BatchEndpointEmbeddings,
+			Body:     openai.EmbeddingRequest{Model: openai.SmallEmbedding3, Input: "hello"},
+		},
+	}
+}
+
+func TestBatchRunnerShardsAndRetries(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := newBatchServer(server, "c2")
+
+	var progress []openai.BatchRunProgress
+	result, err := client.NewBatchRunner(openai.BatchRunnerOptions{
+		MaxRequestsPerFile: 2,
+		PollInterval:       time.Millisecond,
+		OnProgress:         func(p openai.BatchRunProgress) { progress = append(progress, p) },
+	}).Run(context.Background(), batchRunnerLines())
+	checks.NoError(t, err, "Run error")
+
+	// Round 0 shards the lines into c1+c2, c4, c3 and e1; round 1 retries c2.
+	if len(result.Batches) != 5 {
+		t.Fatalf("expected 5 batches, got %d", len(result.Batches))
+	}
+	for _, batch := range fake.batches {
+		if len(batch.models) != 1 || len(batch.customIDs) > 2 {
+			t.Fatalf("batch %s holds %v for models %v", batch.ID, batch.customIDs, batch.models)
+		}
+	}
+	if ids := fake.batches["batch_5"].customIDs; fmt.Sprint(ids) != "[c2]" {
+		t.Fatalf("expected the retry batch to hold c2, got %v", ids)
+	}
+
+	if len(result.Results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(result.Results))
+	}
+	for id, res := range result.Results {
+		if res.Error != nil || res.StatusCode != http.StatusOK || res.Request == nil {
+			t.Fatalf("unexpected result for %s: %+v", id, res)
+		}
+	}
+	if res := result.Results["c3"]; res.ChatCompletion == nil || res.ChatCompletion.ID != "resp_c3" {
+		t.Fatalf("expected a decoded chat completion, got %+v", res)
+	}
+
+	last := progress[len(progress)-1]
+	if last.Round != 1 || last.Counts != (openai.BatchRequestCounts{Total: 1, Completed: 1}) {
+		t.Fatalf("unexpected final progress %+v", last)
+	}
+	for _, p := range progress {
+		if p.Round == 0 && p.Counts.Total != 5 {
+			t.Fatalf("expected the first round to count 5 requests, got %+v", p.Counts)
+		}
+	}
+}
+
+func TestBatchRunnerWithoutRetries(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	newBatchServer(server, "c2")
+
+	result, err := client.NewBatchRunner(openai.BatchRunnerOptions{
+		MaxRetries:   -1,
+		PollInterval: time.Millisecond,
+	}).Run(context.Background(), batchRunnerLines())
+	checks.NoError(t, err, "Run error")
+	if len(result.Batches) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(result.Batches))
+	}
+	if res := result.Results["c2"]; res.Error == nil || res.Error.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected c2 to keep its failure, got %+v", res)
+	}
+}
+
+func TestBatchRunnerResumes(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := newBatchServer(server)
+	statePath := filepath.Join(t.TempDir(), "batches.json")
+
+	// The first run is interrupted once its batches are created.
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := client.NewBatchRunner(openai.BatchRunnerOptions{
+		StatePath:    statePath,
+		PollInterval: time.Millisecond,
+		OnProgress:   func(openai.BatchRunProgress) { cancel() },
+	}).Run(ctx, batchRunnerLines())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be cancelled, got %v", err)
+	}
+	data, err := os.ReadFile(statePath)
+	checks.NoError(t, err, "ReadFile error")
+	var state openai.BatchRunState
+	checks.NoError(t, json.Unmarshal(data, &state), "Unmarshal error")
+	if len(state.Batches) != 3 || state.Lines != 5 {
+		t.Fatalf("expected 3 saved batches of 5 lines, got %+v", state)
+	}
+
+	// The batches aren't resumed for other lines.
+	_, err = client.NewBatchRunner(openai.BatchRunnerOptions{
+		StatePath:    statePath,
+		PollInterval: time.Millisecond,
+	}).Run(context.Background(), batchRunnerLines()[1:])
+	if !errors.Is(err, openai.ErrBatchStateMismatch) {
+		t.Fatalf("expected ErrBatchStateMismatch, got %v", err)
+	}
+
+	uploads := fake.uploads
+	result, err := client.NewBatchRunner(openai.BatchRunnerOptions{
+		StatePath:    statePath,
+		PollInterval: time.Millisecond,
+	}).Run(context.Background(), batchRunnerLines())
+	checks.NoError(t, err, "Run error")
+	if fake.uploads != uploads || len(fake.batches) != 3 {
+		t.Fatalf("expected the batches to be resumed, got %d uploads and %d batches", fake.uploads, len(fake.batches))
+	}
+	if len(result.Results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(result.Results))
+	}
+	if _, err = os.Stat(statePath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the state file to be removed, got %v", err)
+	}
+}
+
+func TestBatchRunnerCreateFailure(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := newBatchServer(server)
+	fake.maxBatches = 1
+	deleted := false
+	server.RegisterHandler("/v1/files/file_in2", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		deleted = r.Method == http.MethodDelete
+		fmt.Fprint(w, `{"id":"file_in2","deleted":true}`)
+	})
+
+	result, err := client.NewBatchRunner(openai.BatchRunnerOptions{PollInterval: time.Millisecond}).
+		Run(context.Background(), batchRunnerLines())
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the batch creation to fail, got %v", err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if !deleted {
+		t.Fatal("expected the input file of the failed batch to be deleted")
+	}
+	if len(result.Batches) != 1 || result.Batches[0].ID != "batch_1" {
+		t.Fatalf("expected the created batch in the result, got %+v", result.Batches)
+	}
+}
+
+func TestBatchRunnerRejectsInvalidLines(t *testing.T) {
+	client := openai.NewClient("token")
+	runner := client.NewBatchRunner(openai.BatchRunnerOptions{})
+
+	if _, err := runner.Run(context.Background(), nil); !errors.Is(err, openai.ErrBatchNoLines) {
+		t.Fatalf("expected ErrBatchNoLines, got %v", err)
+	}
+	lines := batchRunnerLines()
+	lines = append(lines, lines[0])
+	if _, err := runner.Run(context.Background(), lines); !errors.Is(err, openai.ErrBatchDuplicateCustomID) {
+		t.Fatalf("expected ErrBatchDuplicateCustomID, got %v", err)
+	}
+
+	runner = client.NewBatchRunner(openai.BatchRunnerOptions{MaxBytesPerFile: 10})
+	if _, err := runner.Run(context.Background(), lines[:1]); !errors.Is(err, openai.ErrBatchLineTooLarge) {
+		t.Fatalf("expected ErrBatchLineTooLarge, got %v", err)
+	}
+}
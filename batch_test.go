@@ -88,7 +88,7 @@ func TestListBatch(t *testing.T) {
 	server.RegisterHandler("/v1/batches", handleBatchEndpoint)
 	after := "batch_abc123"
 	limit := 10
-	_, err := client.ListBatch(context.Background(), &after, &limit)
+	_, err := client.ListBatch(context.Background(), openai.Pagination{After: &after, Limit: &limit})
 	checks.NoError(t, err, "RetrieveBatch error")
 }
 
@@ -52,6 +52,63 @@ type Profanity struct {
 	Detected bool `json:"detected"`
 }
 
+// IndirectAttack reports attacks embedded in grounding documents or tool results.
+type IndirectAttack struct {
+	Filtered bool `json:"filtered"`
+	Detected bool `json:"detected"`
+}
+
+// ProtectedMaterialText reports known text content, such as song lyrics, in a completion.
+type ProtectedMaterialText struct {
+	Filtered bool `json:"filtered"`
+	Detected bool `json:"detected"`
+}
+
+// ProtectedMaterialCode reports source code from public repositories in a completion.
+type ProtectedMaterialCode struct {
+	Filtered bool                       `json:"filtered"`
+	Detected bool                       `json:"detected"`
+	Citation *ProtectedMaterialCitation `json:"citation,omitempty"`
+}
+
+type ProtectedMaterialCitation struct {
+	License string `json:"license,omitempty"`
+	URL     string `json:"URL,omitempty"`
+}
+
+// CustomBlocklists reports matches against the custom blocklists of the deployment.
+type CustomBlocklists struct {
+	Filtered bool                    `json:"filtered"`
+	Details  []CustomBlocklistResult `json:"details,omitempty"`
+}
+
+type CustomBlocklistResult struct {
+	Filtered bool   `json:"filtered"`
+	ID       string `json:"id"`
+}
+
+// UnmarshalJSON accepts both the object form and the plain list of results
+// returned by older Azure OpenAI API versions.
+func (c *CustomBlocklists) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		c.Filtered = false
+		if err := json.Unmarshal(data, &c.Details); err != nil {
+			return err
+		}
+		for _, detail := range c.Details {
+			c.Filtered = c.Filtered || detail.Filtered
+		}
+		return nil
+	}
+	type customBlocklists CustomBlocklists
+	return json.Unmarshal(data, (*customBlocklists)(c))
+}
+
+type ContentFilterError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type ContentFilterResults struct {
 	Hate      Hate      `json:"hate,omitempty"`
 	SelfHarm  SelfHarm  `json:"self_harm,omitempty"`
@@ -59,6 +116,14 @@ type ContentFilterResults struct {
 	Violence  Violence  `json:"violence,omitempty"`
 	JailBreak JailBreak `json:"jailbreak,omitempty"`
 	Profanity Profanity `json:"profanity,omitempty"`
+	// IndirectAttack is only reported for prompts.
+	IndirectAttack IndirectAttack `json:"indirect_attack,omitempty"`
+	// ProtectedMaterialText and ProtectedMaterialCode are only reported for completions.
+	ProtectedMaterialText ProtectedMaterialText `json:"protected_material_text,omitempty"`
+	ProtectedMaterialCode ProtectedMaterialCode `json:"protected_material_code,omitempty"`
+	CustomBlocklists      CustomBlocklists      `json:"custom_blocklists,omitempty"`
+	// Error is set when the content filter could not run.
+	Error *ContentFilterError `json:"error,omitempty"`
 }
 
 type PromptAnnotation struct {
@@ -117,6 +182,9 @@ type ChatCompletionMessage struct {
 
 	// For Role=tool prompts this should be set to the ID given in the assistant's prior request to call a tool.
 	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Context carries the citations and intent of Azure OpenAI On Your Data responses.
+	Context *ChatMessageContext `json:"context,omitempty"`
 }
 
 func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
@@ -125,29 +193,31 @@ func (m ChatCompletionMessage) MarshalJSON() ([]byte, error) {
 	}
 	if len(m.MultiContent) > 0 {
 		msg := struct {
-			Role             string            `json:"role"`
-			Content          string            `json:"-"`
-			Refusal          string            `json:"refusal,omitempty"`
-			MultiContent     []ChatMessagePart `json:"content,omitempty"`
-			Name             string            `json:"name,omitempty"`
-			ReasoningContent string            `json:"reasoning_content,omitempty"`
-			FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-			ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-			ToolCallID       string            `json:"tool_call_id,omitempty"`
+			Role             string              `json:"role"`
+			Content          string              `json:"-"`
+			Refusal          string              `json:"refusal,omitempty"`
+			MultiContent     []ChatMessagePart   `json:"content,omitempty"`
+			Name             string              `json:"name,omitempty"`
+			ReasoningContent string              `json:"reasoning_content,omitempty"`
+			FunctionCall     *FunctionCall       `json:"function_call,omitempty"`
+			ToolCalls        []ToolCall          `json:"tool_calls,omitempty"`
+			ToolCallID       string              `json:"tool_call_id,omitempty"`
+			Context          *ChatMessageContext `json:"-"`
 		}(m)
 		return json.Marshal(msg)
 	}
 
 	msg := struct {
-		Role             string            `json:"role"`
-		Content          string            `json:"content,omitempty"`
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"-"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		Role             string              `json:"role"`
+		Content          string              `json:"content,omitempty"`
+		Refusal          string              `json:"refusal,omitempty"`
+		MultiContent     []ChatMessagePart   `json:"-"`
+		Name             string              `json:"name,omitempty"`
+		ReasoningContent string              `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall       `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall          `json:"tool_calls,omitempty"`
+		ToolCallID       string              `json:"tool_call_id,omitempty"`
+		Context          *ChatMessageContext `json:"-"`
 	}(m)
 	return json.Marshal(msg)
 }
@@ -158,11 +228,12 @@ func (m *ChatCompletionMessage) UnmarshalJSON(bs []byte) error {
 		Content          string `json:"content"`
 		Refusal          string `json:"refusal,omitempty"`
 		MultiContent     []ChatMessagePart
-		Name             string        `json:"name,omitempty"`
-		ReasoningContent string        `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall    `json:"tool_calls,omitempty"`
-		ToolCallID       string        `json:"tool_call_id,omitempty"`
+		Name             string              `json:"name,omitempty"`
+		ReasoningContent string              `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall       `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall          `json:"tool_calls,omitempty"`
+		ToolCallID       string              `json:"tool_call_id,omitempty"`
+		Context          *ChatMessageContext `json:"context,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(bs, &msg); err == nil {
@@ -172,13 +243,14 @@ func (m *ChatCompletionMessage) UnmarshalJSON(bs []byte) error {
 	multiMsg := struct {
 		Role             string `json:"role"`
 		Content          string
-		Refusal          string            `json:"refusal,omitempty"`
-		MultiContent     []ChatMessagePart `json:"content"`
-		Name             string            `json:"name,omitempty"`
-		ReasoningContent string            `json:"reasoning_content,omitempty"`
-		FunctionCall     *FunctionCall     `json:"function_call,omitempty"`
-		ToolCalls        []ToolCall        `json:"tool_calls,omitempty"`
-		ToolCallID       string            `json:"tool_call_id,omitempty"`
+		Refusal          string              `json:"refusal,omitempty"`
+		MultiContent     []ChatMessagePart   `json:"content"`
+		Name             string              `json:"name,omitempty"`
+		ReasoningContent string              `json:"reasoning_content,omitempty"`
+		FunctionCall     *FunctionCall       `json:"function_call,omitempty"`
+		ToolCalls        []ToolCall          `json:"tool_calls,omitempty"`
+		ToolCallID       string              `json:"tool_call_id,omitempty"`
+		Context          *ChatMessageContext `json:"context,omitempty"`
 	}{}
 	if err := json.Unmarshal(bs, &multiMsg); err != nil {
 		return err
@@ -280,6 +352,9 @@ type ChatCompletionRequest struct {
 	// Such as think mode for qwen3. "chat_template_kwargs": {"enable_thinking": false}
 	// https://qwen.readthedocs.io/en/latest/deployment/vllm.html#thinking-non-thinking-modes
 	ChatTemplateKwargs map[string]any `json:"chat_template_kwargs,omitempty"`
+	// DataSources grounds the completion in your own data on Azure OpenAI.
+	// https://learn.microsoft.com/en-us/azure/ai-services/openai/references/on-your-data
+	DataSources []ChatDataSource `json:"data_sources,omitempty"`
 }
 
 type StreamOptions struct {
@@ -403,12 +478,17 @@ type ChatCompletionResponse struct {
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
 	request ChatCompletionRequest,
+	opts ...RequestOption,
 ) (response ChatCompletionResponse, err error) {
 	if request.Stream {
 		err = ErrChatCompletionStreamNotSupported
 		return
 	}
 
+	if c.config.APIType == APITypeAnthropic {
+		return c.createAnthropicChatCompletion(ctx, request, opts...)
+	}
+
 	urlSuffix := chatCompletionsSuffix
 	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
 		err = ErrChatCompletionInvalidModel
@@ -425,6 +505,7 @@ func (c *Client) CreateChatCompletion(
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
 		withBody(request),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return
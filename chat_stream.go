@@ -17,6 +17,10 @@ type ChatCompletionStreamChoiceDelta struct {
 	// the doc from deepseek:
 	// - https://api-docs.deepseek.com/api/create-chat-completion#responses
 	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	// Context carries the citations and intent of Azure OpenAI On Your Data responses.
+	// It is only set on the first delta.
+	Context *ChatMessageContext `json:"context,omitempty"`
 }
 
 type ChatCompletionStreamChoiceLogprobs struct {
@@ -78,7 +82,12 @@ type ChatCompletionStream struct {
 func (c *Client) CreateChatCompletionStream(
 	ctx context.Context,
 	request ChatCompletionRequest,
+	opts ...RequestOption,
 ) (stream *ChatCompletionStream, err error) {
+	if c.config.APIType == APITypeAnthropic {
+		return c.createAnthropicChatCompletionStream(ctx, request, opts...)
+	}
+
 	urlSuffix := chatCompletionsSuffix
 	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
 		err = ErrChatCompletionInvalidModel
@@ -96,6 +105,7 @@ func (c *Client) CreateChatCompletionStream(
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
 		withBody(request),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return nil, err
@@ -4,15 +4,19 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
+var ErrExtraBodyNotSupported = errors.New("extra body fields can only be merged into JSON request bodies")
+
 // Client is OpenAI GPT-3 API client.
 type Client struct {
 	config ClientConfig
@@ -74,29 +78,119 @@ func NewOrgClient(authToken, org string) *Client {
 type requestOptions struct {
 	body   any
 	header http.Header
+
+	extraHeader http.Header
+	query       url.Values
+	extraBody   map[string]any
+	baseURL     string
+	timeout     time.Duration
+
+	uploadProgress      UploadProgressFunc
+	bypassResponseCache bool
+	streamTimeouts      *StreamTimeouts
 }
 
-type requestOption func(*requestOptions)
+// RequestOption customizes a single API call. Options passed to a Client method
+// are applied after the client defaults, so headers set through WithHeader
+// override the ones derived from ClientConfig.
+type RequestOption func(*requestOptions)
 
-func withBody(body any) requestOption {
+func withBody(body any) RequestOption {
 	return func(args *requestOptions) {
 		args.body = body
 	}
 }
 
-func withContentType(contentType string) requestOption {
+func withContentType(contentType string) RequestOption {
 	return func(args *requestOptions) {
 		args.header.Set("Content-Type", contentType)
 	}
 }
 
-func withBetaAssistantVersion(version string) requestOption {
+func withBetaAssistantVersion(version string) RequestOption {
 	return func(args *requestOptions) {
 		args.header.Set("OpenAI-Beta", fmt.Sprintf("assistants=%s", version))
 	}
 }
 
-func (c *Client) newRequest(ctx context.Context, method, url string, setters ...requestOption) (*http.Request, error) {
+// withOptions applies the options a caller passed to a Client method.
+func withOptions(opts ...RequestOption) RequestOption {
+	return func(args *requestOptions) {
+		for _, opt := range opts {
+			opt(args)
+		}
+	}
+}
+
+// WithHeader sets an additional HTTP header on the request,
+// e.g. OpenAI-Project or the HTTP-Referer expected by OpenRouter.
+func WithHeader(key, value string) RequestOption {
+	return func(args *requestOptions) {
+		if args.extraHeader == nil {
+			args.extraHeader = make(http.Header)
+		}
+		args.extraHeader.Set(key, value)
+	}
+}
+
+// WithQuery adds a query parameter to the request URL.
+func WithQuery(key, value string) RequestOption {
+	return func(args *requestOptions) {
+		if args.query == nil {
+			args.query = make(url.Values)
+		}
+		args.query.Add(key, value)
+	}
+}
+
+// WithExtraBody merges fields into the top level of the JSON request body.
+// It is meant for provider-specific parameters which are not part of the
+// request structs, such as vLLM's guided_json. Fields override the ones
+// with the same name produced by the request struct.
+func WithExtraBody(fields map[string]any) RequestOption {
+	return func(args *requestOptions) {
+		if args.extraBody == nil {
+			args.extraBody = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			args.extraBody[k] = v
+		}
+	}
+}
+
+// WithBaseURL sends the request to baseURL instead of ClientConfig.BaseURL.
+func WithBaseURL(baseURL string) RequestOption {
+	return func(args *requestOptions) {
+		args.baseURL = baseURL
+	}
+}
+
+// WithTimeout bounds the whole call, including reading the response body, by timeout.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(args *requestOptions) {
+		args.timeout = timeout
+	}
+}
+
+type cancelFuncKey struct{}
+
+// cancelOnClose releases the context created by WithTimeout once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+func (c *Client) newRequest(
+	ctx context.Context,
+	method, requestURL string,
+	setters ...RequestOption,
+) (*http.Request, error) {
 	// Default Options
 	args := &requestOptions{
 		body:   nil,
@@ -105,14 +199,136 @@ func (c *Client) newRequest(ctx context.Context, method, url string, setters ...
 	for _, setter := range setters {
 		setter(args)
 	}
-	req, err := c.requestBuilder.Build(ctx, method, url, args.body, args.header)
+
+	if len(args.extraBody) > 0 {
+		body, err := mergeExtraBody(args.body, args.extraBody)
+		if err != nil {
+			return nil, err
+		}
+		args.body = body
+	}
+
+	requestURL, err := c.applyURLOptions(requestURL, args)
+	if err != nil {
+		return nil, err
+	}
+
+	form, _ := args.body.(*multipartForm)
+	var formBody *formPipe
+	if form != nil {
+		if formBody, err = form.open(); err != nil {
+			return nil, err
+		}
+		args.body = formBody
+		args.header.Set("Content-Type", form.contentType)
+	}
+
+	cancel := func() {}
+	if args.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, args.timeout)
+		ctx = context.WithValue(ctx, cancelFuncKey{}, cancel)
+	}
+	if args.bypassResponseCache {
+		ctx = withResponseCacheBypass(ctx)
+	}
+	if args.streamTimeouts != nil {
+		ctx = withStreamTimeouts(ctx, *args.streamTimeouts)
+	}
+
+	req, err := c.requestBuilder.Build(ctx, method, requestURL, args.body, args.header)
+	if err == nil {
+		err = c.setCommonHeaders(req)
+	}
 	if err != nil {
+		cancel()
+		if formBody != nil {
+			formBody.Close()
+		}
 		return nil, err
 	}
-	c.setCommonHeaders(req)
+	if form != nil {
+		form.setRequest(req)
+	}
+	if args.uploadProgress != nil {
+		reportUploadProgress(req, args.uploadProgress)
+	}
+	for key, values := range args.extraHeader {
+		req.Header[key] = values
+	}
 	return req, nil
 }
 
+func (c *Client) applyURLOptions(requestURL string, args *requestOptions) (string, error) {
+	if args.baseURL != "" {
+		baseURL := strings.TrimRight(c.config.BaseURL, "/")
+		if strings.HasPrefix(requestURL, baseURL) {
+			requestURL = strings.TrimRight(args.baseURL, "/") + strings.TrimPrefix(requestURL, baseURL)
+		}
+	}
+	if len(args.query) == 0 {
+		return requestURL, nil
+	}
+
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsedURL.Query()
+	for key, values := range args.query {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	parsedURL.RawQuery = query.Encode()
+	return parsedURL.String(), nil
+}
+
+func mergeExtraBody(body any, extraBody map[string]any) (any, error) {
+	switch body.(type) {
+	case io.Reader, *multipartForm:
+		return nil, ErrExtraBodyNotSupported
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("merging extra body: %w", err)
+		}
+	}
+	for key, value := range extraBody {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	return fields, nil
+}
+
+// do sends req with the configured HTTP client and ties the lifetime of a
+// WithTimeout context to the response body.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.config.HTTPClient.Do(req)
+	recorder, recording := req.Context().Value(responseBodyRecorderKey{}).(*responseBodyRecorder)
+	if recording && err == nil {
+		resp.Body = recorder.record(resp.Body)
+	}
+	cancel, ok := req.Context().Value(cancelFuncKey{}).(context.CancelFunc)
+	if !ok {
+		return resp, err
+	}
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
 func (c *Client) sendRequest(req *http.Request, v Response) error {
 	req.Header.Set("Accept", "application/json")
 
@@ -123,7 +339,7 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	res, err := c.config.HTTPClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -142,7 +358,7 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 }
 
 func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err error) {
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body should be closed by outer function
+	resp, err := c.do(req) //nolint:bodyclose // body should be closed by outer function
 	if err != nil {
 		return
 	}
@@ -163,43 +379,76 @@ func sendRequestStream[T streamable](client *Client, req *http.Request) (*stream
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	resp, err := client.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	var watchdog *streamWatchdog
+	if timeouts := client.streamTimeouts(req.Context()); timeouts.enabled() {
+		var ctx context.Context
+		watchdog, ctx = newStreamWatchdog(req.Context(), timeouts)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := client.do(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
+		if watchdog != nil {
+			watchdog.close()
+			if timeoutErr := watchdog.timeoutErr(); timeoutErr != nil {
+				return new(streamReader[T]), timeoutErr
+			}
+		}
 		return new(streamReader[T]), err
 	}
 	if isFailureStatusCode(resp) {
+		if watchdog != nil {
+			defer watchdog.close()
+		}
 		return new(streamReader[T]), client.handleErrorResp(resp)
 	}
+	if watchdog != nil {
+		watchdog.setBody(resp.Body)
+	}
 	return &streamReader[T]{
 		emptyMessagesLimit: client.config.EmptyMessagesLimit,
 		reader:             bufio.NewReader(resp.Body),
 		response:           resp,
 		errAccumulator:     utils.NewErrorAccumulator(),
 		unmarshaler:        &utils.JSONUnmarshaler{},
+		watchdog:           watchdog,
 		httpHeader:         httpHeader(resp.Header),
 	}, nil
 }
 
-func (c *Client) setCommonHeaders(req *http.Request) {
+func (c *Client) setCommonHeaders(req *http.Request) error {
+	authToken := c.config.authToken
+	if c.config.TokenProvider != nil {
+		token, err := c.config.TokenProvider.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("getting auth token: %w", err)
+		}
+		authToken = token
+	}
+
 	// https://learn.microsoft.com/en-us/azure/cognitive-services/openai/reference#authentication
 	switch c.config.APIType {
 	case APITypeAzure, APITypeCloudflareAzure:
 		// Azure API Key authentication
-		req.Header.Set(AzureAPIKeyHeader, c.config.authToken)
+		req.Header.Set(AzureAPIKeyHeader, authToken)
 	case APITypeAnthropic:
 		// https://docs.anthropic.com/en/api/versioning
 		req.Header.Set("anthropic-version", c.config.APIVersion)
+		if authToken != "" {
+			req.Header.Set(AnthropicAPIKeyHeader, authToken)
+		}
 	case APITypeOpenAI, APITypeAzureAD:
 		fallthrough
 	default:
-		if c.config.authToken != "" {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.authToken))
+		if authToken != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
 		}
 	}
 
 	if c.config.OrgID != "" {
 		req.Header.Set("OpenAI-Organization", c.config.OrgID)
 	}
+	return nil
 }
 
 func isFailureStatusCode(resp *http.Response) bool {
@@ -242,16 +491,6 @@ func withModel(model string) fullURLOption {
 	}
 }
 
-var azureDeploymentsEndpoints = []string{
-	"/completions",
-	"/embeddings",
-	"/chat/completions",
-	"/audio/transcriptions",
-	"/audio/translations",
-	"/audio/speech",
-	"/images/generations",
-}
-
 // fullURL returns full URL for request.
 func (c *Client) fullURL(suffix string, setters ...fullURLOption) string {
 	baseURL := strings.TrimRight(c.config.BaseURL, "/")
@@ -264,32 +503,28 @@ func (c *Client) fullURL(suffix string, setters ...fullURLOption) string {
 		baseURL = c.baseURLWithAzureDeployment(baseURL, suffix, args.model)
 	}
 
-	if c.config.APIVersion != "" {
-		suffix = c.suffixWithAPIVersion(suffix)
+	// Anthropic carries its API version in the anthropic-version header.
+	if c.config.APIType != APITypeAnthropic && (c.config.APIVersion != "" || len(c.config.AzureAPIVersions) > 0) {
+		// An invalid suffix is left as it is, so newRequest fails to parse the URL and returns the error.
+		if versioned, err := c.suffixWithAPIVersion(suffix); err == nil {
+			suffix = versioned
+		}
 	}
 	return fmt.Sprintf("%s%s", baseURL, suffix)
 }
 
-func (c *Client) suffixWithAPIVersion(suffix string) string {
+func (c *Client) suffixWithAPIVersion(suffix string) (string, error) {
 	parsedSuffix, err := url.Parse(suffix)
 	if err != nil {
-		panic("failed to parse url suffix")
+		return "", err
 	}
-	query := parsedSuffix.Query()
-	query.Add("api-version", c.config.APIVersion)
-	return fmt.Sprintf("%s?%s", parsedSuffix.Path, query.Encode())
-}
-
-func (c *Client) baseURLWithAzureDeployment(baseURL, suffix, model string) (newBaseURL string) {
-	baseURL = fmt.Sprintf("%s/%s", strings.TrimRight(baseURL, "/"), azureAPIPrefix)
-	if containsSubstr(azureDeploymentsEndpoints, suffix) {
-		azureDeploymentName := c.config.GetAzureDeploymentByModel(model)
-		if azureDeploymentName == "" {
-			azureDeploymentName = "UNKNOWN"
-		}
-		baseURL = fmt.Sprintf("%s/%s/%s", baseURL, azureDeploymentsPrefix, azureDeploymentName)
+	apiVersion := c.config.apiVersionForPath(parsedSuffix.Path)
+	if apiVersion == "" {
+		return suffix, nil
 	}
-	return baseURL
+	query := parsedSuffix.Query()
+	query.Add("api-version", apiVersion)
+	return fmt.Sprintf("%s?%s", parsedSuffix.EscapedPath(), query.Encode()), nil
 }
 
 func (c *Client) handleErrorResp(resp *http.Response) error {
@@ -316,12 +551,3 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 	errRes.Error.HTTPStatusCode = resp.StatusCode
 	return errRes.Error
 }
-
-func containsSubstr(s []string, e string) bool {
-	for _, v := range s {
-		if strings.Contains(e, v) {
-			return true
-		}
-	}
-	return false
-}
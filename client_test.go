@@ -3,12 +3,16 @@ package openai //nolint:testpackage // testing private field
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
@@ -47,7 +51,9 @@ func TestSetCommonHeadersAnthropic(t *testing.T) {
 		t.Fatalf("Failed to create request: %v", err)
 	}
 
-	client.setCommonHeaders(req)
+	if err = client.setCommonHeaders(req); err != nil {
+		t.Fatalf("Failed to set headers: %v", err)
+	}
 
 	if got := req.Header.Get("anthropic-version"); got != AnthropicAPIVersion {
 		t.Errorf("Expected anthropic-version header to be %q, got %q", AnthropicAPIVersion, got)
@@ -315,7 +321,7 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.RetrieveFineTuningJob(ctx, "")
 		}},
 		{"ListFineTuningJobEvents", func() (any, error) {
-			return client.ListFineTuningJobEvents(ctx, "")
+			return client.ListFineTuningJobEvents(ctx, "", Pagination{})
 		}},
 		{"Moderations", func() (any, error) {
 			return client.Moderations(ctx, ModerationRequest{})
@@ -335,6 +341,18 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 		{"DeleteFile", func() (any, error) {
 			return nil, client.DeleteFile(ctx, "")
 		}},
+		{"CreateUpload", func() (any, error) {
+			return client.CreateUpload(ctx, UploadRequest{})
+		}},
+		{"AddUploadPart", func() (any, error) {
+			return client.AddUploadPart(ctx, "", bytes.NewReader(nil))
+		}},
+		{"CompleteUpload", func() (any, error) {
+			return client.CompleteUpload(ctx, "", CompleteUploadRequest{})
+		}},
+		{"CancelUpload", func() (any, error) {
+			return client.CancelUpload(ctx, "")
+		}},
 		{"GetFile", func() (any, error) {
 			return client.GetFile(ctx, "")
 		}},
@@ -372,13 +390,13 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.DeleteAssistant(ctx, "")
 		}},
 		{"ListAssistants", func() (any, error) {
-			return client.ListAssistants(ctx, nil, nil, nil, nil)
+			return client.ListAssistants(ctx, Pagination{})
 		}},
 		{"CreateAssistantFile", func() (any, error) {
 			return client.CreateAssistantFile(ctx, "", AssistantFileRequest{})
 		}},
 		{"ListAssistantFiles", func() (any, error) {
-			return client.ListAssistantFiles(ctx, "", nil, nil, nil, nil)
+			return client.ListAssistantFiles(ctx, "", Pagination{})
 		}},
 		{"RetrieveAssistantFile", func() (any, error) {
 			return client.RetrieveAssistantFile(ctx, "", "")
@@ -390,7 +408,7 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.CreateMessage(ctx, "", MessageRequest{})
 		}},
 		{"ListMessage", func() (any, error) {
-			return client.ListMessage(ctx, "", nil, nil, nil, nil, nil)
+			return client.ListMessage(ctx, "", ListMessageOptions{})
 		}},
 		{"RetrieveMessage", func() (any, error) {
 			return client.RetrieveMessage(ctx, "", "")
@@ -459,7 +477,7 @@ func TestClientReturnsRequestBuilderErrors(t *testing.T) {
 			return client.RetrieveBatch(ctx, "")
 		}},
 		{"CancelBatch", func() (any, error) { return client.CancelBatch(ctx, "") }},
-		{"ListBatch", func() (any, error) { return client.ListBatch(ctx, nil, nil) }},
+		{"ListBatch", func() (any, error) { return client.ListBatch(ctx, Pagination{}) }},
 	}
 
 	for _, testCase := range testCases {
@@ -493,32 +511,39 @@ func TestClient_suffixWithAPIVersion(t *testing.T) {
 		suffix string
 	}
 	tests := []struct {
-		name      string
-		fields    fields
-		args      args
-		want      string
-		wantPanic string
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr bool
 	}{
 		{
 			"",
 			fields{apiVersion: "2023-05"},
 			args{suffix: "/assistants"},
 			"/assistants?api-version=2023-05",
-			"",
+			false,
 		},
 		{
 			"",
 			fields{apiVersion: "2023-05"},
 			args{suffix: "/assistants?limit=5"},
 			"/assistants?api-version=2023-05&limit=5",
+			false,
+		},
+		{
 			"",
+			fields{apiVersion: "2023-05"},
+			args{suffix: "/models/ft:abc%25zz"},
+			"/models/ft:abc%25zz?api-version=2023-05",
+			false,
 		},
 		{
 			"",
 			fields{apiVersion: "2023-05"},
 			args{suffix: "123:assistants?limit=5"},
-			"/assistants?api-version=2023-05&limit=5",
-			"failed to parse url suffix",
+			"",
+			true,
 		},
 	}
 	for _, tt := range tests {
@@ -526,20 +551,11 @@ func TestClient_suffixWithAPIVersion(t *testing.T) {
 			c := &Client{
 				config: ClientConfig{APIVersion: tt.fields.apiVersion},
 			}
-			defer func() {
-				if r := recover(); r != nil {
-					// Check if the panic message matches the expected panic message
-					if rStr, ok := r.(string); ok {
-						if rStr != tt.wantPanic {
-							t.Errorf("suffixWithAPIVersion() = %v, want %v", rStr, tt.wantPanic)
-						}
-					} else {
-						// If the panic is not a string, log it
-						t.Errorf("suffixWithAPIVersion() panicked with non-string value: %v", r)
-					}
-				}
-			}()
-			if got := c.suffixWithAPIVersion(tt.args.suffix); got != tt.want {
+			got, err := c.suffixWithAPIVersion(tt.args.suffix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("suffixWithAPIVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
 				t.Errorf("suffixWithAPIVersion() = %v, want %v", got, tt.want)
 			}
 		})
@@ -586,3 +602,98 @@ func TestClient_baseURLWithAzureDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestOptions(t *testing.T) {
+	var (
+		gotHeader http.Header
+		gotQuery  url.Values
+		gotBody   map[string]any
+		gotPath   string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		gotQuery = r.URL.Query()
+		gotPath = r.URL.Path
+		gotBody = nil
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		}
+		fmt.Fprint(w, `{"id":"chatcmpl-1"}`)
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.OrgID = "org-default"
+	client := NewClientWithConfig(config)
+	ctx := context.Background()
+
+	_, err := client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model:    GPT4oMini,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "hi"}},
+	},
+		WithHeader("OpenAI-Project", "proj-1"),
+		WithHeader("OpenAI-Organization", "org-override"),
+		WithQuery("trace", "on"),
+		WithExtraBody(map[string]any{"guided_json": map[string]any{"type": "object"}, "model": "override"}),
+	)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	if got := gotHeader.Get("OpenAI-Project"); got != "proj-1" {
+		t.Errorf("OpenAI-Project header = %q, want %q", got, "proj-1")
+	}
+	if got := gotHeader.Get("OpenAI-Organization"); got != "org-override" {
+		t.Errorf("OpenAI-Organization header = %q, want %q", got, "org-override")
+	}
+	if got := gotHeader.Get("Authorization"); got != "Bearer "+test.GetTestToken() {
+		t.Errorf("Authorization header = %q", got)
+	}
+	if got := gotQuery.Get("trace"); got != "on" {
+		t.Errorf("trace query = %q, want %q", got, "on")
+	}
+	if _, ok := gotBody["guided_json"]; !ok {
+		t.Errorf("guided_json was not merged into the body: %v", gotBody)
+	}
+	if gotBody["model"] != "override" {
+		t.Errorf("model = %v, want extra body to override it", gotBody["model"])
+	}
+	if _, ok := gotBody["messages"]; !ok {
+		t.Errorf("messages missing from merged body: %v", gotBody)
+	}
+
+	_, err = client.ListModels(ctx, WithBaseURL(ts.URL+"/other/"), WithExtraBody(map[string]any{"a": 1}))
+	checks.NoError(t, err, "ListModels error")
+	if gotPath != "/other/models" {
+		t.Errorf("path = %q, want %q", gotPath, "/other/models")
+	}
+	if gotBody["a"] != float64(1) {
+		t.Errorf("extra body without request body = %v", gotBody)
+	}
+
+	_, err = client.CreateFileBytes(ctx, FileBytesRequest{Name: "a.jsonl", Bytes: []byte("{}")},
+		WithExtraBody(map[string]any{"a": 1}))
+	if !errors.Is(err, ErrExtraBodyNotSupported) {
+		t.Errorf("expected ErrExtraBodyNotSupported for multipart body, got %v", err)
+	}
+}
+
+func TestRequestOptionWithTimeout(t *testing.T) {
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	client := NewClientWithConfig(config)
+
+	_, err := client.ListModels(context.Background(), WithTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
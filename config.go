@@ -43,9 +43,18 @@ type ClientConfig struct {
 	APIVersion           string // required when APIType is APITypeAzure or APITypeAzureAD or APITypeAnthropic
 	AssistantVersion     string
 	AzureModelMapperFunc func(model string) string // replace model to azure deployment name func
-	HTTPClient           HTTPDoer
+	// AzureAPIVersions overrides APIVersion for endpoints whose path starts with
+	// the key, e.g. {"/assistants": "2024-05-01-preview"}. The longest match wins.
+	AzureAPIVersions map[string]string
+	HTTPClient       HTTPDoer
+	// TokenProvider, when set, is consulted for the credential of every request
+	// instead of the token the config was created with.
+	TokenProvider TokenProvider
 
 	EmptyMessagesLimit uint
+	// StreamTimeouts bound the streams of the client, unless a call sets its
+	// own with WithStreamTimeouts. The zero value doesn't bound them.
+	StreamTimeouts StreamTimeouts
 }
 
 func DefaultConfig(authToken string) ClientConfig {
@@ -79,6 +88,15 @@ func DefaultAzureConfig(apiKey, baseURL string) ClientConfig {
 	}
 }
 
+// DefaultAzureADConfig returns a config for Azure OpenAI authenticated with
+// Microsoft Entra ID tokens obtained from tokenProvider.
+func DefaultAzureADConfig(tokenProvider TokenProvider, baseURL string) ClientConfig {
+	config := DefaultAzureConfig("", baseURL)
+	config.APIType = APITypeAzureAD
+	config.TokenProvider = tokenProvider
+	return config
+}
+
 func DefaultAnthropicConfig(apiKey, baseURL string) ClientConfig {
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com/v1"
@@ -3,9 +3,7 @@ package openai
 import (
 	"context"
 	"encoding/base64"
-	"encoding/binary"
 	"errors"
-	"math"
 	"net/http"
 )
 
@@ -86,13 +84,7 @@ func (b base64String) Decode() ([]float32, error) {
 		return nil, err
 	}
 
-	const sizeOfFloat32 = 4
-	floats := make([]float32, len(decodedData)/sizeOfFloat32)
-	for i := 0; i < len(floats); i++ {
-		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(decodedData[i*4 : (i+1)*4]))
-	}
-
-	return floats, nil
+	return float32sFromBytes(decodedData), nil
 }
 
 // Base64Embedding is a container for base64 encoded embeddings.
@@ -239,6 +231,7 @@ func (r EmbeddingRequestTokens) Convert() EmbeddingRequest {
 func (c *Client) CreateEmbeddings(
 	ctx context.Context,
 	conv EmbeddingRequestConverter,
+	opts ...RequestOption,
 ) (res EmbeddingResponse, err error) {
 	baseReq := conv.Convert()
 	req, err := c.newRequest(
@@ -246,6 +239,7 @@ func (c *Client) CreateEmbeddings(
 		http.MethodPost,
 		c.fullURL("/embeddings", withModel(string(baseReq.Model))),
 		withBody(baseReq),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return
@@ -0,0 +1,192 @@
+package index
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// hnsw is a Hierarchical Navigable Small World graph over the vectors of an
+// Index (Malkov and Yashunin, 2016). Nodes are the indexes of documents.
+// Its exported fields are persisted with the index.
+type hnsw struct {
+	M              int
+	EfConstruction int
+	LevelMult      float64
+	Seed           int64
+	// Entry is the node searches start from, on layer MaxLevel. It is -1
+	// while the graph is empty.
+	Entry    int32
+	MaxLevel int
+	// Neighbors holds the neighbors of every node on every layer it is on.
+	Neighbors [][][]int32
+
+	rng *rand.Rand
+}
+
+func newHNSW(options Options) *hnsw {
+	return &hnsw{
+		M:              options.M,
+		EfConstruction: options.EfConstruction,
+		LevelMult:      1 / math.Log(float64(options.M)),
+		Seed:           options.Seed,
+		Entry:          -1,
+	}
+}
+
+func (g *hnsw) len() int {
+	return len(g.Neighbors)
+}
+
+// maxNeighbors is the number of neighbors a node keeps on level.
+func (g *hnsw) maxNeighbors(level int) int {
+	if level == 0 {
+		return 2 * g.M
+	}
+	return g.M
+}
+
+func (g *hnsw) randomLevel() int {
+	if g.rng == nil {
+		g.rng = newRand(g.Seed, g.len())
+	}
+	return int(math.Floor(-math.Log(1-g.rng.Float64()) * g.LevelMult))
+}
+
+type candidate struct {
+	node     int32
+	distance float32
+}
+
+func distance(ix *Index, query []float32, node int32) float32 {
+	return 1 - dot(query, ix.vector(int(node)))
+}
+
+// candidateHeap is a min-heap of candidates by distance, or a max-heap with farthest set.
+type candidateHeap struct {
+	items    []candidate
+	farthest bool
+}
+
+func (h *candidateHeap) Len() int { return len(h.items) }
+func (h *candidateHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.items[i].distance > h.items[j].distance
+	}
+	return h.items[i].distance < h.items[j].distance
+}
+func (h *candidateHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candidateHeap) Push(x any)    { h.items = append(h.items, x.(candidate)) }
+func (h *candidateHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// searchLayer returns the ef nodes of level closest to query found from
+// entries, closest first.
+func (g *hnsw) searchLayer(ix *Index, query []float32, entries []candidate, ef, level int) []candidate {
+	visited := make(map[int32]bool, ef)
+	candidates := &candidateHeap{}
+	results := &candidateHeap{farthest: true}
+	for _, entry := range entries {
+		visited[entry.node] = true
+		heap.Push(candidates, entry)
+		heap.Push(results, entry)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && c.distance > results.items[0].distance {
+			break
+		}
+		for _, n := range g.Neighbors[c.node][level] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			d := distance(ix, query, n)
+			if results.Len() < ef || d < results.items[0].distance {
+				heap.Push(candidates, candidate{node: n, distance: d})
+				heap.Push(results, candidate{node: n, distance: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sort.Slice(results.items, func(i, j int) bool { return results.items[i].distance < results.items[j].distance })
+	return results.items
+}
+
+// descend walks greedily from the entry point down to level.
+func (g *hnsw) descend(ix *Index, query []float32, level int) []candidate {
+	entry := []candidate{{node: g.Entry, distance: distance(ix, query, g.Entry)}}
+	for l := g.MaxLevel; l > level; l-- {
+		entry = g.searchLayer(ix, query, entry, 1, l)[:1]
+	}
+	return entry
+}
+
+// search returns the ef nodes closest to query found on the bottom layer, closest first.
+func (g *hnsw) search(ix *Index, query []float32, ef int) []candidate {
+	if g.Entry < 0 {
+		return nil
+	}
+	return g.searchLayer(ix, query, g.descend(ix, query, 0), ef, 0)
+}
+
+// insert adds node, which must be the next document of ix, to the graph.
+func (g *hnsw) insert(ix *Index, node int32) {
+	query := ix.vector(int(node))
+	level := g.randomLevel()
+	g.Neighbors = append(g.Neighbors, make([][]int32, level+1))
+	if g.Entry < 0 {
+		g.Entry, g.MaxLevel = node, level
+		return
+	}
+
+	top := level
+	if top > g.MaxLevel {
+		top = g.MaxLevel
+	}
+	entry := g.descend(ix, query, top)
+	for l := top; l >= 0; l-- {
+		found := g.searchLayer(ix, query, entry, g.EfConstruction, l)
+		selected := found
+		if limit := g.maxNeighbors(l); len(selected) > limit {
+			selected = selected[:limit]
+		}
+		neighbors := make([]int32, len(selected))
+		for i, c := range selected {
+			neighbors[i] = c.node
+			g.link(ix, c.node, node, l)
+		}
+		g.Neighbors[node][l] = neighbors
+		entry = found
+	}
+	if level > g.MaxLevel {
+		g.Entry, g.MaxLevel = node, level
+	}
+}
+
+// link adds to to the neighbors of from on level, dropping the farthest
+// neighbor when from has too many.
+func (g *hnsw) link(ix *Index, from, to int32, level int) {
+	neighbors := append(g.Neighbors[from][level], to)
+	if limit := g.maxNeighbors(level); len(neighbors) > limit {
+		vector := ix.vector(int(from))
+		candidates := make([]candidate, len(neighbors))
+		for i, n := range neighbors {
+			candidates[i] = candidate{node: n, distance: distance(ix, vector, n)}
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+		neighbors = neighbors[:limit]
+		for i := range neighbors {
+			neighbors[i] = candidates[i].node
+		}
+	}
+	g.Neighbors[from][level] = neighbors
+}
@@ -0,0 +1,344 @@
+// Package index provides an in-memory vector index over embeddings, for
+// corpora small enough not to need a vector database. It searches by cosine
+// similarity, either exactly or approximately with an HNSW graph, and can
+// be saved to and loaded from disk.
+package index
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	defaultM              = 16
+	defaultEfConstruction = 200
+	defaultEfSearch       = 64
+	// minM is the smallest M with a finite level multiplier, 1/ln(M).
+	minM = 2
+	// unroll is the number of independent sums dot keeps, so the compiler
+	// can pipeline the multiplications.
+	unroll = 4
+)
+
+var (
+	ErrDuplicateID = errors.New("document ID is already indexed")
+	ErrEmptyVector = errors.New("document vector is empty")
+	ErrInvalidK    = errors.New("k must be positive")
+)
+
+// Document is a vector stored in the index, with the text it embeds and
+// metadata to filter searches by.
+type Document struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+	Vector   []float32
+}
+
+// Result is a document found by a search, with its cosine similarity to the query.
+type Result struct {
+	Document
+	Score float32
+}
+
+// Filter selects the documents a search may return by their metadata.
+type Filter func(metadata map[string]string) bool
+
+// MatchMetadata returns a filter selecting documents whose metadata holds all pairs.
+func MatchMetadata(pairs map[string]string) Filter {
+	return func(metadata map[string]string) bool {
+		for key, value := range pairs {
+			if v, ok := metadata[key]; !ok || v != value {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Options configures the HNSW graph of an Index. Zero values use the defaults.
+type Options struct {
+	// M is the number of neighbors of a node on the upper layers of the
+	// graph, twice as many on the bottom layer. Defaults to 16; values
+	// below 2 are raised to 2.
+	M int
+	// EfConstruction is the number of candidates considered when inserting
+	// a node. Defaults to 200.
+	EfConstruction int
+	// EfSearch is the number of candidates considered by SearchApprox, at
+	// least k. Higher values trade speed for recall. Defaults to 64.
+	EfSearch int
+	// Seed makes the graph layout deterministic.
+	Seed int64
+}
+
+func (o Options) withDefaults() Options {
+	switch {
+	case o.M <= 0:
+		o.M = defaultM
+	case o.M < minM:
+		o.M = minM
+	}
+	if o.EfConstruction <= 0 {
+		o.EfConstruction = defaultEfConstruction
+	}
+	if o.EfSearch <= 0 {
+		o.EfSearch = defaultEfSearch
+	}
+	return o
+}
+
+// Index is an in-memory vector index. It is safe for concurrent use.
+//
+// Vectors are normalized when added, so scores are cosine similarities.
+// They are stored in one contiguous slice, which keeps exact search fast.
+type Index struct {
+	mu         sync.RWMutex
+	options    Options
+	dimensions int
+	// docs holds the documents without their vectors, which are in vectors.
+	docs []Document
+	byID map[string]int
+	// vectors holds the normalized vectors of docs, dimensions values each.
+	vectors []float32
+	// graph is the HNSW graph, built up to date by SearchApprox.
+	graph *hnsw
+}
+
+// New returns an empty index.
+func New(options Options) *Index {
+	options = options.withDefaults()
+	return &Index{
+		options: options,
+		byID:    map[string]int{},
+		graph:   newHNSW(options),
+	}
+}
+
+// Len returns the number of documents in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Dimensions returns the length of the vectors in the index, 0 while it is empty.
+func (ix *Index) Dimensions() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.dimensions
+}
+
+// Add adds documents to the index. All vectors must have the same length
+// and IDs must be unique. No document is added if one is invalid.
+func (ix *Index) Add(docs ...Document) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	dimensions := ix.dimensions
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		if len(doc.Vector) == 0 {
+			return fmt.Errorf("%w: %q", ErrEmptyVector, doc.ID)
+		}
+		if dimensions == 0 {
+			dimensions = len(doc.Vector)
+		}
+		if len(doc.Vector) != dimensions {
+			return fmt.Errorf("%w: %q has %d dimensions, not %d",
+				openai.ErrVectorLengthMismatch, doc.ID, len(doc.Vector), dimensions)
+		}
+		if _, ok := ix.byID[doc.ID]; ok || seen[doc.ID] {
+			return fmt.Errorf("%w: %q", ErrDuplicateID, doc.ID)
+		}
+		seen[doc.ID] = true
+	}
+
+	ix.dimensions = dimensions
+	for _, doc := range docs {
+		ix.vectors = append(ix.vectors, openai.Normalize(doc.Vector)...)
+		doc.Vector = nil
+		ix.byID[doc.ID] = len(ix.docs)
+		ix.docs = append(ix.docs, doc)
+	}
+	return nil
+}
+
+// Get returns the document with id. Its vector is normalized.
+func (ix *Index) Get(id string) (Document, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return Document{}, false
+	}
+	return ix.document(i), true
+}
+
+func (ix *Index) vector(i int) []float32 {
+	return ix.vectors[i*ix.dimensions : (i+1)*ix.dimensions : (i+1)*ix.dimensions]
+}
+
+func (ix *Index) document(i int) Document {
+	doc := ix.docs[i]
+	doc.Vector = ix.vector(i)
+	return doc
+}
+
+func (ix *Index) prepareQuery(query []float32, k int) ([]float32, error) {
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+	if ix.dimensions != 0 && len(query) != ix.dimensions {
+		return nil, fmt.Errorf("%w: query has %d dimensions, not %d",
+			openai.ErrVectorLengthMismatch, len(query), ix.dimensions)
+	}
+	return openai.Normalize(query), nil
+}
+
+// Search returns the k documents most similar to query, most similar
+// first, comparing it to every document. filter may be nil.
+func (ix *Index) Search(query []float32, k int, filter Filter) ([]Result, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	query, err := ix.prepareQuery(query, k)
+	if err != nil {
+		return nil, err
+	}
+	return ix.searchExact(query, k, filter), nil
+}
+
+func (ix *Index) searchExact(query []float32, k int, filter Filter) []Result {
+	top := &topK{k: k}
+	for i := range ix.docs {
+		if filter != nil && !filter(ix.docs[i].Metadata) {
+			continue
+		}
+		top.offer(int32(i), dot(query, ix.vector(i)))
+	}
+	return ix.results(top)
+}
+
+// results returns the documents in top, most similar first.
+func (ix *Index) results(top *topK) []Result {
+	sort.Sort(sort.Reverse(top))
+	results := make([]Result, len(top.items))
+	for i, item := range top.items {
+		results[i] = Result{Document: ix.document(int(item.node)), Score: item.score}
+	}
+	return results
+}
+
+// dot returns the dot product of a and b, which have the same length.
+func dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+unroll <= len(a); i += unroll {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+type scored struct {
+	node  int32
+	score float32
+}
+
+// topK keeps the k highest scores offered, as a min-heap.
+type topK struct {
+	k     int
+	items []scored
+}
+
+func (t *topK) Len() int           { return len(t.items) }
+func (t *topK) Less(i, j int) bool { return t.items[i].score < t.items[j].score }
+func (t *topK) Swap(i, j int)      { t.items[i], t.items[j] = t.items[j], t.items[i] }
+func (t *topK) Push(x any)         { t.items = append(t.items, x.(scored)) }
+func (t *topK) Pop() any {
+	last := t.items[len(t.items)-1]
+	t.items = t.items[:len(t.items)-1]
+	return last
+}
+
+func (t *topK) offer(node int32, score float32) {
+	if len(t.items) < t.k {
+		heap.Push(t, scored{node: node, score: score})
+		return
+	}
+	if score > t.items[0].score {
+		t.items[0] = scored{node: node, score: score}
+		heap.Fix(t, 0)
+	}
+}
+
+// SearchApprox returns about the k documents most similar to query, most
+// similar first, searching the HNSW graph. It is much faster than Search on
+// large indexes, at the cost of occasionally missing a document. Documents
+// added since the last call are inserted into the graph first. When filter
+// rejects too many candidates, the search widens until it falls back to an
+// exact search.
+func (ix *Index) SearchApprox(query []float32, k int, filter Filter) ([]Result, error) {
+	ix.mu.RLock()
+	stale := ix.graph.len() < len(ix.docs)
+	ix.mu.RUnlock()
+	if stale {
+		ix.buildGraph()
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	query, err := ix.prepareQuery(query, k)
+	if err != nil {
+		return nil, err
+	}
+	n := ix.graph.len()
+	if n == 0 {
+		return nil, nil
+	}
+	ef := ix.options.EfSearch
+	if ef < k {
+		ef = k
+	}
+	for {
+		top := &topK{k: k}
+		for _, c := range ix.graph.search(ix, query, ef) {
+			if filter == nil || filter(ix.docs[c.node].Metadata) {
+				top.offer(c.node, 1-c.distance)
+			}
+		}
+		if top.Len() >= k {
+			return ix.results(top), nil
+		}
+		if ef >= n {
+			return ix.searchExact(query, k, filter), nil
+		}
+		ef *= 2
+	}
+}
+
+// buildGraph inserts the documents missing from the graph.
+func (ix *Index) buildGraph() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for i := ix.graph.len(); i < len(ix.docs); i++ {
+		ix.graph.insert(ix, int32(i))
+	}
+}
+
+func newRand(seed int64, n int) *rand.Rand {
+	//nolint:gosec // the level of a node needs no secure randomness
+	return rand.New(rand.NewSource(seed + int64(n)))
+}
@@ -0,0 +1,296 @@
+package index_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/embeddings/index"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func fruitIndex(t *testing.T) *index.Index {
+	t.Helper()
+	ix := index.New(index.Options{})
+	checks.NoError(t, ix.Add(
+		index.Document{ID: "apple", Vector: []float32{1, 0, 0}, Metadata: map[string]string{"color": "red"}},
+		index.Document{ID: "cherry", Vector: []float32{3, 1, 0}, Metadata: map[string]string{"color": "red"}},
+		index.Document{ID: "lime", Vector: []float32{0, 2, 0}, Metadata: map[string]string{"color": "green"}},
+		index.Document{ID: "plum", Vector: []float32{0, 0, 5}},
+	), "Add error")
+	return ix
+}
+
+func resultIDs(results []index.Result) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	ix := fruitIndex(t)
+
+	results, err := ix.Search([]float32{2, 0.1, 0}, 3, nil)
+	checks.NoError(t, err, "Search error")
+	if fmt.Sprint(resultIDs(results)) != "[apple cherry lime]" {
+		t.Fatalf("unexpected results %v", resultIDs(results))
+	}
+	if results[0].Score < 0.99 || results[0].Score > 1.0001 {
+		t.Fatalf("expected a cosine similarity near 1, got %v", results[0].Score)
+	}
+
+	results, err = ix.Search([]float32{0, 1, 0}, 10, index.MatchMetadata(map[string]string{"color": "red"}))
+	checks.NoError(t, err, "Search error")
+	if fmt.Sprint(resultIDs(results)) != "[cherry apple]" {
+		t.Fatalf("unexpected filtered results %v", resultIDs(results))
+	}
+
+	if _, err = ix.Search([]float32{1, 0}, 1, nil); !errors.Is(err, openai.ErrVectorLengthMismatch) {
+		t.Fatalf("expected ErrVectorLengthMismatch, got %v", err)
+	}
+	if _, err = ix.Search([]float32{1, 0, 0}, 0, nil); !errors.Is(err, index.ErrInvalidK) {
+		t.Fatalf("expected ErrInvalidK, got %v", err)
+	}
+
+	doc, ok := ix.Get("plum")
+	if !ok || !reflect.DeepEqual(doc.Vector, []float32{0, 0, 1}) {
+		t.Fatalf("expected the normalized document, got %+v", doc)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	ix := fruitIndex(t)
+	err := ix.Add(
+		index.Document{ID: "kiwi", Vector: []float32{1, 1, 1}},
+		index.Document{ID: "apple", Vector: []float32{1, 1, 1}},
+	)
+	if !errors.Is(err, index.ErrDuplicateID) {
+		t.Fatalf("expected ErrDuplicateID, got %v", err)
+	}
+	if err = ix.Add(index.Document{ID: "kiwi", Vector: []float32{1}}); !errors.Is(err, openai.ErrVectorLengthMismatch) {
+		t.Fatalf("expected ErrVectorLengthMismatch, got %v", err)
+	}
+	if err = ix.Add(index.Document{ID: "kiwi"}); !errors.Is(err, index.ErrEmptyVector) {
+		t.Fatalf("expected ErrEmptyVector, got %v", err)
+	}
+	if ix.Len() != 4 {
+		t.Fatalf("expected failed adds to add nothing, got %d documents", ix.Len())
+	}
+}
+
+func randomDocuments(rng *rand.Rand, n, dimensions int) []index.Document {
+	docs := make([]index.Document, n)
+	for i := range docs {
+		vector := make([]float32, dimensions)
+		for j := range vector {
+			vector[j] = float32(rng.NormFloat64())
+		}
+		parity := "even"
+		if i%2 == 1 {
+			parity = "odd"
+		}
+		docs[i] = index.Document{ID: fmt.Sprint(i), Vector: vector, Metadata: map[string]string{"parity": parity}}
+	}
+	return docs
+}
+
+func TestSearchApproxRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ix := index.New(index.Options{Seed: 1})
+	checks.NoError(t, ix.Add(randomDocuments(rng, 2000, 32)...), "Add error")
+
+	const k, queries = 10, 50
+	found := 0
+	for q := 0; q < queries; q++ {
+		query := randomDocuments(rng, 1, 32)[0].Vector
+		exact, err := ix.Search(query, k, nil)
+		checks.NoError(t, err, "Search error")
+		approx, err := ix.SearchApprox(query, k, nil)
+		checks.NoError(t, err, "SearchApprox error")
+		if len(approx) != k {
+			t.Fatalf("expected %d results, got %d", k, len(approx))
+		}
+		want := map[string]bool{}
+		for _, r := range exact {
+			want[r.ID] = true
+		}
+		for _, r := range approx {
+			if want[r.ID] {
+				found++
+			}
+		}
+	}
+	if recall := float64(found) / (k * queries); recall < 0.9 {
+		t.Fatalf("expected a recall of at least 0.9, got %v", recall)
+	}
+}
+
+func TestSearchApproxSmallM(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for _, m := range []int{1, 2} {
+		ix := index.New(index.Options{M: m, Seed: 5})
+		docs := randomDocuments(rng, 50, 8)
+		checks.NoError(t, ix.Add(docs...), "Add error")
+		results, err := ix.SearchApprox(docs[7].Vector, 1, nil)
+		checks.NoError(t, err, "SearchApprox error")
+		if len(results) != 1 || results[0].ID != "7" {
+			t.Fatalf("expected document 7 with M %d, got %v", m, results)
+		}
+	}
+}
+
+func TestSearchApproxFilter(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	ix := index.New(index.Options{EfSearch: 8})
+	checks.NoError(t, ix.Add(randomDocuments(rng, 300, 16)...), "Add error")
+	checks.NoError(t, ix.Add(index.Document{
+		ID:       "needle",
+		Vector:   randomDocuments(rng, 1, 16)[0].Vector,
+		Metadata: map[string]string{"kind": "needle"},
+	}), "Add error")
+
+	// A filter matching a single document still finds it.
+	results, err := ix.SearchApprox(make([]float32, 16), 1, index.MatchMetadata(map[string]string{"kind": "needle"}))
+	checks.NoError(t, err, "SearchApprox error")
+	if fmt.Sprint(resultIDs(results)) != "[needle]" {
+		t.Fatalf("unexpected results %v", resultIDs(results))
+	}
+
+	results, err = ix.SearchApprox(randomDocuments(rng, 1, 16)[0].Vector, 5, index.MatchMetadata(map[string]string{
+		"parity": "odd",
+	}))
+	checks.NoError(t, err, "SearchApprox error")
+	for _, r := range results {
+		if r.Metadata["parity"] != "odd" {
+			t.Fatalf("filter not applied to %+v", r)
+		}
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	ix := index.New(index.Options{Seed: 3})
+	checks.NoError(t, ix.Add(randomDocuments(rng, 200, 8)...), "Add error")
+	query := randomDocuments(rng, 1, 8)[0].Vector
+	want, err := ix.SearchApprox(query, 5, nil)
+	checks.NoError(t, err, "SearchApprox error")
+
+	path := filepath.Join(t.TempDir(), "index.gob")
+	checks.NoError(t, ix.SaveFile(path), "SaveFile error")
+	loaded, err := index.LoadFile(path)
+	checks.NoError(t, err, "LoadFile error")
+
+	got, err := loaded.SearchApprox(query, 5, nil)
+	checks.NoError(t, err, "SearchApprox error")
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the loaded index to search like the saved one, got %v, want %v", got, want)
+	}
+
+	checks.NoError(t, loaded.Add(index.Document{ID: "new", Vector: query}), "Add error")
+	got, err = loaded.SearchApprox(query, 1, nil)
+	checks.NoError(t, err, "SearchApprox error")
+	if got[0].ID != "new" {
+		t.Fatalf("expected the added document, got %v", got[0].ID)
+	}
+}
+
+// indexFile mirrors the gob encoding of a saved index, so tests can corrupt it.
+type indexFile struct {
+	Version    int
+	Options    index.Options
+	Dimensions int
+	Docs       []struct {
+		ID       string
+		Text     string
+		Metadata map[string]string
+	}
+	Vectors []float32
+	Graph   *struct {
+		M              int
+		EfConstruction int
+		LevelMult      float64
+		Seed           int64
+		Entry          int32
+		MaxLevel       int
+		Neighbors      [][][]int32
+	}
+}
+
+func TestLoadRejectsCorruptedFile(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	ix := index.New(index.Options{Seed: 4})
+	checks.NoError(t, ix.Add(randomDocuments(rng, 20, 4)...), "Add error")
+	_, err := ix.SearchApprox(randomDocuments(rng, 1, 4)[0].Vector, 1, nil)
+	checks.NoError(t, err, "SearchApprox error")
+	var saved bytes.Buffer
+	checks.NoError(t, ix.Save(&saved), "Save error")
+
+	corruptions := map[string]func(f *indexFile){
+		"neighbor":   func(f *indexFile) { f.Graph.Neighbors[3][0] = append(f.Graph.Neighbors[3][0], 20) },
+		"dimensions": func(f *indexFile) { f.Dimensions = 5 },
+		"entry":      func(f *indexFile) { f.Graph.Entry = -1 },
+	}
+	for name, corrupt := range corruptions {
+		var f indexFile
+		checks.NoError(t, gob.NewDecoder(bytes.NewReader(saved.Bytes())).Decode(&f), "Decode error")
+		corrupt(&f)
+		var corrupted bytes.Buffer
+		checks.NoError(t, gob.NewEncoder(&corrupted).Encode(f), "Encode error")
+		if _, err = index.Load(&corrupted); !errors.Is(err, index.ErrInvalidIndexFile) {
+			t.Fatalf("expected ErrInvalidIndexFile for a corrupted %s, got %v", name, err)
+		}
+	}
+}
+
+// hashEmbedder embeds a text as a vector derived from its bytes.
+type hashEmbedder struct {
+	requests int
+}
+
+func (e *hashEmbedder) CreateEmbeddings(
+	_ context.Context,
+	conv openai.EmbeddingRequestConverter,
+	_ ...openai.RequestOption,
+) (openai.EmbeddingResponse, error) {
+	e.requests++
+	input, _ := conv.Convert().Input.([]string)
+	response := openai.EmbeddingResponse{}
+	for i, text := range input {
+		vector := make([]float32, 4)
+		for j, b := range []byte(text) {
+			vector[j%4] += float32(b)
+		}
+		// Answer out of order, like the API may.
+		response.Data = append([]openai.Embedding{{Index: i, Embedding: vector}}, response.Data...)
+	}
+	return response, nil
+}
+
+func TestIndexTexts(t *testing.T) {
+	docs := make([]index.TextDocument, 2100)
+	for i := range docs {
+		docs[i] = index.TextDocument{ID: fmt.Sprint(i), Text: fmt.Sprintf("document %d", i)}
+	}
+	docs[7].Text = "zzzz"
+
+	embedder := &hashEmbedder{}
+	ix, err := index.IndexTexts(context.Background(), embedder, openai.SmallEmbedding3, docs)
+	checks.NoError(t, err, "IndexTexts error")
+	if embedder.requests != 2 || ix.Len() != len(docs) {
+		t.Fatalf("expected 2 requests indexing %d documents, got %d and %d", len(docs), embedder.requests, ix.Len())
+	}
+	results, err := ix.Search([]float32{1, 1, 1, 1}, 1, nil)
+	checks.NoError(t, err, "Search error")
+	if results[0].ID != "7" || results[0].Text != "zzzz" {
+		t.Fatalf("expected the document with equal byte sums, got %+v", results[0].Document)
+	}
+}
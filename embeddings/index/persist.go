@@ -0,0 +1,162 @@
+package index
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	snapshotVersion = 1
+	indexFileMode   = 0o600
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported index file version")
+	ErrInvalidIndexFile   = errors.New("inconsistent index file")
+)
+
+// snapshot is the persisted form of an Index.
+type snapshot struct {
+	Version    int
+	Options    Options
+	Dimensions int
+	Docs       []snapshotDocument
+	Vectors    []float32
+	Graph      *hnsw
+}
+
+// snapshotDocument is a Document without its vector, which is in snapshot.Vectors.
+type snapshotDocument struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// Save writes the index, including its HNSW graph, to w.
+func (ix *Index) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	s := snapshot{
+		Version:    snapshotVersion,
+		Options:    ix.options,
+		Dimensions: ix.dimensions,
+		Docs:       make([]snapshotDocument, len(ix.docs)),
+		Vectors:    ix.vectors,
+		Graph:      ix.graph,
+	}
+	for i, doc := range ix.docs {
+		s.Docs[i] = snapshotDocument{ID: doc.ID, Text: doc.Text, Metadata: doc.Metadata}
+	}
+	buffered := bufio.NewWriter(w)
+	if err := gob.NewEncoder(buffered).Encode(s); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// Load reads an index written by Save.
+func Load(r io.Reader) (*Index, error) {
+	var s snapshot
+	if err := gob.NewDecoder(bufio.NewReader(r)).Decode(&s); err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, s.Version)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIndexFile, err)
+	}
+
+	ix := New(s.Options)
+	ix.dimensions = s.Dimensions
+	ix.vectors = s.Vectors
+	ix.graph = s.Graph
+	ix.docs = make([]Document, len(s.Docs))
+	for i, doc := range s.Docs {
+		ix.docs[i] = Document{ID: doc.ID, Text: doc.Text, Metadata: doc.Metadata}
+		ix.byID[doc.ID] = i
+	}
+	return ix, nil
+}
+
+// validate checks that the documents, vectors and graph of s agree, so a
+// corrupted file is rejected by Load instead of making searches panic.
+func (s *snapshot) validate() error {
+	switch {
+	case s.Dimensions < 0 || (s.Dimensions == 0 && len(s.Docs) > 0):
+		return fmt.Errorf("%d dimensions", s.Dimensions)
+	case len(s.Vectors) != len(s.Docs)*s.Dimensions:
+		return fmt.Errorf("%d vector values for %d documents of %d dimensions", len(s.Vectors), len(s.Docs), s.Dimensions)
+	case s.Graph == nil:
+		return errors.New("missing graph")
+	case s.Graph.len() > len(s.Docs):
+		return fmt.Errorf("graph of %d nodes for %d documents", s.Graph.len(), len(s.Docs))
+	case s.Graph.M < minM || math.IsInf(s.Graph.LevelMult, 0) || math.IsNaN(s.Graph.LevelMult):
+		return fmt.Errorf("graph with M %d and level multiplier %v", s.Graph.M, s.Graph.LevelMult)
+	}
+	ids := make(map[string]bool, len(s.Docs))
+	for _, doc := range s.Docs {
+		if ids[doc.ID] {
+			return fmt.Errorf("duplicate document %q", doc.ID)
+		}
+		ids[doc.ID] = true
+	}
+
+	g := s.Graph
+	nodes := int32(g.len())
+	if nodes == 0 {
+		if g.Entry != -1 {
+			return fmt.Errorf("entry point %d in an empty graph", g.Entry)
+		}
+		return nil
+	}
+	if g.Entry < 0 || g.Entry >= nodes || len(g.Neighbors[g.Entry]) != g.MaxLevel+1 {
+		return fmt.Errorf("entry point %d is not a node on level %d", g.Entry, g.MaxLevel)
+	}
+	for node, levels := range g.Neighbors {
+		if len(levels) == 0 || len(levels) > g.MaxLevel+1 {
+			return fmt.Errorf("node %d is on %d levels", node, len(levels))
+		}
+		for level, neighbors := range levels {
+			for _, neighbor := range neighbors {
+				if neighbor < 0 || neighbor >= nodes || len(g.Neighbors[neighbor]) <= level {
+					return fmt.Errorf("node %d has neighbor %d, which is not a node on level %d", node, neighbor, level)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SaveFile writes the index to path through a temporary file, so an
+// interrupted save never leaves a truncated index.
+func (ix *Index) SaveFile(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, indexFileMode)
+	if err != nil {
+		return err
+	}
+	if err = ix.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadFile reads an index written by SaveFile.
+func LoadFile(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
@@ -0,0 +1,92 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxEmbeddingInputs is the number of inputs a single embeddings request accepts.
+const maxEmbeddingInputs = 2048
+
+// Embedder creates embeddings. It is satisfied by *openai.Client and *openai.Router.
+type Embedder interface {
+	CreateEmbeddings(
+		ctx context.Context,
+		conv openai.EmbeddingRequestConverter,
+		opts ...openai.RequestOption,
+	) (openai.EmbeddingResponse, error)
+}
+
+// TextDocument is a text to embed and index.
+type TextDocument struct {
+	ID       string
+	Text     string
+	Metadata map[string]string
+}
+
+// IndexTexts embeds docs with model and returns a new index holding them.
+func IndexTexts(
+	ctx context.Context,
+	client Embedder,
+	model openai.EmbeddingModel,
+	docs []TextDocument,
+	opts ...openai.RequestOption,
+) (*Index, error) {
+	ix := New(Options{})
+	if err := ix.AddTexts(ctx, client, model, docs, opts...); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// AddTexts embeds docs with model, in requests of up to 2048 texts, and
+// adds them to the index. Documents embedded before an error are added.
+func (ix *Index) AddTexts(
+	ctx context.Context,
+	client Embedder,
+	model openai.EmbeddingModel,
+	docs []TextDocument,
+	opts ...openai.RequestOption,
+) error {
+	for start := 0; start < len(docs); start += maxEmbeddingInputs {
+		end := start + maxEmbeddingInputs
+		if end > len(docs) {
+			end = len(docs)
+		}
+		batch := docs[start:end]
+		input := make([]string, len(batch))
+		for i, doc := range batch {
+			input[i] = doc.Text
+		}
+
+		request := openai.EmbeddingRequestStrings{Input: input, Model: model}
+		response, err := client.CreateEmbeddings(ctx, request, opts...)
+		if err != nil {
+			return fmt.Errorf("embedding documents %d to %d: %w", start, end, err)
+		}
+		if len(response.Data) != len(batch) {
+			return fmt.Errorf("embedding documents %d to %d: got %d embeddings: %w",
+				start, end, len(response.Data), openai.ErrVectorLengthMismatch)
+		}
+
+		indexed := make([]Document, len(batch))
+		for _, embedding := range response.Data {
+			if embedding.Index < 0 || embedding.Index >= len(batch) {
+				return fmt.Errorf("embedding documents %d to %d: unexpected index %d", start, end, embedding.Index)
+			}
+			doc := batch[embedding.Index]
+			indexed[embedding.Index] = Document{
+				ID:       doc.ID,
+				Text:     doc.Text,
+				Metadata: doc.Metadata,
+				Vector:   embedding.Embedding,
+			}
+		}
+		if err = ix.Add(indexed...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
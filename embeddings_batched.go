@@ -0,0 +1,226 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// maxEmbeddingInputsPerRequest and maxEmbeddingTokensPerRequest are the
+	// limits of a single embeddings request.
+	maxEmbeddingInputsPerRequest = 2048
+	maxEmbeddingTokensPerRequest = 300000
+	defaultEmbeddingConcurrency  = 4
+	// bytesPerTokenEstimate errs on the side of overestimating tokens: English
+	// averages about four bytes per token.
+	bytesPerTokenEstimate = 3
+)
+
+var ErrEmbeddingBatchIncomplete = errors.New("embeddings response is missing inputs")
+
+// EmbeddingBatchOptions configures CreateEmbeddingsBatched. Zero values use the defaults.
+type EmbeddingBatchOptions struct {
+	// MaxInputsPerRequest defaults to 2048, the API limit.
+	MaxInputsPerRequest int
+	// MaxTokensPerRequest defaults to 300,000, the API limit.
+	MaxTokensPerRequest int
+	// CountTokens returns the number of tokens of a string input. Defaults to
+	// an estimate of one token per three bytes; pass a tokenizer for exact
+	// budgets. Token inputs are counted exactly.
+	CountTokens func(input string) int
+	// Concurrency is the number of requests sent at once. Defaults to 4.
+	Concurrency int
+	// Base64 requests the embeddings base64 encoded, a smaller response
+	// than JSON floats, and decodes them.
+	Base64 bool
+}
+
+func (o EmbeddingBatchOptions) withDefaults() EmbeddingBatchOptions {
+	if o.MaxInputsPerRequest <= 0 || o.MaxInputsPerRequest > maxEmbeddingInputsPerRequest {
+		o.MaxInputsPerRequest = maxEmbeddingInputsPerRequest
+	}
+	if o.MaxTokensPerRequest <= 0 || o.MaxTokensPerRequest > maxEmbeddingTokensPerRequest {
+		o.MaxTokensPerRequest = maxEmbeddingTokensPerRequest
+	}
+	if o.CountTokens == nil {
+		o.CountTokens = estimateTokens
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultEmbeddingConcurrency
+	}
+	return o
+}
+
+func estimateTokens(input string) int {
+	return (len(input) + bytesPerTokenEstimate - 1) / bytesPerTokenEstimate
+}
+
+// embeddingsAPI is the part of Client and Router CreateEmbeddingsBatched sends requests with.
+type embeddingsAPI interface {
+	CreateEmbeddings(ctx context.Context, conv EmbeddingRequestConverter, opts ...RequestOption) (
+		EmbeddingResponse, error)
+}
+
+// CreateEmbeddingsBatched is CreateEmbeddings for any number of inputs. The
+// inputs of conv, strings or tokens, are split into requests within the
+// input and token limits, which are sent concurrently. The response holds
+// the embeddings of all inputs in their original order and the summed usage.
+// The first failing request fails the call.
+func (c *Client) CreateEmbeddingsBatched(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	options EmbeddingBatchOptions,
+	opts ...RequestOption,
+) (EmbeddingResponse, error) {
+	return createEmbeddingsBatched(ctx, c, conv, options, opts)
+}
+
+// embeddingChunk is the inputs of one request, from index start of the original inputs.
+type embeddingChunk struct {
+	start int
+	input any
+	size  int
+}
+
+// chunkEmbeddingInputs splits the inputs of request by count and token budget.
+func chunkEmbeddingInputs(request EmbeddingRequest, options EmbeddingBatchOptions) []embeddingChunk {
+	var (
+		count  int
+		tokens func(i int) int
+		slice  func(start, end int) any
+	)
+	switch input := request.Input.(type) {
+	case []string:
+		count = len(input)
+		tokens = func(i int) int { return options.CountTokens(input[i]) }
+		slice = func(start, end int) any { return input[start:end] }
+	case [][]int:
+		count = len(input)
+		tokens = func(i int) int { return len(input[i]) }
+		slice = func(start, end int) any { return input[start:end] }
+	default:
+		// A single input needs no splitting.
+		return []embeddingChunk{{input: request.Input, size: 1}}
+	}
+
+	var chunks []embeddingChunk
+	start, budget := 0, 0
+	for i := 0; i < count; i++ {
+		n := tokens(i)
+		if i > start && (i-start >= options.MaxInputsPerRequest || budget+n > options.MaxTokensPerRequest) {
+			chunks = append(chunks, embeddingChunk{start: start, input: slice(start, i), size: i - start})
+			start, budget = i, 0
+		}
+		budget += n
+	}
+	if count > start {
+		chunks = append(chunks, embeddingChunk{start: start, input: slice(start, count), size: count - start})
+	}
+	return chunks
+}
+
+func createEmbeddingsBatched(
+	ctx context.Context,
+	api embeddingsAPI,
+	conv EmbeddingRequestConverter,
+	options EmbeddingBatchOptions,
+	opts []RequestOption,
+) (EmbeddingResponse, error) {
+	options = options.withDefaults()
+	request := conv.Convert()
+	if options.Base64 {
+		request.EncodingFormat = EmbeddingEncodingFormatBase64
+	}
+	chunks := chunkEmbeddingInputs(request, options)
+	responses := make([]EmbeddingResponse, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, options.Concurrency)
+	for i := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			chunkRequest := request
+			chunkRequest.Input = chunks[i].input
+			response, err := api.CreateEmbeddings(ctx, chunkRequest, opts...)
+			if err == nil && len(response.Data) != chunks[i].size {
+				err = fmt.Errorf("%w: got %d embeddings for %d inputs",
+					ErrEmbeddingBatchIncomplete, len(response.Data), chunks[i].size)
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("embedding inputs %d to %d: %w",
+						chunks[i].start, chunks[i].start+chunks[i].size, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			responses[i] = response
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return EmbeddingResponse{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return EmbeddingResponse{}, err
+	}
+	return mergeEmbeddingResponses(chunks, responses)
+}
+
+// mergeEmbeddingResponses reassembles the responses of chunks in the
+// original input order.
+func mergeEmbeddingResponses(chunks []embeddingChunk, responses []EmbeddingResponse) (EmbeddingResponse, error) {
+	total := 0
+	for _, chunk := range chunks {
+		total += chunk.size
+	}
+	merged := EmbeddingResponse{Object: "list", Data: make([]Embedding, total)}
+	filled := make([]bool, total)
+	for i, response := range responses {
+		if merged.Model == "" {
+			merged.Model = response.Model
+		}
+		if merged.httpHeader == nil {
+			merged.httpHeader = response.httpHeader
+		}
+		merged.Usage.PromptTokens += response.Usage.PromptTokens
+		merged.Usage.CompletionTokens += response.Usage.CompletionTokens
+		merged.Usage.TotalTokens += response.Usage.TotalTokens
+		for _, embedding := range response.Data {
+			if embedding.Index < 0 || embedding.Index >= chunks[i].size {
+				return EmbeddingResponse{}, fmt.Errorf("%w: unexpected index %d",
+					ErrEmbeddingBatchIncomplete, embedding.Index)
+			}
+			embedding.Index += chunks[i].start
+			merged.Data[embedding.Index] = embedding
+			filled[embedding.Index] = true
+		}
+	}
+	for i, ok := range filled {
+		if !ok {
+			return EmbeddingResponse{}, fmt.Errorf("%w: no embedding for input %d", ErrEmbeddingBatchIncomplete, i)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,154 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// embeddingsServer answers every input, a number, with the vector [number, 1].
+type embeddingsServer struct {
+	mu       sync.Mutex
+	requests [][]string
+	formats  []openai.EmbeddingEncodingFormat
+	// failAt answers requests holding this input with a 400.
+	failAt string
+}
+
+func (s *embeddingsServer) handler(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input          []string                       `json:"input"`
+			EncodingFormat openai.EmbeddingEncodingFormat `json:"encoding_format"`
+		}
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&request), "Decode error")
+		s.mu.Lock()
+		s.requests = append(s.requests, request.Input)
+		s.formats = append(s.formats, request.EncodingFormat)
+		s.mu.Unlock()
+
+		data := make([]map[string]any, len(request.Input))
+		for i, input := range request.Input {
+			if input == s.failAt {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"message":"too many tokens"}}`)
+				return
+			}
+			n, _ := strconv.Atoi(input)
+			vector := []float32{float32(n), 1}
+			// Answer in reverse order, which the API is free to do.
+			data[len(data)-1-i] = map[string]any{"index": i, "embedding": vector}
+			if request.EncodingFormat == openai.EmbeddingEncodingFormatBase64 {
+				data[len(data)-1-i]["embedding"] = openai.EncodeEmbeddingBase64(vector)
+			}
+		}
+		checks.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"model":  "text-embedding-3-small",
+			"data":   data,
+			"usage":  map[string]int{"prompt_tokens": len(request.Input), "total_tokens": len(request.Input)},
+		}), "Encode error")
+	}
+}
+
+func numberInputs(n int) []string {
+	inputs := make([]string, n)
+	for i := range inputs {
+		inputs[i] = strconv.Itoa(i)
+	}
+	return inputs
+}
+
+func TestCreateEmbeddingsBatched(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := &embeddingsServer{}
+	server.RegisterHandler("/v1/embeddings", fake.handler(t))
+
+	inputs := numberInputs(25)
+	response, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequestStrings{
+		Input: inputs,
+		Model: openai.SmallEmbedding3,
+	}, openai.EmbeddingBatchOptions{
+		MaxInputsPerRequest: 10,
+		// Every input costs its length in tokens: 1 up to "9", then 2.
+		MaxTokensPerRequest: 12,
+		CountTokens:         func(input string) int { return len(input) },
+		Concurrency:         2,
+	})
+	checks.NoError(t, err, "CreateEmbeddingsBatched error")
+
+	// 0-9 fit by tokens but not by count, then six two-digit inputs per request.
+	if len(fake.requests) != 4 {
+		t.Fatalf("expected 4 requests, got %v", fake.requests)
+	}
+	for _, request := range fake.requests {
+		tokens := 0
+		for _, input := range request {
+			tokens += len(input)
+		}
+		if len(request) > 10 || tokens > 12 {
+			t.Fatalf("request %v exceeds the limits", request)
+		}
+	}
+
+	if len(response.Data) != len(inputs) || response.Usage.TotalTokens != len(inputs) ||
+		response.Model != openai.SmallEmbedding3 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	for i, embedding := range response.Data {
+		if embedding.Index != i || embedding.Embedding[0] != float32(i) {
+			t.Fatalf("embedding %d out of order: %+v", i, embedding)
+		}
+	}
+}
+
+func TestCreateEmbeddingsBatchedBase64(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := &embeddingsServer{}
+	server.RegisterHandler("/v1/embeddings", fake.handler(t))
+
+	response, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequest{
+		Input: numberInputs(5),
+		Model: openai.SmallEmbedding3,
+	}, openai.EmbeddingBatchOptions{MaxInputsPerRequest: 2, Base64: true})
+	checks.NoError(t, err, "CreateEmbeddingsBatched error")
+
+	for _, format := range fake.formats {
+		if format != openai.EmbeddingEncodingFormatBase64 {
+			t.Fatalf("expected base64 requests, got %v", fake.formats)
+		}
+	}
+	if len(response.Data) != 5 || response.Data[4].Embedding[0] != 4 {
+		t.Fatalf("unexpected decoded response %+v", response.Data)
+	}
+}
+
+func TestCreateEmbeddingsBatchedError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := &embeddingsServer{failAt: "7"}
+	server.RegisterHandler("/v1/embeddings", fake.handler(t))
+
+	_, err := client.CreateEmbeddingsBatched(context.Background(), openai.EmbeddingRequestStrings{
+		Input: numberInputs(10),
+		Model: openai.SmallEmbedding3,
+	}, openai.EmbeddingBatchOptions{MaxInputsPerRequest: 3, Concurrency: 1})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+	if len(fake.requests) != 3 {
+		t.Fatalf("expected the failure to stop the remaining requests, got %v", fake.requests)
+	}
+}
@@ -0,0 +1,393 @@
+package openai
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+const (
+	embeddingCacheDirMode           = 0o700
+	defaultLRUEmbeddingCacheEntries = 10000
+)
+
+// EmbeddingCacheKey identifies the embedding of an input by the model,
+// dimensions and a SHA-256 hash of the input.
+type EmbeddingCacheKey struct {
+	Model      EmbeddingModel
+	Dimensions int
+	Hash       [sha256.Size]byte
+}
+
+func (k EmbeddingCacheKey) String() string {
+	return string(k.Model) + "/" + strconv.Itoa(k.Dimensions) + "/" + hex.EncodeToString(k.Hash[:])
+}
+
+// EmbeddingCache stores embeddings for a CachedEmbedder. Implementations must
+// be safe for concurrent use.
+type EmbeddingCache interface {
+	// Get returns the embedding stored for key, and whether there is one.
+	Get(key EmbeddingCacheKey) ([]float32, bool, error)
+	Set(key EmbeddingCacheKey, embedding []float32) error
+}
+
+// LRUEmbeddingCache is an in-memory EmbeddingCache holding up to a fixed
+// number of embeddings, evicting the least recently used.
+type LRUEmbeddingCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[EmbeddingCacheKey]*list.Element
+}
+
+type lruEmbeddingEntry struct {
+	key       EmbeddingCacheKey
+	embedding []float32
+}
+
+// NewLRUEmbeddingCache returns a cache holding up to capacity embeddings. A
+// capacity of 0 or less uses the default of 10000 embeddings.
+func NewLRUEmbeddingCache(capacity int) *LRUEmbeddingCache {
+	if capacity <= 0 {
+		capacity = defaultLRUEmbeddingCacheEntries
+	}
+	return &LRUEmbeddingCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[EmbeddingCacheKey]*list.Element{},
+	}
+}
+
+func (c *LRUEmbeddingCache) Get(key EmbeddingCacheKey) ([]float32, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return copyFloat32s(element.Value.(*lruEmbeddingEntry).embedding), true, nil
+}
+
+func (c *LRUEmbeddingCache) Set(key EmbeddingCacheKey, embedding []float32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEmbeddingEntry).embedding = copyFloat32s(embedding)
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEmbeddingEntry{key: key, embedding: copyFloat32s(embedding)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEmbeddingEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of embeddings in the cache.
+func (c *LRUEmbeddingCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func copyFloat32s(v []float32) []float32 {
+	return append([]float32(nil), v...)
+}
+
+// FileEmbeddingCache is an EmbeddingCache storing every embedding in a file
+// of a directory, encoded with EncodeEmbedding. It never evicts.
+type FileEmbeddingCache struct {
+	dir string
+}
+
+// NewFileEmbeddingCache returns a cache storing embeddings in dir, which is
+// created if needed.
+func NewFileEmbeddingCache(dir string) (*FileEmbeddingCache, error) {
+	if err := os.MkdirAll(dir, embeddingCacheDirMode); err != nil {
+		return nil, err
+	}
+	return &FileEmbeddingCache{dir: dir}, nil
+}
+
+// path spreads the files over 256 subdirectories.
+func (c *FileEmbeddingCache) path(key EmbeddingCacheKey) string {
+	sum := sha256.Sum256([]byte(key.String()))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name+".f32")
+}
+
+func (c *FileEmbeddingCache) Get(key EmbeddingCacheKey) ([]float32, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	embedding, err := DecodeEmbedding(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading cached embedding %s: %w", key, err)
+	}
+	return embedding, true, nil
+}
+
+func (c *FileEmbeddingCache) Set(key EmbeddingCacheKey, embedding []float32) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), embeddingCacheDirMode); err != nil {
+		return err
+	}
+	// Every writer uses its own temporary file, so concurrent writes of the
+	// same key don't interleave.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(EncodeEmbedding(embedding)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CachedEmbedder creates embeddings through an EmbeddingCache. Only inputs
+// missing from the cache are sent, once however often they appear, and
+// concurrent calls embedding the same input share a single request, whose
+// failure fails all of them.
+type CachedEmbedder struct {
+	api   embeddingsAPI
+	cache EmbeddingCache
+
+	mu       sync.Mutex
+	inflight map[EmbeddingCacheKey]*embeddingCall
+}
+
+// embeddingCall is an input being embedded by one of the calls of a CachedEmbedder.
+type embeddingCall struct {
+	done      chan struct{}
+	embedding []float32
+	err       error
+}
+
+// NewCachedEmbedder returns a CachedEmbedder creating embeddings with the client.
+func (c *Client) NewCachedEmbedder(cache EmbeddingCache) *CachedEmbedder {
+	return newCachedEmbedder(c, cache)
+}
+
+func newCachedEmbedder(api embeddingsAPI, cache EmbeddingCache) *CachedEmbedder {
+	return &CachedEmbedder{api: api, cache: cache, inflight: map[EmbeddingCacheKey]*embeddingCall{}}
+}
+
+// embeddingCacheInputs returns the cache keys of the inputs of request, or
+// false for inputs which aren't strings or tokens.
+func embeddingCacheInputs(request EmbeddingRequest) ([]EmbeddingCacheKey, []any, bool) {
+	var inputs []any
+	var hashes [][sha256.Size]byte
+	input := request.Input
+	switch single := input.(type) {
+	case string:
+		input = []string{single}
+	case []int:
+		input = [][]int{single}
+	}
+	switch input := input.(type) {
+	case []string:
+		for _, text := range input {
+			inputs = append(inputs, text)
+			hashes = append(hashes, sha256.Sum256([]byte("text:"+text)))
+		}
+	case [][]int:
+		buf := make([]byte, binary.MaxVarintLen64)
+		for _, tokens := range input {
+			inputs = append(inputs, tokens)
+			h := sha256.New()
+			h.Write([]byte("tokens:"))
+			for _, token := range tokens {
+				h.Write(buf[:binary.PutVarint(buf, int64(token))])
+			}
+			var sum [sha256.Size]byte
+			copy(sum[:], h.Sum(nil))
+			hashes = append(hashes, sum)
+		}
+	default:
+		return nil, nil, false
+	}
+	keys := make([]EmbeddingCacheKey, len(hashes))
+	for i, hash := range hashes {
+		keys[i] = EmbeddingCacheKey{Model: request.Model, Dimensions: request.Dimensions, Hash: hash}
+	}
+	return keys, inputs, true
+}
+
+// CreateEmbeddings returns the embeddings of the inputs of conv like
+// Client.CreateEmbeddings, taking them from the cache where possible. Usage
+// counts the inputs this call sent. Inputs other than strings and tokens
+// bypass the cache.
+func (e *CachedEmbedder) CreateEmbeddings(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	opts ...RequestOption,
+) (EmbeddingResponse, error) {
+	request := conv.Convert()
+	keys, inputs, ok := embeddingCacheInputs(request)
+	if !ok {
+		return e.api.CreateEmbeddings(ctx, request, opts...)
+	}
+
+	response := EmbeddingResponse{Object: "list", Model: request.Model, Data: make([]Embedding, len(keys))}
+	pending := map[EmbeddingCacheKey]*embeddingCall{}
+	var misses []int
+	for i, key := range keys {
+		response.Data[i] = Embedding{Object: "embedding", Index: i}
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		embedding, hit, err := e.cache.Get(key)
+		if err != nil {
+			return EmbeddingResponse{}, fmt.Errorf("embedding cache: %w", err)
+		}
+		pending[key] = &embeddingCall{embedding: embedding}
+		if !hit {
+			misses = append(misses, i)
+		}
+	}
+
+	// Calls are only joined once nothing can fail before embedMisses
+	// completes the calls this one leads.
+	var (
+		missKeys   []EmbeddingCacheKey
+		missInputs []any
+	)
+	for _, i := range misses {
+		call, leader := e.join(keys[i])
+		pending[keys[i]] = call
+		if leader {
+			missKeys = append(missKeys, keys[i])
+			missInputs = append(missInputs, inputs[i])
+		}
+	}
+
+	if len(missKeys) > 0 {
+		sent, err := e.embedMisses(ctx, request, missKeys, missInputs, opts)
+		if err != nil {
+			return EmbeddingResponse{}, err
+		}
+		response.Model, response.Usage, response.httpHeader = sent.Model, sent.Usage, sent.httpHeader
+	}
+
+	for i, key := range keys {
+		call := pending[key]
+		if call.done != nil {
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return EmbeddingResponse{}, ctx.Err()
+			}
+		}
+		if call.err != nil {
+			return EmbeddingResponse{}, call.err
+		}
+		response.Data[i].Embedding = copyFloat32s(call.embedding)
+	}
+	return response, nil
+}
+
+// join returns the call embedding key, registering a new one if there is
+// none. leader reports whether the caller registered it and must embed key.
+func (e *CachedEmbedder) join(key EmbeddingCacheKey) (call *embeddingCall, leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if call, ok := e.inflight[key]; ok {
+		return call, false
+	}
+	call = &embeddingCall{done: make(chan struct{})}
+	e.inflight[key] = call
+	return call, true
+}
+
+// embedMisses embeds the inputs of keys, which the caller leads, stores them
+// in the cache and completes their calls.
+func (e *CachedEmbedder) embedMisses(
+	ctx context.Context,
+	request EmbeddingRequest,
+	keys []EmbeddingCacheKey,
+	inputs []any,
+	opts []RequestOption,
+) (response EmbeddingResponse, err error) {
+	embeddings := make([][]float32, len(keys))
+	defer func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		for i, key := range keys {
+			call := e.inflight[key]
+			call.embedding, call.err = embeddings[i], err
+			delete(e.inflight, key)
+			close(call.done)
+		}
+	}()
+
+	request.Input = embeddingRequestInput(inputs)
+	response, err = e.api.CreateEmbeddings(ctx, request, opts...)
+	if err != nil {
+		return
+	}
+	for _, embedding := range response.Data {
+		if embedding.Index < 0 || embedding.Index >= len(keys) {
+			err = fmt.Errorf("%w: unexpected index %d", ErrEmbeddingBatchIncomplete, embedding.Index)
+			return
+		}
+		embeddings[embedding.Index] = embedding.Embedding
+	}
+	for i, key := range keys {
+		if embeddings[i] == nil {
+			err = fmt.Errorf("%w: no embedding for input %d", ErrEmbeddingBatchIncomplete, i)
+			return
+		}
+		if err = e.cache.Set(key, embeddings[i]); err != nil {
+			err = fmt.Errorf("embedding cache: %w", err)
+			return
+		}
+	}
+	return
+}
+
+// embeddingRequestInput converts inputs back to the input type of a request.
+func embeddingRequestInput(inputs []any) any {
+	if _, isText := inputs[0].(string); isText {
+		texts := make([]string, len(inputs))
+		for i, input := range inputs {
+			texts[i] = input.(string)
+		}
+		return texts
+	}
+	tokens := make([][]int, len(inputs))
+	for i, input := range inputs {
+		tokens[i] = input.([]int)
+	}
+	return tokens
+}
+
+// CreateEmbeddingsBatched is Client.CreateEmbeddingsBatched through the cache.
+func (e *CachedEmbedder) CreateEmbeddingsBatched(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	options EmbeddingBatchOptions,
+	opts ...RequestOption,
+) (EmbeddingResponse, error) {
+	return createEmbeddingsBatched(ctx, e, conv, options, opts)
+}
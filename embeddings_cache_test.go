@@ -0,0 +1,136 @@
+package openai_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestLRUEmbeddingCache(t *testing.T) {
+	cache := openai.NewLRUEmbeddingCache(2)
+	key := func(text string) openai.EmbeddingCacheKey {
+		return openai.EmbeddingCacheKey{Model: openai.SmallEmbedding3, Hash: sha256.Sum256([]byte(text))}
+	}
+	checks.NoError(t, cache.Set(key("a"), []float32{1}), "Set error")
+	checks.NoError(t, cache.Set(key("b"), []float32{2}), "Set error")
+	// Reading a makes b the least recently used.
+	embedding, ok, _ := cache.Get(key("a"))
+	if !ok || embedding[0] != 1 {
+		t.Fatalf("expected a to be cached, got %v", embedding)
+	}
+	embedding[0] = 100
+	checks.NoError(t, cache.Set(key("c"), []float32{3}), "Set error")
+
+	if _, ok, _ = cache.Get(key("b")); ok || cache.Len() != 2 {
+		t.Fatalf("expected b to be evicted, %d cached", cache.Len())
+	}
+	if embedding, _, _ = cache.Get(key("a")); embedding[0] != 1 {
+		t.Fatalf("expected the cached embedding to be a copy, got %v", embedding)
+	}
+
+	// A capacity of 0 uses the default instead of evicting every embedding.
+	cache = openai.NewLRUEmbeddingCache(0)
+	checks.NoError(t, cache.Set(key("a"), []float32{1}), "Set error")
+	if _, ok, _ = cache.Get(key("a")); !ok || cache.Len() != 1 {
+		t.Fatalf("expected a to be cached, %d cached", cache.Len())
+	}
+}
+
+func TestCachedEmbedder(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := &embeddingsServer{}
+	server.RegisterHandler("/v1/embeddings", fake.handler(t))
+
+	cache, err := openai.NewFileEmbeddingCache(t.TempDir())
+	checks.NoError(t, err, "NewFileEmbeddingCache error")
+	embedder := client.NewCachedEmbedder(cache)
+	request := openai.EmbeddingRequestStrings{Input: []string{"1", "2", "1"}, Model: openai.SmallEmbedding3}
+	response, err := embedder.CreateEmbeddings(context.Background(), request)
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(fake.requests) != 1 || len(fake.requests[0]) != 2 || response.Usage.PromptTokens != 2 {
+		t.Fatalf("expected the two distinct inputs to be sent once, got %v and %+v", fake.requests, response.Usage)
+	}
+	if len(response.Data) != 3 || response.Data[2].Index != 2 || response.Data[2].Embedding[0] != 1 {
+		t.Fatalf("unexpected response %+v", response.Data)
+	}
+
+	// A new embedder on the same directory only sends the new input.
+	embedder = client.NewCachedEmbedder(cache)
+	request.Input = []string{"2", "3"}
+	response, err = embedder.CreateEmbeddings(context.Background(), request)
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(fake.requests) != 2 || len(fake.requests[1]) != 1 || fake.requests[1][0] != "3" ||
+		response.Usage.PromptTokens != 1 {
+		t.Fatalf("expected only the miss to be sent, got %v and %+v", fake.requests, response.Usage)
+	}
+	if response.Data[0].Embedding[0] != 2 || response.Data[1].Embedding[0] != 3 {
+		t.Fatalf("unexpected response %+v", response.Data)
+	}
+
+	// Other dimensions are other embeddings.
+	request.Dimensions = 1
+	_, err = embedder.CreateEmbeddings(context.Background(), request)
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(fake.requests) != 3 {
+		t.Fatalf("expected a request for the new dimensions, got %v", fake.requests)
+	}
+
+	// Hits alone send nothing and use no tokens.
+	request.Dimensions = 0
+	response, err = embedder.CreateEmbeddings(context.Background(), request)
+	checks.NoError(t, err, "CreateEmbeddings error")
+	if len(fake.requests) != 3 || response.Usage.TotalTokens != 0 {
+		t.Fatalf("expected no request, got %v", fake.requests)
+	}
+}
+
+func TestCachedEmbedderSingleFlight(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	fake := &embeddingsServer{}
+	handler := fake.handler(t)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	server.RegisterHandler("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		handler(w, r)
+	})
+
+	embedder := client.NewCachedEmbedder(openai.NewLRUEmbeddingCache(10))
+	request := openai.EmbeddingRequest{Input: "7", Model: openai.SmallEmbedding3}
+	var wg sync.WaitGroup
+	responses := make([]openai.EmbeddingResponse, 2)
+	errs := make([]error, 2)
+	call := func(i int) {
+		defer wg.Done()
+		responses[i], errs[i] = embedder.CreateEmbeddings(context.Background(), request)
+	}
+	wg.Add(2)
+	go call(0)
+	<-started
+	go call(1)
+	// Give the second call time to join the first one's request.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := range responses {
+		checks.NoError(t, errs[i], "CreateEmbeddings error")
+		if responses[i].Data[0].Embedding[0] != 7 {
+			t.Fatalf("unexpected response %+v", responses[i])
+		}
+	}
+	if len(fake.requests) != 1 {
+		t.Fatalf("expected a single request, got %v", fake.requests)
+	}
+	if responses[0].Usage.PromptTokens+responses[1].Usage.PromptTokens != 1 {
+		t.Fatal("expected only the call sending the request to count its usage")
+	}
+}
@@ -0,0 +1,252 @@
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	sizeOfFloat32 = 4
+	maxInt8       = 127
+	bitsPerByte   = 8
+)
+
+var (
+	ErrEmbeddingDimensionsInvalid = errors.New("embedding dimensions must be between 1 and the vector length")
+	ErrEmbeddingEncodingInvalid   = errors.New("encoded embedding length is not a multiple of 4")
+)
+
+// CosineSimilarity returns the cosine of the angle between a and b, which
+// unlike their dot product doesn't require normalized vectors. It is 0 if
+// either vector is zero. Sums are accumulated in float64.
+func CosineSimilarity(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, ErrVectorLengthMismatch
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB))), nil
+}
+
+// L2Distance returns the Euclidean distance between a and b.
+func L2Distance(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, ErrVectorLengthMismatch
+	}
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return float32(math.Sqrt(sum)), nil
+}
+
+// Normalize returns a copy of v scaled to unit length. A zero vector is
+// returned unchanged.
+func Normalize(v []float32) []float32 {
+	normalized := make([]float32, len(v))
+	copy(normalized, v)
+	normalizeInPlace(normalized)
+	return normalized
+}
+
+func normalizeInPlace(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range v {
+		v[i] = float32(float64(v[i]) / norm)
+	}
+}
+
+// TruncateEmbedding shortens v to its first dimensions values and
+// renormalizes it. For models trained with Matryoshka representation
+// learning, like text-embedding-3-small and -large, this matches requesting
+// the embedding with EmbeddingRequest.Dimensions.
+func TruncateEmbedding(v []float32, dimensions int) ([]float32, error) {
+	if dimensions <= 0 || dimensions > len(v) {
+		return nil, fmt.Errorf("%w: %d of %d", ErrEmbeddingDimensionsInvalid, dimensions, len(v))
+	}
+	return Normalize(v[:dimensions]), nil
+}
+
+// CosineSimilarity returns the cosine similarity of the embedding with another one.
+func (e *Embedding) CosineSimilarity(other *Embedding) (float32, error) {
+	return CosineSimilarity(e.Embedding, other.Embedding)
+}
+
+// L2Distance returns the Euclidean distance of the embedding to another one.
+func (e *Embedding) L2Distance(other *Embedding) (float32, error) {
+	return L2Distance(e.Embedding, other.Embedding)
+}
+
+// Normalize scales the embedding to unit length in place, after which
+// DotProduct equals CosineSimilarity.
+func (e *Embedding) Normalize() {
+	normalizeInPlace(e.Embedding)
+}
+
+// Truncate shortens the embedding to dimensions values and renormalizes it, see TruncateEmbedding.
+func (e *Embedding) Truncate(dimensions int) error {
+	truncated, err := TruncateEmbedding(e.Embedding, dimensions)
+	if err != nil {
+		return err
+	}
+	e.Embedding = truncated
+	return nil
+}
+
+// Int8Embedding is an embedding quantized to int8, a quarter of its float32
+// size. Every value is approximately Values[i] * Scale.
+type Int8Embedding struct {
+	Values []int8
+	Scale  float32
+}
+
+// QuantizeInt8 quantizes v symmetrically, mapping its largest absolute value to 127.
+func QuantizeInt8(v []float32) Int8Embedding {
+	var maxAbs float64
+	for _, x := range v {
+		maxAbs = math.Max(maxAbs, math.Abs(float64(x)))
+	}
+	q := Int8Embedding{Values: make([]int8, len(v))}
+	if maxAbs == 0 {
+		return q
+	}
+	scale := maxAbs / maxInt8
+	q.Scale = float32(scale)
+	for i, x := range v {
+		q.Values[i] = int8(math.Round(float64(x) / scale))
+	}
+	return q
+}
+
+// Dequantize returns the approximate float32 values of the embedding.
+func (q Int8Embedding) Dequantize() []float32 {
+	v := make([]float32, len(q.Values))
+	for i, x := range q.Values {
+		v[i] = float32(x) * q.Scale
+	}
+	return v
+}
+
+// DotProduct approximates the dot product of the original vectors.
+func (q Int8Embedding) DotProduct(other Int8Embedding) (float32, error) {
+	if len(q.Values) != len(other.Values) {
+		return 0, ErrVectorLengthMismatch
+	}
+	var dot int64
+	for i := range q.Values {
+		dot += int64(q.Values[i]) * int64(other.Values[i])
+	}
+	return float32(float64(dot) * float64(q.Scale) * float64(other.Scale)), nil
+}
+
+// CosineSimilarity approximates the cosine similarity of the original vectors.
+func (q Int8Embedding) CosineSimilarity(other Int8Embedding) (float32, error) {
+	if len(q.Values) != len(other.Values) {
+		return 0, ErrVectorLengthMismatch
+	}
+	var dot, normA, normB int64
+	for i := range q.Values {
+		x, y := int64(q.Values[i]), int64(other.Values[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return float32(float64(dot) / (math.Sqrt(float64(normA)) * math.Sqrt(float64(normB)))), nil
+}
+
+// BinaryEmbedding is an embedding quantized to one bit per value, set for
+// positive values, a 32nd of its float32 size. Bits are packed most
+// significant bit first.
+type BinaryEmbedding struct {
+	Bits       []byte
+	Dimensions int
+}
+
+// QuantizeBinary quantizes v to the signs of its values.
+func QuantizeBinary(v []float32) BinaryEmbedding {
+	b := BinaryEmbedding{Bits: make([]byte, (len(v)+bitsPerByte-1)/bitsPerByte), Dimensions: len(v)}
+	for i, x := range v {
+		if x > 0 {
+			b.Bits[i/bitsPerByte] |= 0x80 >> (i % bitsPerByte)
+		}
+	}
+	return b
+}
+
+// HammingDistance returns the number of values whose signs differ between a and b.
+func HammingDistance(a, b BinaryEmbedding) (int, error) {
+	if a.Dimensions != b.Dimensions || len(a.Bits) != len(b.Bits) {
+		return 0, ErrVectorLengthMismatch
+	}
+	distance := 0
+	for i := range a.Bits {
+		distance += bits.OnesCount8(a.Bits[i] ^ b.Bits[i])
+	}
+	return distance, nil
+}
+
+// Similarity scores b against other from 1, for identical signs, to -1, for
+// opposite ones. It is a cheap approximation of cosine similarity, suited to
+// shortlisting candidates which are then rescored with the full vectors.
+func (b BinaryEmbedding) Similarity(other BinaryEmbedding) (float32, error) {
+	distance, err := HammingDistance(b, other)
+	if err != nil || b.Dimensions == 0 {
+		return 0, err
+	}
+	return 1 - 2*float32(distance)/float32(b.Dimensions), nil
+}
+
+// EncodeEmbedding serializes v as little-endian float32 values, the format
+// of embeddings requested with EmbeddingEncodingFormatBase64.
+func EncodeEmbedding(v []float32) []byte {
+	data := make([]byte, len(v)*sizeOfFloat32)
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(data[i*sizeOfFloat32:], math.Float32bits(x))
+	}
+	return data
+}
+
+// DecodeEmbedding deserializes a vector serialized by EncodeEmbedding.
+func DecodeEmbedding(data []byte) ([]float32, error) {
+	if len(data)%sizeOfFloat32 != 0 {
+		return nil, ErrEmbeddingEncodingInvalid
+	}
+	return float32sFromBytes(data), nil
+}
+
+// EncodeEmbeddingBase64 serializes v like the API encodes base64 embeddings.
+func EncodeEmbeddingBase64(v []float32) string {
+	return base64.StdEncoding.EncodeToString(EncodeEmbedding(v))
+}
+
+// float32sFromBytes decodes little-endian float32 values, ignoring trailing bytes.
+func float32sFromBytes(data []byte) []float32 {
+	floats := make([]float32, len(data)/sizeOfFloat32)
+	for i := range floats {
+		floats[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*sizeOfFloat32:]))
+	}
+	return floats
+}
@@ -0,0 +1,145 @@
+package openai_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func approxEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-5
+}
+
+func TestCosineSimilarityAndL2Distance(t *testing.T) {
+	a := &openai.Embedding{Embedding: []float32{1, 2, 3}}
+	b := &openai.Embedding{Embedding: []float32{2, 4, 6}}
+	c := &openai.Embedding{Embedding: []float32{-3, 0, 1}}
+
+	similarity, err := a.CosineSimilarity(b)
+	checks.NoError(t, err, "CosineSimilarity error")
+	if !approxEqual(similarity, 1) {
+		t.Fatalf("expected parallel vectors to have similarity 1, got %v", similarity)
+	}
+	if similarity, _ = a.CosineSimilarity(c); !approxEqual(similarity, 0) {
+		t.Fatalf("expected orthogonal vectors to have similarity 0, got %v", similarity)
+	}
+	if similarity, _ = openai.CosineSimilarity([]float32{0, 0}, []float32{1, 1}); similarity != 0 {
+		t.Fatalf("expected a zero vector to have similarity 0, got %v", similarity)
+	}
+
+	distance, err := a.L2Distance(b)
+	checks.NoError(t, err, "L2Distance error")
+	if !approxEqual(distance, float32(math.Sqrt(14))) {
+		t.Fatalf("unexpected distance %v", distance)
+	}
+
+	_, err = openai.CosineSimilarity([]float32{1}, []float32{1, 2})
+	if !errors.Is(err, openai.ErrVectorLengthMismatch) {
+		t.Fatalf("expected ErrVectorLengthMismatch, got %v", err)
+	}
+	if _, err = openai.L2Distance([]float32{1}, nil); !errors.Is(err, openai.ErrVectorLengthMismatch) {
+		t.Fatalf("expected ErrVectorLengthMismatch, got %v", err)
+	}
+}
+
+func TestNormalizeAndTruncate(t *testing.T) {
+	v := []float32{3, 4, 12}
+	normalized := openai.Normalize(v)
+	if v[0] != 3 || !approxEqual(normalized[0], 3.0/13) || !approxEqual(normalized[2], 12.0/13) {
+		t.Fatalf("unexpected normalized vector %v of %v", normalized, v)
+	}
+
+	e := &openai.Embedding{Embedding: []float32{3, 4, 12}}
+	checks.NoError(t, e.Truncate(2), "Truncate error")
+	if !reflect.DeepEqual(e.Embedding, []float32{0.6, 0.8}) {
+		t.Fatalf("unexpected truncated embedding %v", e.Embedding)
+	}
+	dot, _ := e.DotProduct(e)
+	if !approxEqual(dot, 1) {
+		t.Fatalf("expected a unit vector, got norm %v", dot)
+	}
+
+	for _, dimensions := range []int{0, 4} {
+		if _, err := openai.TruncateEmbedding(v, dimensions); !errors.Is(err, openai.ErrEmbeddingDimensionsInvalid) {
+			t.Fatalf("expected ErrEmbeddingDimensionsInvalid for %d, got %v", dimensions, err)
+		}
+	}
+}
+
+func TestQuantizeInt8(t *testing.T) {
+	a := []float32{0.5, -0.25, 0.1, 0}
+	b := []float32{0.4, 0.3, -0.2, 0.1}
+	qa, qb := openai.QuantizeInt8(a), openai.QuantizeInt8(b)
+	if qa.Values[0] != 127 || qa.Values[1] != -64 {
+		t.Fatalf("unexpected quantized values %v", qa.Values)
+	}
+	for i, x := range qa.Dequantize() {
+		if math.Abs(float64(x-a[i])) > float64(qa.Scale) {
+			t.Fatalf("dequantized %v too far from %v", x, a[i])
+		}
+	}
+
+	want, _ := openai.CosineSimilarity(a, b)
+	got, err := qa.CosineSimilarity(qb)
+	checks.NoError(t, err, "CosineSimilarity error")
+	if math.Abs(float64(got-want)) > 0.01 {
+		t.Fatalf("expected approximate similarity %v, got %v", want, got)
+	}
+	wantDot := 0.5*0.4 - 0.25*0.3 - 0.1*0.2
+	if dot, _ := qa.DotProduct(qb); math.Abs(float64(dot)-wantDot) > 0.01 {
+		t.Fatalf("expected approximate dot product %v, got %v", wantDot, dot)
+	}
+	if zero := openai.QuantizeInt8([]float32{0, 0}); zero.Scale != 0 {
+		t.Fatalf("unexpected quantized zero vector %+v", zero)
+	}
+}
+
+func TestQuantizeBinary(t *testing.T) {
+	a := openai.QuantizeBinary([]float32{1, -1, 1, 1, -1, -1, 1, -1, 1})
+	b := openai.QuantizeBinary([]float32{1, 1, 1, 1, -1, -1, 1, -1, -1})
+	if fmt.Sprintf("%08b", a.Bits) != "[10110010 10000000]" || a.Dimensions != 9 {
+		t.Fatalf("unexpected bits %08b", a.Bits)
+	}
+
+	distance, err := openai.HammingDistance(a, b)
+	checks.NoError(t, err, "HammingDistance error")
+	if distance != 2 {
+		t.Fatalf("expected distance 2, got %d", distance)
+	}
+	if similarity, _ := a.Similarity(a); similarity != 1 {
+		t.Fatalf("expected identical vectors to score 1, got %v", similarity)
+	}
+	if _, err = a.Similarity(openai.QuantizeBinary([]float32{1})); !errors.Is(err, openai.ErrVectorLengthMismatch) {
+		t.Fatalf("expected ErrVectorLengthMismatch, got %v", err)
+	}
+}
+
+func TestEncodeEmbedding(t *testing.T) {
+	v := []float32{1.5, -2.25, float32(math.Pi)}
+	decoded, err := openai.DecodeEmbedding(openai.EncodeEmbedding(v))
+	checks.NoError(t, err, "DecodeEmbedding error")
+	if !reflect.DeepEqual(decoded, v) {
+		t.Fatalf("expected %v, got %v", v, decoded)
+	}
+	if _, err = openai.DecodeEmbedding([]byte{1, 2, 3}); !errors.Is(err, openai.ErrEmbeddingEncodingInvalid) {
+		t.Fatalf("expected ErrEmbeddingEncodingInvalid, got %v", err)
+	}
+
+	// The base64 form decodes like an embedding returned by the API.
+	data, _ := json.Marshal(map[string]any{
+		"data": []map[string]any{{"embedding": openai.EncodeEmbeddingBase64(v)}},
+	})
+	var response openai.EmbeddingResponseBase64
+	checks.NoError(t, json.Unmarshal(data, &response), "Unmarshal error")
+	converted, err := response.ToEmbeddingResponse()
+	checks.NoError(t, err, "ToEmbeddingResponse error")
+	if !reflect.DeepEqual(converted.Data[0].Embedding, v) {
+		t.Fatalf("expected %v, got %v", v, converted.Data[0].Embedding)
+	}
+}
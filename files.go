@@ -5,7 +5,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+
+	utils "github.com/sashabaranov/go-openai/internal"
 )
 
 type FileRequest struct {
@@ -57,28 +60,20 @@ type FilesList struct {
 }
 
 // CreateFileBytes uploads bytes directly to OpenAI without requiring a local file.
-func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest) (file File, err error) {
-	var b bytes.Buffer
-	reader := bytes.NewReader(request.Bytes)
-	builder := c.createFormBuilder(&b)
-
-	err = builder.WriteField("purpose", string(request.Purpose))
-	if err != nil {
-		return
-	}
-
-	err = builder.CreateFormFileReader("file", reader, request.Name)
-	if err != nil {
-		return
-	}
-
-	err = builder.Close()
-	if err != nil {
-		return
-	}
-
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"),
-		withBody(&b), withContentType(builder.FormDataContentType()))
+func (c *Client) CreateFileBytes(
+	ctx context.Context,
+	request FileBytesRequest,
+	opts ...RequestOption,
+) (file File, err error) {
+	// The bytes are read from a new reader on every pass, so nothing needs rewinding.
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		return fileMultipartForm(builder, string(request.Purpose), func() error {
+			return builder.CreateFormFileReader("file", bytes.NewReader(request.Bytes), request.Name)
+		})
+	}, func() error { return nil })
+	defer form.close()
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"), withBody(form), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -88,45 +83,48 @@ func (c *Client) CreateFileBytes(ctx context.Context, request FileBytesRequest)
 }
 
 // CreateFile uploads a jsonl file to GPT3
-// FilePath must be a local file path.
-func (c *Client) CreateFile(ctx context.Context, request FileRequest) (file File, err error) {
-	var b bytes.Buffer
-	builder := c.createFormBuilder(&b)
-
-	err = builder.WriteField("purpose", request.Purpose)
-	if err != nil {
-		return
-	}
-
+// FilePath must be a local file path. The file is streamed, not read into memory.
+func (c *Client) CreateFile(ctx context.Context, request FileRequest, opts ...RequestOption) (file File, err error) {
 	fileData, err := os.Open(request.FilePath)
 	if err != nil {
 		return
 	}
 	defer fileData.Close()
 
-	err = builder.CreateFormFile("file", fileData)
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		return fileMultipartForm(builder, request.Purpose, func() error {
+			return builder.CreateFormFile("file", fileData)
+		})
+	}, rewindReaders(fileData))
+	defer form.close()
+
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"), withBody(form), withOptions(opts...))
 	if err != nil {
 		return
 	}
 
-	err = builder.Close()
+	err = c.sendRequest(req, &file)
+	return
+}
+
+// fileMultipartForm writes the purpose field, the file added by createFile and closes the form.
+func fileMultipartForm(builder utils.FormBuilder, purpose string, createFile func() error) error {
+	err := builder.WriteField("purpose", purpose)
 	if err != nil {
-		return
+		return err
 	}
 
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/files"),
-		withBody(&b), withContentType(builder.FormDataContentType()))
+	err = createFile()
 	if err != nil {
-		return
+		return err
 	}
 
-	err = c.sendRequest(req, &file)
-	return
+	return builder.Close()
 }
 
 // DeleteFile deletes an existing file.
-func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
-	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/files/"+fileID))
+func (c *Client) DeleteFile(ctx context.Context, fileID string, opts ...RequestOption) (err error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/files/"+url.PathEscape(fileID)), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -137,8 +135,8 @@ func (c *Client) DeleteFile(ctx context.Context, fileID string) (err error) {
 
 // ListFiles Lists the currently available files,
 // and provides basic information about each file such as the file name and purpose.
-func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/files"))
+func (c *Client) ListFiles(ctx context.Context, opts ...RequestOption) (files FilesList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/files"), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -149,9 +147,9 @@ func (c *Client) ListFiles(ctx context.Context) (files FilesList, err error) {
 
 // GetFile Retrieves a file instance, providing basic information about the file
 // such as the file name and purpose.
-func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err error) {
-	urlSuffix := fmt.Sprintf("/files/%s", fileID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+func (c *Client) GetFile(ctx context.Context, fileID string, opts ...RequestOption) (file File, err error) {
+	urlSuffix := fmt.Sprintf("/files/%s", url.PathEscape(fileID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -160,9 +158,13 @@ func (c *Client) GetFile(ctx context.Context, fileID string) (file File, err err
 	return
 }
 
-func (c *Client) GetFileContent(ctx context.Context, fileID string) (content RawResponse, err error) {
-	urlSuffix := fmt.Sprintf("/files/%s/content", fileID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+func (c *Client) GetFileContent(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) (content RawResponse, err error) {
+	urlSuffix := fmt.Sprintf("/files/%s/content", url.PathEscape(fileID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
@@ -99,9 +100,13 @@ type FineTuneDeleteResponse struct {
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) CreateFineTune(ctx context.Context, request FineTuneRequest) (response FineTune, err error) {
+func (c *Client) CreateFineTune(
+	ctx context.Context,
+	request FineTuneRequest,
+	opts ...RequestOption,
+) (response FineTune, err error) {
 	urlSuffix := "/fine-tunes"
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -114,8 +119,12 @@ func (c *Client) CreateFineTune(ctx context.Context, request FineTuneRequest) (r
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) CancelFineTune(ctx context.Context, fineTuneID string) (response FineTune, err error) {
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/fine-tunes/"+fineTuneID+"/cancel")) //nolint:lll //this method is deprecated
+func (c *Client) CancelFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (response FineTune, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/fine-tunes/"+url.PathEscape(fineTuneID)+"/cancel"), withOptions(opts...)) //nolint:lll //this method is deprecated
 	if err != nil {
 		return
 	}
@@ -127,8 +136,8 @@ func (c *Client) CancelFineTune(ctx context.Context, fineTuneID string) (respons
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) ListFineTunes(ctx context.Context) (response FineTuneList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/fine-tunes"))
+func (c *Client) ListFineTunes(ctx context.Context, opts ...RequestOption) (response FineTuneList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/fine-tunes"), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -140,9 +149,13 @@ func (c *Client) ListFineTunes(ctx context.Context) (response FineTuneList, err
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) GetFineTune(ctx context.Context, fineTuneID string) (response FineTune, err error) {
-	urlSuffix := fmt.Sprintf("/fine-tunes/%s", fineTuneID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+func (c *Client) GetFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (response FineTune, err error) {
+	urlSuffix := fmt.Sprintf("/fine-tunes/%s", url.PathEscape(fineTuneID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -154,8 +167,13 @@ func (c *Client) GetFineTune(ctx context.Context, fineTuneID string) (response F
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) DeleteFineTune(ctx context.Context, fineTuneID string) (response FineTuneDeleteResponse, err error) {
-	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/fine-tunes/"+fineTuneID))
+func (c *Client) DeleteFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (response FineTuneDeleteResponse, err error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/fine-tunes/"+url.PathEscape(fineTuneID)),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -167,8 +185,13 @@ func (c *Client) DeleteFineTune(ctx context.Context, fineTuneID string) (respons
 // Deprecated: On August 22nd, 2023, OpenAI announced the deprecation of the /v1/fine-tunes API.
 // This API will be officially deprecated on January 4th, 2024.
 // OpenAI recommends to migrate to the new fine tuning API implemented in fine_tuning_job.go.
-func (c *Client) ListFineTuneEvents(ctx context.Context, fineTuneID string) (response FineTuneEventList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/fine-tunes/"+fineTuneID+"/events"))
+func (c *Client) ListFineTuneEvents(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (response FineTuneEventList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/fine-tunes/"+url.PathEscape(fineTuneID)+"/events"),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -40,13 +41,46 @@ type FineTuningJobRequest struct {
 }
 
 type FineTuningJobEventList struct {
-	Object  string          `json:"object"`
-	Data    []FineTuneEvent `json:"data"`
-	HasMore bool            `json:"has_more"`
+	Object string `json:"object"`
+	// Deprecated: Data lacks the ID, type and data of the events. Use Events instead.
+	Data []FineTuneEvent `json:"-"`
+	// Events are the events of the page, decoded from its data.
+	Events  []FineTuningJobEvent `json:"-"`
+	HasMore bool                 `json:"has_more"`
 
 	httpHeader
 }
 
+// fineTuningJobEventListJSON is the wire format of FineTuningJobEventList.
+type fineTuningJobEventListJSON struct {
+	Object  string               `json:"object"`
+	Data    []FineTuningJobEvent `json:"data"`
+	HasMore bool                 `json:"has_more"`
+}
+
+func (l FineTuningJobEventList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fineTuningJobEventListJSON{Object: l.Object, Data: l.Events, HasMore: l.HasMore})
+}
+
+// UnmarshalJSON decodes the events into Events, and into Data for callers of the deprecated field.
+func (l *FineTuningJobEventList) UnmarshalJSON(data []byte) error {
+	var list fineTuningJobEventListJSON
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	l.Object, l.Events, l.HasMore = list.Object, list.Data, list.HasMore
+	l.Data = make([]FineTuneEvent, len(list.Data))
+	for i, event := range list.Data {
+		l.Data[i] = FineTuneEvent{
+			Object:    event.Object,
+			CreatedAt: int64(event.CreatedAt),
+			Level:     event.Level,
+			Message:   event.Message,
+		}
+	}
+	return nil
+}
+
 type FineTuningJobEvent struct {
 	Object    string `json:"object"`
 	ID        string `json:"id"`
@@ -61,9 +95,10 @@ type FineTuningJobEvent struct {
 func (c *Client) CreateFineTuningJob(
 	ctx context.Context,
 	request FineTuningJobRequest,
+	opts ...RequestOption,
 ) (response FineTuningJob, err error) {
 	urlSuffix := "/fine_tuning/jobs"
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -73,8 +108,13 @@ func (c *Client) CreateFineTuningJob(
 }
 
 // CancelFineTuningJob cancel a fine tuning job.
-func (c *Client) CancelFineTuningJob(ctx context.Context, fineTuningJobID string) (response FineTuningJob, err error) {
-	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL("/fine_tuning/jobs/"+fineTuningJobID+"/cancel"))
+func (c *Client) CancelFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (response FineTuningJob, err error) {
+	urlSuffix := "/fine_tuning/jobs/" + url.PathEscape(fineTuningJobID) + "/cancel"
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -87,9 +127,10 @@ func (c *Client) CancelFineTuningJob(ctx context.Context, fineTuningJobID string
 func (c *Client) RetrieveFineTuningJob(
 	ctx context.Context,
 	fineTuningJobID string,
+	opts ...RequestOption,
 ) (response FineTuningJob, err error) {
-	urlSuffix := fmt.Sprintf("/fine_tuning/jobs/%s", fineTuningJobID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+	urlSuffix := fmt.Sprintf("/fine_tuning/jobs/%s", url.PathEscape(fineTuningJobID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -98,58 +139,15 @@ func (c *Client) RetrieveFineTuningJob(
 	return
 }
 
-type listFineTuningJobEventsParameters struct {
-	after *string
-	limit *int
-}
-
-type ListFineTuningJobEventsParameter func(*listFineTuningJobEventsParameters)
-
-func ListFineTuningJobEventsWithAfter(after string) ListFineTuningJobEventsParameter {
-	return func(args *listFineTuningJobEventsParameters) {
-		args.after = &after
-	}
-}
-
-func ListFineTuningJobEventsWithLimit(limit int) ListFineTuningJobEventsParameter {
-	return func(args *listFineTuningJobEventsParameters) {
-		args.limit = &limit
-	}
-}
-
-// ListFineTuningJobs list fine tuning jobs events.
+// ListFineTuningJobEvents lists the events of a fine tuning job.
 func (c *Client) ListFineTuningJobEvents(
 	ctx context.Context,
 	fineTuningJobID string,
-	setters ...ListFineTuningJobEventsParameter,
+	pagination Pagination,
+	opts ...RequestOption,
 ) (response FineTuningJobEventList, err error) {
-	parameters := &listFineTuningJobEventsParameters{
-		after: nil,
-		limit: nil,
-	}
-
-	for _, setter := range setters {
-		setter(parameters)
-	}
-
-	urlValues := url.Values{}
-	if parameters.after != nil {
-		urlValues.Add("after", *parameters.after)
-	}
-	if parameters.limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *parameters.limit))
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodGet,
-		c.fullURL("/fine_tuning/jobs/"+fineTuningJobID+"/events"+encodedValues),
-	)
+	urlSuffix := "/fine_tuning/jobs/" + url.PathEscape(fineTuningJobID) + "/events" + encodeQuery(pagination.values())
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
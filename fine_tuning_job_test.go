@@ -79,28 +79,44 @@ func TestFineTuningJob(t *testing.T) {
 	_, err = client.RetrieveFineTuningJob(ctx, testFineTuninigJobID)
 	checks.NoError(t, err, "RetrieveFineTuningJob error")
 
-	_, err = client.ListFineTuningJobEvents(ctx, testFineTuninigJobID)
+	_, err = client.ListFineTuningJobEvents(ctx, testFineTuninigJobID, openai.Pagination{})
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 
-	_, err = client.ListFineTuningJobEvents(
-		ctx,
-		testFineTuninigJobID,
-		openai.ListFineTuningJobEventsWithAfter("last-event-id"),
-	)
+	after := "last-event-id"
+	_, err = client.ListFineTuningJobEvents(ctx, testFineTuninigJobID, openai.Pagination{After: &after})
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 
-	_, err = client.ListFineTuningJobEvents(
-		ctx,
-		testFineTuninigJobID,
-		openai.ListFineTuningJobEventsWithLimit(10),
-	)
+	limit := 10
+	_, err = client.ListFineTuningJobEvents(ctx, testFineTuninigJobID, openai.Pagination{Limit: &limit})
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 
 	_, err = client.ListFineTuningJobEvents(
 		ctx,
 		testFineTuninigJobID,
-		openai.ListFineTuningJobEventsWithAfter("last-event-id"),
-		openai.ListFineTuningJobEventsWithLimit(10),
+		openai.Pagination{After: &after, Limit: &limit},
 	)
 	checks.NoError(t, err, "ListFineTuningJobEvents error")
 }
+
+func TestFineTuningJobEventListData(t *testing.T) {
+	var list openai.FineTuningJobEventList
+	err := json.Unmarshal([]byte(`{"object":"list","data":[{"object":"fine_tuning.job.event",`+
+		`"id":"ftevent-abc","created_at":1721764800,"level":"info","message":"Step 1/10","type":"metrics"}],`+
+		`"has_more":true}`), &list)
+	checks.NoError(t, err, "Unmarshal error")
+	if len(list.Events) != 1 || list.Events[0].ID != "ftevent-abc" || list.Events[0].Type != "metrics" {
+		t.Fatalf("unexpected events %+v", list.Events)
+	}
+	// The deprecated field keeps being filled.
+	if len(list.Data) != 1 || list.Data[0].CreatedAt != 1721764800 || list.Data[0].Message != "Step 1/10" {
+		t.Fatalf("unexpected data %+v", list.Data)
+	}
+
+	data, err := json.Marshal(list)
+	checks.NoError(t, err, "Marshal error")
+	var decoded openai.FineTuningJobEventList
+	checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+	if len(decoded.Events) != 1 || decoded.Events[0].ID != "ftevent-abc" || !decoded.HasMore {
+		t.Fatalf("expected the list to round-trip, got %s", data)
+	}
+}
@@ -1,11 +1,13 @@
 package openai
 
 import (
-	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
+
+	utils "github.com/sashabaranov/go-openai/internal"
 )
 
 // Image sizes defined by the OpenAI API.
@@ -116,13 +118,18 @@ type ImageResponseDataInner struct {
 }
 
 // CreateImage - API call to create an image. This is the main endpoint of the DALL-E API.
-func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (response ImageResponse, err error) {
+func (c *Client) CreateImage(
+	ctx context.Context,
+	request ImageRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
 	urlSuffix := "/images/generations"
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix, withModel(request.Model)),
 		withBody(request),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return
@@ -146,62 +153,71 @@ type ImageEditRequest struct {
 }
 
 // CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
-func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
+func (c *Client) CreateEditImage(
+	ctx context.Context,
+	request ImageEditRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
+	files := []io.Reader{request.Image}
+	if request.Mask != nil {
+		files = append(files, request.Mask)
+	}
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		return imageEditMultipartForm(request, builder)
+	}, rewindReaders(files...))
+	defer form.close()
 
-	// image
-	err = builder.CreateFormFile("image", request.Image)
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/edits", withModel(request.Model)),
+		withBody(form),
+		withOptions(opts...),
+	)
 	if err != nil {
 		return
 	}
 
+	err = c.sendRequest(req, &response)
+	return
+}
+
+func imageEditMultipartForm(request ImageEditRequest, builder utils.FormBuilder) error {
+	// image
+	err := builder.CreateFormFile("image", request.Image)
+	if err != nil {
+		return err
+	}
+
 	// mask, it is optional
 	if request.Mask != nil {
 		err = builder.CreateFormFile("mask", request.Mask)
 		if err != nil {
-			return
+			return err
 		}
 	}
 
 	err = builder.WriteField("prompt", request.Prompt)
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("n", strconv.Itoa(request.N))
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("size", request.Size)
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("response_format", request.ResponseFormat)
 	if err != nil {
-		return
-	}
-
-	err = builder.Close()
-	if err != nil {
-		return
-	}
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/edits", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
-	if err != nil {
-		return
+		return err
 	}
 
-	err = c.sendRequest(req, &response)
-	return
+	return builder.Close()
 }
 
 // ImageVariRequest represents the request structure for the image API.
@@ -216,47 +232,52 @@ type ImageVariRequest struct {
 
 // CreateVariImage - API call to create an image variation. This is the main endpoint of the DALL-E API.
 // Use abbreviations(vari for variation) because ci-lint has a single-line length limit ...
-func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
+func (c *Client) CreateVariImage(
+	ctx context.Context,
+	request ImageVariRequest,
+	opts ...RequestOption,
+) (response ImageResponse, err error) {
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		return imageVariMultipartForm(request, builder)
+	}, rewindReaders(request.Image))
+	defer form.close()
 
-	// image
-	err = builder.CreateFormFile("image", request.Image)
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/variations", withModel(request.Model)),
+		withBody(form),
+		withOptions(opts...),
+	)
 	if err != nil {
 		return
 	}
 
-	err = builder.WriteField("n", strconv.Itoa(request.N))
-	if err != nil {
-		return
-	}
+	err = c.sendRequest(req, &response)
+	return
+}
 
-	err = builder.WriteField("size", request.Size)
+func imageVariMultipartForm(request ImageVariRequest, builder utils.FormBuilder) error {
+	// image
+	err := builder.CreateFormFile("image", request.Image)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = builder.WriteField("response_format", request.ResponseFormat)
+	err = builder.WriteField("n", strconv.Itoa(request.N))
 	if err != nil {
-		return
+		return err
 	}
 
-	err = builder.Close()
+	err = builder.WriteField("size", request.Size)
 	if err != nil {
-		return
+		return err
 	}
 
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/variations", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	err = builder.WriteField("response_format", request.ResponseFormat)
 	if err != nil {
-		return
+		return err
 	}
 
-	err = c.sendRequest(req, &response)
-	return
+	return builder.Close()
 }
@@ -63,3 +63,105 @@ func (fb *DefaultFormBuilder) Close() error {
 func (fb *DefaultFormBuilder) FormDataContentType() string {
 	return fb.writer.FormDataContentType()
 }
+
+// SetBoundary replaces the random boundary, so a form can be written again
+// with the Content-Type it was first sent with.
+func (fb *DefaultFormBuilder) SetBoundary(boundary string) error {
+	return fb.writer.SetBoundary(boundary)
+}
+
+// FormSizer is a FormBuilder computing the length of the form a
+// DefaultFormBuilder with the same boundary writes, without reading files.
+type FormSizer struct {
+	writer  *multipart.Writer
+	counter countingWriter
+	files   int64
+	unknown bool
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func NewFormSizer(boundary string) (*FormSizer, error) {
+	s := &FormSizer{}
+	s.writer = multipart.NewWriter(&s.counter)
+	if err := s.writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FormSizer) CreateFormFile(fieldname string, file *os.File) error {
+	if file == nil {
+		s.unknown = true
+		return nil
+	}
+	return s.createFormFile(fieldname, file, file.Name())
+}
+
+func (s *FormSizer) CreateFormFileReader(fieldname string, r io.Reader, filename string) error {
+	return s.createFormFile(fieldname, r, path.Base(filename))
+}
+
+func (s *FormSizer) createFormFile(fieldname string, r io.Reader, filename string) error {
+	if filename == "" {
+		return fmt.Errorf("filename cannot be empty")
+	}
+	if _, err := s.writer.CreateFormFile(fieldname, filename); err != nil {
+		return err
+	}
+	size, ok := ReaderSize(r)
+	if !ok {
+		s.unknown = true
+	}
+	s.files += size
+	return nil
+}
+
+func (s *FormSizer) WriteField(fieldname, value string) error {
+	return s.writer.WriteField(fieldname, value)
+}
+
+func (s *FormSizer) Close() error {
+	return s.writer.Close()
+}
+
+func (s *FormSizer) FormDataContentType() string {
+	return s.writer.FormDataContentType()
+}
+
+// Size returns the length of the form once closed, and whether the size of
+// every file was known.
+func (s *FormSizer) Size() (int64, bool) {
+	return s.counter.n + s.files, !s.unknown
+}
+
+// ReaderSize returns the number of bytes left to read from r, if it can be
+// known without reading: r has a Len method or is an io.Seeker.
+func ReaderSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case io.Seeker:
+		current, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, false
+		}
+		if _, err = v.Seek(current, io.SeekStart); err != nil {
+			return 0, false
+		}
+		return end - current, true
+	default:
+		return 0, false
+	}
+}
@@ -43,3 +43,49 @@ func TestFormBuilderWithClosedFile(t *testing.T) {
 	checks.HasError(t, err, "formbuilder should return error if file is closed")
 	checks.ErrorIs(t, err, os.ErrClosed, "formbuilder should return error if file is closed")
 }
+
+func TestFormSizerMatchesFormBuilder(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "*.jsonl")
+	if err != nil {
+		t.Fatalf("Error creating tmp file: %v", err)
+	}
+	defer file.Close()
+	_, err = file.WriteString(`{"prompt":"a"}`)
+	checks.NoError(t, err, "WriteString error")
+	_, err = file.Seek(0, 0)
+	checks.NoError(t, err, "Seek error")
+
+	write := func(builder FormBuilder) {
+		checks.NoError(t, builder.WriteField("purpose", "fine-tune"), "WriteField error")
+		checks.NoError(t, builder.CreateFormFile("file", file), "CreateFormFile error")
+		checks.NoError(t, builder.CreateFormFileReader("mask", bytes.NewReader([]byte("mask")), "dir/m.png"),
+			"CreateFormFileReader error")
+		checks.NoError(t, builder.Close(), "Close error")
+	}
+
+	body := &bytes.Buffer{}
+	builder := NewFormBuilder(body)
+	checks.NoError(t, builder.SetBoundary("0123456789"), "SetBoundary error")
+	sizer, err := NewFormSizer("0123456789")
+	checks.NoError(t, err, "NewFormSizer error")
+	write(sizer)
+	write(builder)
+
+	size, ok := sizer.Size()
+	if !ok || size != int64(body.Len()) {
+		t.Fatalf("expected size %d, got %d (known %v)", body.Len(), size, ok)
+	}
+
+	unknown, err := NewFormSizer("0123456789")
+	checks.NoError(t, err, "NewFormSizer error")
+	checks.NoError(t, unknown.CreateFormFileReader("file", &failingReader{}, "f"), "CreateFormFileReader error")
+	if _, ok = unknown.Size(); ok {
+		t.Fatal("expected the size of a plain reader to be unknown")
+	}
+}
+
+type failingReader struct{}
+
+func (*failingReader) Read([]byte) (int, error) {
+	return 0, errMockFailingWriterError
+}
@@ -0,0 +1,259 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type MessageAnnotationType string
+
+const (
+	// MessageAnnotationTypeFileCitation marks text quoted from a file found by file_search.
+	MessageAnnotationTypeFileCitation MessageAnnotationType = "file_citation"
+	// MessageAnnotationTypeFilePath marks a link to a file generated by code_interpreter.
+	MessageAnnotationTypeFilePath MessageAnnotationType = "file_path"
+)
+
+// MessageAnnotation points at the part of MessageText.Value between
+// StartIndex and EndIndex, which holds Text. Only the field matching Type is set.
+type MessageAnnotation struct {
+	Type MessageAnnotationType `json:"type"`
+	// Text is the marker in the message, e.g. "【4:0†source】" or "sandbox:/mnt/data/out.csv".
+	Text         string               `json:"text"`
+	StartIndex   int                  `json:"start_index"`
+	EndIndex     int                  `json:"end_index"`
+	FileCitation *MessageFileCitation `json:"file_citation,omitempty"`
+	FilePath     *MessageFilePath     `json:"file_path,omitempty"`
+}
+
+type MessageFileCitation struct {
+	FileID string `json:"file_id"`
+	Quote  string `json:"quote,omitempty"`
+}
+
+type MessageFilePath struct {
+	FileID string `json:"file_id"`
+}
+
+// FileID returns the ID of the cited or generated file.
+func (a MessageAnnotation) FileID() string {
+	switch {
+	case a.FileCitation != nil:
+		return a.FileCitation.FileID
+	case a.FilePath != nil:
+		return a.FilePath.FileID
+	default:
+		return ""
+	}
+}
+
+// OutputFileIDs returns the IDs of the files generated for the message, i.e.
+// file_path annotations and image_file contents. Their bytes can be
+// downloaded with Client.GetFileContent.
+func (m Message) OutputFileIDs() []string {
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, content := range m.Content {
+		if content.ImageFile != nil {
+			add(content.ImageFile.FileID)
+		}
+		if content.Text == nil {
+			continue
+		}
+		for _, annotation := range content.Text.Annotations {
+			if annotation.Type == MessageAnnotationTypeFilePath {
+				add(annotation.FileID())
+			}
+		}
+	}
+	return ids
+}
+
+// FileGetter retrieves file metadata. It is implemented by Client and Router.
+type FileGetter interface {
+	GetFile(ctx context.Context, fileID string, opts ...RequestOption) (File, error)
+}
+
+type CitationStyle int
+
+const (
+	// CitationStyleFootnotes replaces citation markers with "[1]", "[2]", ...
+	CitationStyleFootnotes CitationStyle = iota
+	// CitationStyleLinks replaces citation markers with Markdown links to the
+	// cited file, built with CitationOptions.FileURL.
+	CitationStyleLinks
+)
+
+type CitationOptions struct {
+	Style CitationStyle
+	// FileURL returns the link to a file. It is used for CitationStyleLinks
+	// and to replace the sandbox paths of generated files. Without it,
+	// citations are rendered as footnotes and sandbox paths are kept.
+	FileURL func(file File) string
+}
+
+// MessageCitation is a numbered file cited by a message.
+type MessageCitation struct {
+	Number int
+	File   File
+	// Quotes are the quotes of every citation of File, if the API returned them.
+	Quotes []string
+}
+
+// RenderedMessageText is MessageText.Value with its annotations rendered.
+type RenderedMessageText struct {
+	Text      string
+	Citations []MessageCitation
+	// Files are the files generated by code_interpreter and linked from the text.
+	Files []File
+}
+
+// Footnotes returns the citations as "[1] name" lines.
+func (r RenderedMessageText) Footnotes() string {
+	var b strings.Builder
+	for _, citation := range r.Citations {
+		fmt.Fprintf(&b, "[%d] %s\n", citation.Number, citation.File.FileName)
+	}
+	return b.String()
+}
+
+// annotationSpan is an annotation located in the runes of MessageText.Value.
+type annotationSpan struct {
+	start, end int
+	annotation MessageAnnotation
+}
+
+// spans locates every annotation. StartIndex and EndIndex count characters,
+// so they are checked against Text and, when they don't match, Text is
+// searched for instead.
+func (t MessageText) spans() []annotationSpan {
+	runes := []rune(t.Value)
+	spans := make([]annotationSpan, 0, len(t.Annotations))
+	// searchFrom is the byte offset the next search starts at, so repeated
+	// markers are matched in order.
+	searchFrom := 0
+	for _, annotation := range t.Annotations {
+		start, end := annotation.StartIndex, annotation.EndIndex
+		if start < 0 || end > len(runes) || start >= end || string(runes[start:end]) != annotation.Text {
+			index := strings.Index(t.Value[searchFrom:], annotation.Text)
+			if annotation.Text == "" || index < 0 {
+				continue
+			}
+			index += searchFrom
+			start = len([]rune(t.Value[:index]))
+			end = start + len([]rune(annotation.Text))
+		}
+		if matched := len(string(runes[:end])); matched > searchFrom {
+			searchFrom = matched
+		}
+		spans = append(spans, annotationSpan{start: start, end: end, annotation: annotation})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// citationRenderer numbers cited files and resolves each file once.
+type citationRenderer struct {
+	ctx      context.Context
+	files    FileGetter
+	options  CitationOptions
+	resolved map[string]File
+	numbers  map[string]int
+	linked   map[string]bool
+	rendered RenderedMessageText
+}
+
+func (r *citationRenderer) getFile(fileID string) (File, error) {
+	if file, ok := r.resolved[fileID]; ok {
+		return file, nil
+	}
+	file, err := r.files.GetFile(r.ctx, fileID)
+	if err != nil {
+		return File{}, fmt.Errorf("resolving cited file %s: %w", fileID, err)
+	}
+	r.resolved[fileID] = file
+	return file, nil
+}
+
+// replacement returns the text which replaces the marker of annotation.
+func (r *citationRenderer) replacement(annotation MessageAnnotation) (string, error) {
+	fileID := annotation.FileID()
+	if fileID == "" {
+		return annotation.Text, nil
+	}
+	file, err := r.getFile(fileID)
+	if err != nil {
+		return "", err
+	}
+
+	switch annotation.Type {
+	case MessageAnnotationTypeFileCitation:
+		number, ok := r.numbers[fileID]
+		if !ok {
+			number = len(r.rendered.Citations) + 1
+			r.numbers[fileID] = number
+			r.rendered.Citations = append(r.rendered.Citations, MessageCitation{Number: number, File: file})
+		}
+		if citation := annotation.FileCitation; citation != nil && citation.Quote != "" {
+			r.rendered.Citations[number-1].Quotes = append(r.rendered.Citations[number-1].Quotes, citation.Quote)
+		}
+		if r.options.Style == CitationStyleLinks && r.options.FileURL != nil {
+			return fmt.Sprintf("[%s](%s)", file.FileName, r.options.FileURL(file)), nil
+		}
+		return fmt.Sprintf("[%d]", number), nil
+	case MessageAnnotationTypeFilePath:
+		if !r.linked[fileID] {
+			r.linked[fileID] = true
+			r.rendered.Files = append(r.rendered.Files, file)
+		}
+		if r.options.FileURL != nil {
+			return r.options.FileURL(file), nil
+		}
+	}
+	return annotation.Text, nil
+}
+
+// RenderCitations replaces the annotation markers in Value with numbered
+// footnotes or links, resolving file names with files.GetFile. Every file is
+// looked up once and keeps the same number for all its citations.
+func (t MessageText) RenderCitations(
+	ctx context.Context,
+	files FileGetter,
+	options CitationOptions,
+) (RenderedMessageText, error) {
+	r := &citationRenderer{
+		ctx:      ctx,
+		files:    files,
+		options:  options,
+		resolved: map[string]File{},
+		numbers:  map[string]int{},
+		linked:   map[string]bool{},
+	}
+
+	runes := []rune(t.Value)
+	var b strings.Builder
+	last := 0
+	for _, span := range t.spans() {
+		if span.start < last {
+			continue // overlapping annotations keep the first one
+		}
+		replacement, err := r.replacement(span.annotation)
+		if err != nil {
+			return RenderedMessageText{}, err
+		}
+		b.WriteString(string(runes[last:span.start]))
+		b.WriteString(replacement)
+		last = span.end
+	}
+	b.WriteString(string(runes[last:]))
+	r.rendered.Text = b.String()
+	return r.rendered, nil
+}
@@ -0,0 +1,164 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type fakeFileGetter struct {
+	names map[string]string
+	calls map[string]int
+}
+
+func (f *fakeFileGetter) GetFile(_ context.Context, fileID string, _ ...openai.RequestOption) (openai.File, error) {
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[fileID]++
+	name, ok := f.names[fileID]
+	if !ok {
+		return openai.File{}, errors.New("no such file")
+	}
+	return openai.File{ID: fileID, FileName: name}, nil
+}
+
+// annotatedMessage cites file_a twice and file_b once, and links a generated file.
+// The indices count characters, so they differ from byte offsets after the first
+// marker; the third citation has no indices and is found by its text.
+const annotatedMessage = `{
+	"id": "msg_1",
+	"role": "assistant",
+	"content": [{
+		"type": "text",
+		"text": {
+			"value": "Warranty 2y【4:0†source】, returns 30d【4:1†source】【4:2†source】. See sandbox:/mnt/data/r.csv",
+			"annotations": [
+				{"type": "file_citation", "text": "【4:0†source】", "start_index": 11, "end_index": 23,
+					"file_citation": {"file_id": "file_a", "quote": "two year warranty"}},
+				{"type": "file_citation", "text": "【4:1†source】", "start_index": 36, "end_index": 48,
+					"file_citation": {"file_id": "file_b"}},
+				{"type": "file_citation", "text": "【4:2†source】", "start_index": 0, "end_index": 0,
+					"file_citation": {"file_id": "file_a"}},
+				{"type": "file_path", "text": "sandbox:/mnt/data/r.csv", "start_index": 66, "end_index": 89,
+					"file_path": {"file_id": "file_csv"}}
+			]
+		}
+	}, {
+		"type": "image_file",
+		"image_file": {"file_id": "file_png"}
+	}]
+}`
+
+func newAnnotatedFiles() *fakeFileGetter {
+	return &fakeFileGetter{names: map[string]string{
+		"file_a":   "warranty.md",
+		"file_b":   "returns.md",
+		"file_csv": "report.csv",
+	}}
+}
+
+func decodeAnnotatedMessage(t *testing.T) openai.Message {
+	t.Helper()
+	var msg openai.Message
+	checks.NoError(t, json.Unmarshal([]byte(annotatedMessage), &msg), "Unmarshal error")
+	return msg
+}
+
+func TestMessageAnnotationsDecode(t *testing.T) {
+	msg := decodeAnnotatedMessage(t)
+	annotations := msg.Content[0].Text.Annotations
+	if len(annotations) != 4 {
+		t.Fatalf("expected 4 annotations, got %d", len(annotations))
+	}
+	first := annotations[0]
+	if first.Type != openai.MessageAnnotationTypeFileCitation || first.FileCitation.Quote != "two year warranty" ||
+		first.StartIndex != 11 || first.EndIndex != 23 {
+		t.Fatalf("unexpected file citation %+v", first)
+	}
+	if annotations[3].FilePath == nil || annotations[3].FileID() != "file_csv" {
+		t.Fatalf("unexpected file path %+v", annotations[3])
+	}
+	if ids := msg.OutputFileIDs(); !reflect.DeepEqual(ids, []string{"file_csv", "file_png"}) {
+		t.Fatalf("unexpected output files %v", ids)
+	}
+}
+
+func TestRenderCitationsFootnotes(t *testing.T) {
+	msg := decodeAnnotatedMessage(t)
+	files := newAnnotatedFiles()
+
+	rendered, err := msg.Content[0].Text.RenderCitations(context.Background(), files, openai.CitationOptions{})
+	checks.NoError(t, err, "RenderCitations error")
+
+	want := "Warranty 2y[1], returns 30d[2][1]. See sandbox:/mnt/data/r.csv"
+	if rendered.Text != want {
+		t.Fatalf("expected %q, got %q", want, rendered.Text)
+	}
+	if rendered.Footnotes() != "[1] warranty.md\n[2] returns.md\n" {
+		t.Fatalf("unexpected footnotes %q", rendered.Footnotes())
+	}
+	if quotes := rendered.Citations[0].Quotes; !reflect.DeepEqual(quotes, []string{"two year warranty"}) {
+		t.Fatalf("unexpected quotes %v", quotes)
+	}
+	if len(rendered.Files) != 1 || rendered.Files[0].FileName != "report.csv" {
+		t.Fatalf("unexpected files %+v", rendered.Files)
+	}
+	if files.calls["file_a"] != 1 {
+		t.Fatalf("expected file_a to be resolved once, got %d", files.calls["file_a"])
+	}
+}
+
+func TestRenderCitationsLinks(t *testing.T) {
+	msg := decodeAnnotatedMessage(t)
+	files := newAnnotatedFiles()
+
+	rendered, err := msg.Content[0].Text.RenderCitations(context.Background(), files, openai.CitationOptions{
+		Style:   openai.CitationStyleLinks,
+		FileURL: func(file openai.File) string { return "https://files.example.com/" + file.ID },
+	})
+	checks.NoError(t, err, "RenderCitations error")
+
+	want := "Warranty 2y[warranty.md](https://files.example.com/file_a), " +
+		"returns 30d[returns.md](https://files.example.com/file_b)[warranty.md](https://files.example.com/file_a). " +
+		"See https://files.example.com/file_csv"
+	if rendered.Text != want {
+		t.Fatalf("expected %q, got %q", want, rendered.Text)
+	}
+}
+
+func TestRenderCitationsUnknownFile(t *testing.T) {
+	msg := decodeAnnotatedMessage(t)
+	_, err := msg.Content[0].Text.RenderCitations(context.Background(), &fakeFileGetter{}, openai.CitationOptions{})
+	if err == nil {
+		t.Fatal("expected an error for a file which can't be resolved")
+	}
+}
+
+func TestRenderCitationsRepeatedMarkers(t *testing.T) {
+	// The second marker has the text of the first and no indices, so it is
+	// found by searching after the first one.
+	text := openai.MessageText{
+		Value: "Warranty【4:0†source】, returns【4:0†source】",
+		Annotations: []openai.MessageAnnotation{
+			{
+				Type: openai.MessageAnnotationTypeFileCitation, Text: "【4:0†source】", StartIndex: 8, EndIndex: 20,
+				FileCitation: &openai.MessageFileCitation{FileID: "file_a"},
+			},
+			{
+				Type: openai.MessageAnnotationTypeFileCitation, Text: "【4:0†source】",
+				FileCitation: &openai.MessageFileCitation{FileID: "file_b"},
+			},
+		},
+	}
+	rendered, err := text.RenderCitations(context.Background(), newAnnotatedFiles(), openai.CitationOptions{})
+	checks.NoError(t, err, "RenderCitations error")
+	if want := "Warranty[1], returns[2]"; rendered.Text != want {
+		t.Fatalf("expected %q, got %q", want, rendered.Text)
+	}
+}
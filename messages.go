@@ -44,8 +44,8 @@ type MessageContent struct {
 	ImageURL  *ImageURL    `json:"image_url,omitempty"`
 }
 type MessageText struct {
-	Value       string `json:"value"`
-	Annotations []any  `json:"annotations"`
+	Value       string              `json:"value"`
+	Annotations []MessageAnnotation `json:"annotations"`
 }
 
 type ImageFile struct {
@@ -89,10 +89,16 @@ type MessageDeletionStatus struct {
 }
 
 // CreateMessage creates a new message.
-func (c *Client) CreateMessage(ctx context.Context, threadID string, request MessageRequest) (msg Message, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s", threadID, messagesSuffix)
+func (c *Client) CreateMessage(
+	ctx context.Context,
+	threadID string,
+	request MessageRequest,
+	opts ...RequestOption,
+) (msg Message, err error) {
+	urlSuffix := fmt.Sprintf("/threads/%s/%s", url.PathEscape(threadID), messagesSuffix)
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -101,39 +107,29 @@ func (c *Client) CreateMessage(ctx context.Context, threadID string, request Mes
 	return
 }
 
+// ListMessageOptions filters the messages returned by ListMessage.
+type ListMessageOptions struct {
+	Pagination
+	// RunID only returns the messages created by this run.
+	RunID *string
+}
+
 // ListMessage fetches all messages in the thread.
-func (c *Client) ListMessage(ctx context.Context, threadID string,
-	limit *int,
-	order *string,
-	after *string,
-	before *string,
-	runID *string,
+func (c *Client) ListMessage(
+	ctx context.Context,
+	threadID string,
+	options ListMessageOptions,
+	opts ...RequestOption,
 ) (messages MessagesList, err error) {
-	urlValues := url.Values{}
-	if limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *limit))
-	}
-	if order != nil {
-		urlValues.Add("order", *order)
-	}
-	if after != nil {
-		urlValues.Add("after", *after)
-	}
-	if before != nil {
-		urlValues.Add("before", *before)
-	}
-	if runID != nil {
-		urlValues.Add("run_id", *runID)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
+	urlValues := options.Pagination.values()
+	if options.RunID != nil {
+		urlValues.Add("run_id", *options.RunID)
 	}
 
-	urlSuffix := fmt.Sprintf("/threads/%s/%s%s", threadID, messagesSuffix, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s%s", url.PathEscape(threadID), messagesSuffix, encodeQuery(urlValues))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -146,10 +142,12 @@ func (c *Client) ListMessage(ctx context.Context, threadID string,
 func (c *Client) RetrieveMessage(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (msg Message, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", url.PathEscape(threadID), messagesSuffix, url.PathEscape(messageID))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -163,10 +161,12 @@ func (c *Client) ModifyMessage(
 	ctx context.Context,
 	threadID, messageID string,
 	metadata map[string]string,
+	opts ...RequestOption,
 ) (msg Message, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", url.PathEscape(threadID), messagesSuffix, url.PathEscape(messageID))
 	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
-		withBody(map[string]any{"metadata": metadata}), withBetaAssistantVersion(c.config.AssistantVersion))
+		withBody(map[string]any{"metadata": metadata}), withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -179,10 +179,13 @@ func (c *Client) ModifyMessage(
 func (c *Client) RetrieveMessageFile(
 	ctx context.Context,
 	threadID, messageID, fileID string,
+	opts ...RequestOption,
 ) (file MessageFile, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files/%s", threadID, messagesSuffix, messageID, fileID)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files/%s",
+		url.PathEscape(threadID), messagesSuffix, url.PathEscape(messageID), url.PathEscape(fileID))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -195,10 +198,13 @@ func (c *Client) RetrieveMessageFile(
 func (c *Client) ListMessageFiles(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (files MessageFilesList, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files", threadID, messagesSuffix, messageID)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s/files",
+		url.PathEscape(threadID), messagesSuffix, url.PathEscape(messageID))
 	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -211,10 +217,12 @@ func (c *Client) ListMessageFiles(
 func (c *Client) DeleteMessage(
 	ctx context.Context,
 	threadID, messageID string,
+	opts ...RequestOption,
 ) (status MessageDeletionStatus, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", threadID, messagesSuffix, messageID)
+	urlSuffix := fmt.Sprintf("/threads/%s/%s/%s", url.PathEscape(threadID), messagesSuffix, url.PathEscape(messageID))
 	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -208,7 +208,7 @@ func TestMessages(t *testing.T) {
 	}
 
 	var msgs openai.MessagesList
-	msgs, err = client.ListMessage(ctx, threadID, nil, nil, nil, nil, nil)
+	msgs, err = client.ListMessage(ctx, threadID, openai.ListMessageOptions{})
 	checks.NoError(t, err, "ListMessages error")
 	if len(msgs.Messages) != 1 {
 		t.Fatalf("unexpected length of fetched messages")
@@ -220,7 +220,10 @@ func TestMessages(t *testing.T) {
 	after := "obj_foo"
 	before := "obj_bar"
 	runID := "run_abc123"
-	msgs, err = client.ListMessage(ctx, threadID, &limit, &order, &after, &before, &runID)
+	msgs, err = client.ListMessage(ctx, threadID, openai.ListMessageOptions{
+		Pagination: openai.Pagination{Limit: &limit, Order: &order, After: &after, Before: &before},
+		RunID:      &runID,
+	})
 	checks.NoError(t, err, "ListMessages error")
 	if len(msgs.Messages) != 1 {
 		t.Fatalf("unexpected length of fetched messages")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // Model struct represents an OpenAPI model.
@@ -53,8 +54,8 @@ type ModelsList struct {
 
 // ListModels Lists the currently available models,
 // and provides basic information about each model such as the model id and parent.
-func (c *Client) ListModels(ctx context.Context) (models ModelsList, err error) {
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/models"))
+func (c *Client) ListModels(ctx context.Context, opts ...RequestOption) (models ModelsList, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL("/models"), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -65,9 +66,9 @@ func (c *Client) ListModels(ctx context.Context) (models ModelsList, err error)
 
 // GetModel Retrieves a model instance, providing basic information about
 // the model such as the owner and permissioning.
-func (c *Client) GetModel(ctx context.Context, modelID string) (model Model, err error) {
-	urlSuffix := fmt.Sprintf("/models/%s", modelID)
-	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix))
+func (c *Client) GetModel(ctx context.Context, modelID string, opts ...RequestOption) (model Model, err error) {
+	urlSuffix := fmt.Sprintf("/models/%s", url.PathEscape(modelID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -78,9 +79,9 @@ func (c *Client) GetModel(ctx context.Context, modelID string) (model Model, err
 
 // DeleteFineTuneModel Deletes a fine-tune model. You must have the Owner
 // role in your organization to delete a model.
-func (c *Client) DeleteFineTuneModel(ctx context.Context, modelID string) (
+func (c *Client) DeleteFineTuneModel(ctx context.Context, modelID string, opts ...RequestOption) (
 	response FineTuneModelDeleteResponse, err error) {
-	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/models/"+modelID))
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL("/models/"+url.PathEscape(modelID)), withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -73,6 +73,30 @@ func TestAzureGetModel(t *testing.T) {
 	checks.NoError(t, err, "GetModel error")
 }
 
+func TestGetModelEscapesID(t *testing.T) {
+	const modelID = "ft:abc%zz"
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/v1/models/ft:abc%25zz" && r.URL.EscapedPath() != "/openai/models/ft:abc%25zz" {
+			http.Error(w, "unexpected path "+r.URL.EscapedPath(), http.StatusNotFound)
+			return
+		}
+		handleGetModelEndpoint(w, r)
+	}
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/models/*", handler)
+	_, err := client.GetModel(context.Background(), modelID)
+	checks.NoError(t, err, "GetModel error")
+
+	// Azure adds the api-version to the escaped path.
+	azureClient, azureServer, azureTeardown := setupAzureTestServer()
+	defer azureTeardown()
+	azureServer.RegisterHandler("/openai/models/*", handler)
+	_, err = azureClient.GetModel(context.Background(), modelID)
+	checks.NoError(t, err, "GetModel error")
+}
+
 // handleGetModelsEndpoint Handles the get model endpoint by the test server.
 func handleGetModelEndpoint(w http.ResponseWriter, _ *http.Request) {
 	resBytes, _ := json.Marshal(openai.Model{})
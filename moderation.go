@@ -87,7 +87,11 @@ type ModerationResponse struct {
 
 // Moderations — perform a moderation api call over a string.
 // Input can be an array or slice but a string will reduce the complexity.
-func (c *Client) Moderations(ctx context.Context, request ModerationRequest) (response ModerationResponse, err error) {
+func (c *Client) Moderations(
+	ctx context.Context,
+	request ModerationRequest,
+	opts ...RequestOption,
+) (response ModerationResponse, err error) {
 	if _, ok := validModerationModel[request.Model]; len(request.Model) > 0 && !ok {
 		err = ErrModerationInvalidModel
 		return
@@ -97,6 +101,7 @@ func (c *Client) Moderations(ctx context.Context, request ModerationRequest) (re
 		http.MethodPost,
 		c.fullURL("/moderations", withModel(request.Model)),
 		withBody(&request),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return
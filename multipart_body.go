@@ -0,0 +1,222 @@
+package openai
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+// UploadProgressFunc is called as a request body is sent with the bytes sent
+// so far and the length of the body, or -1 when it isn't known in advance.
+// When the body is sent again, e.g. after a redirect, sent starts over at 0.
+type UploadProgressFunc func(sent, total int64)
+
+// WithUploadProgress reports the progress of sending the request body.
+func WithUploadProgress(progress UploadProgressFunc) RequestOption {
+	return func(args *requestOptions) {
+		args.uploadProgress = progress
+	}
+}
+
+// multipartForm is a request body streamed through a pipe as write fills a
+// FormBuilder, so files are never held in memory.
+type multipartForm struct {
+	newBuilder func(io.Writer) utils.FormBuilder
+	// write adds the fields of the form to builder and closes it.
+	write func(builder utils.FormBuilder) error
+	// rewind prepares the readers used by write to be read again. It is nil
+	// when they can't be, and the body can't be sent twice.
+	rewind func() error
+
+	contentType string
+	boundary    string
+	// length is the length of the body, or -1 if it isn't known.
+	length int64
+
+	mu      sync.Mutex
+	current *formPipe
+}
+
+// newMultipartForm returns a form body for write. rewind is usually built
+// with rewindReaders from the readers write consumes.
+func (c *Client) newMultipartForm(write func(utils.FormBuilder) error, rewind func() error) *multipartForm {
+	return &multipartForm{newBuilder: c.createFormBuilder, write: write, rewind: rewind, length: -1}
+}
+
+// formPipe is one pass of writing a multipartForm.
+type formPipe struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// startWriter signals the first write to the pipe.
+type startWriter struct {
+	io.Writer
+	once    sync.Once
+	started chan struct{}
+}
+
+func (w *startWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	return w.Writer.Write(p)
+}
+
+// open starts writing the form and returns the body to read it from. It
+// waits until the form is being written, so errors raised before anything
+// was written, like a file which can't be opened, are returned here.
+func (f *multipartForm) open() (*formPipe, error) {
+	reader, writer := io.Pipe()
+	started := &startWriter{Writer: writer, started: make(chan struct{})}
+	builder := f.newBuilder(started)
+	if f.boundary == "" {
+		f.measure(builder)
+	} else if b, ok := builder.(interface{ SetBoundary(string) error }); ok {
+		if err := b.SetBoundary(f.boundary); err != nil {
+			return nil, err
+		}
+	}
+
+	body := &formPipe{PipeReader: reader, done: make(chan struct{})}
+	result := make(chan error, 1)
+	go func() {
+		defer close(body.done)
+		err := f.write(builder)
+		result <- err
+		writer.CloseWithError(err)
+	}()
+
+	select {
+	case <-started.started:
+	case err := <-result:
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+	}
+	f.mu.Lock()
+	f.current = body
+	f.mu.Unlock()
+	return body, nil
+}
+
+// measure records the Content-Type of builder and, for a DefaultFormBuilder
+// whose files have known sizes, the length of the body.
+func (f *multipartForm) measure(builder utils.FormBuilder) {
+	f.contentType = builder.FormDataContentType()
+	_, params, err := mime.ParseMediaType(f.contentType)
+	if err != nil || params["boundary"] == "" {
+		return
+	}
+	f.boundary = params["boundary"]
+	if _, ok := builder.(*utils.DefaultFormBuilder); !ok {
+		return
+	}
+	sizer, err := utils.NewFormSizer(f.boundary)
+	if err != nil || f.write(sizer) != nil {
+		return
+	}
+	if size, ok := sizer.Size(); ok {
+		f.length = size
+	}
+}
+
+// getBody implements http.Request.GetBody. The previous pass must be done
+// before its readers are rewound.
+func (f *multipartForm) getBody() (io.ReadCloser, error) {
+	f.close()
+	if err := f.rewind(); err != nil {
+		return nil, err
+	}
+	return f.open()
+}
+
+// close stops the pass still writing the form, if any, and waits for it,
+// so the readers of the form are no longer used once the call returns.
+func (f *multipartForm) close() {
+	f.mu.Lock()
+	current := f.current
+	f.mu.Unlock()
+	if current != nil {
+		current.Close()
+		<-current.done
+	}
+}
+
+// setRequest sets the length of req and, if the form can be rewound, its GetBody.
+func (f *multipartForm) setRequest(req *http.Request) {
+	if f.length >= 0 {
+		req.ContentLength = f.length
+	}
+	if f.rewind != nil {
+		req.GetBody = f.getBody
+	}
+}
+
+// rewindReaders returns a function seeking readers back to their current
+// offsets, or nil if one of them isn't an io.Seeker.
+func rewindReaders(readers ...io.Reader) func() error {
+	type offset struct {
+		seeker io.Seeker
+		offset int64
+	}
+	offsets := make([]offset, 0, len(readers))
+	for _, r := range readers {
+		seeker, ok := r.(io.Seeker)
+		if !ok {
+			return nil
+		}
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil
+		}
+		offsets = append(offsets, offset{seeker: seeker, offset: current})
+	}
+	return func() error {
+		for _, o := range offsets {
+			if _, err := o.seeker.Seek(o.offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// progressReader reports the bytes read from a request body.
+type progressReader struct {
+	io.ReadCloser
+	sent, total int64
+	progress    UploadProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+	return n, err
+}
+
+// reportUploadProgress wraps the body of req, and the bodies GetBody returns, to call progress.
+func reportUploadProgress(req *http.Request, progress UploadProgressFunc) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	req.Body = &progressReader{ReadCloser: req.Body, total: total, progress: progress}
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressReader{ReadCloser: body, total: total, progress: progress}, nil
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// recordedUpload is what the test server saw of a multipart request.
+type recordedUpload struct {
+	mu            sync.Mutex
+	contentLength int64
+	chunked       bool
+	bodyLength    int64
+	file          string
+}
+
+func (u *recordedUpload) handler(t *testing.T, field string, response string) func(http.ResponseWriter, *http.Request) {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		checks.NoError(t, err, "ReadAll error")
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		file, _, err := r.FormFile(field)
+		checks.NoError(t, err, "FormFile error")
+		content, _ := io.ReadAll(file)
+
+		u.mu.Lock()
+		u.contentLength = r.ContentLength
+		u.chunked = len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked"
+		u.bodyLength = int64(len(body))
+		u.file = string(content)
+		u.mu.Unlock()
+		_, _ = io.WriteString(w, response)
+	}
+}
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	checks.NoError(t, os.WriteFile(path, []byte(content), 0o600), "WriteFile error")
+	return path
+}
+
+func TestCreateFileStreamsWithContentLength(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	upload := &recordedUpload{}
+	server.RegisterHandler("/v1/files", upload.handler(t, "file", `{"id":"file_1"}`))
+
+	content := strings.Repeat(`{"prompt":"a","completion":"b"}`+"\n", 1000)
+	path := writeTempFile(t, "train.jsonl", content)
+
+	var sent, total int64
+	_, err := client.CreateFile(context.Background(), openai.FileRequest{FilePath: path, Purpose: "fine-tune"},
+		openai.WithUploadProgress(func(s, n int64) { sent, total = s, n }))
+	checks.NoError(t, err, "CreateFile error")
+
+	if upload.file != content {
+		t.Fatalf("unexpected file content of %d bytes", len(upload.file))
+	}
+	if upload.chunked || upload.contentLength != upload.bodyLength {
+		t.Fatalf("expected Content-Length %d, got %d (chunked %v)", upload.bodyLength, upload.contentLength, upload.chunked)
+	}
+	if sent != upload.bodyLength || total != upload.bodyLength {
+		t.Fatalf("expected progress %d/%d, got %d/%d", upload.bodyLength, upload.bodyLength, sent, total)
+	}
+}
+
+func TestCreateTranscriptionStreamsUnknownLength(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	upload := &recordedUpload{}
+	server.RegisterHandler("/v1/audio/transcriptions", upload.handler(t, "file", `{"text":"hello"}`))
+
+	var total int64
+	_, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: "speech.mp3",
+		// A reader of unknown length is sent chunked.
+		Reader: io.MultiReader(strings.NewReader("mp3 "), strings.NewReader("bytes")),
+	}, openai.WithUploadProgress(func(_, n int64) { total = n }))
+	checks.NoError(t, err, "CreateTranscription error")
+
+	if upload.file != "mp3 bytes" || !upload.chunked {
+		t.Fatalf("expected a chunked upload of the reader, got %q (chunked %v)", upload.file, upload.chunked)
+	}
+	if total != -1 {
+		t.Fatalf("expected an unknown total, got %d", total)
+	}
+}
+
+func TestCreateFileRewindsOnRedirect(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	upload := &recordedUpload{}
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		http.Redirect(w, r, "/v1/files/moved", http.StatusTemporaryRedirect)
+	})
+	server.RegisterHandler("/v1/files/moved", upload.handler(t, "file", `{"id":"file_1"}`))
+
+	path := writeTempFile(t, "data.jsonl", `{"a":1}`)
+	file, err := client.CreateFile(context.Background(), openai.FileRequest{FilePath: path, Purpose: "batch"})
+	checks.NoError(t, err, "CreateFile error")
+	if file.ID != "file_1" || upload.file != `{"a":1}` || upload.contentLength != upload.bodyLength {
+		t.Fatalf("expected the redirected request to carry the whole file, got %q", upload.file)
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestCreateTranscriptionReaderError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, `{"text":"hello"}`)
+	})
+
+	errRead := errors.New("disk on fire")
+	_, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: "speech.mp3",
+		Reader:   io.MultiReader(strings.NewReader("mp3"), failingReader{err: errRead}),
+	})
+	if !errors.Is(err, errRead) {
+		t.Fatalf("expected the reader error, got %v", err)
+	}
+}
@@ -0,0 +1,532 @@
+package openaitest
+
+import (
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// RunState is a state a scripted run goes through.
+type RunState struct {
+	Status openai.RunStatus
+	// ToolCalls are the calls a requires_action state asks outputs for.
+	// Their IDs and types default to generated IDs and functions.
+	ToolCalls []openai.ToolCall
+	// Reply is the message a completed state adds to the thread. Defaults to
+	// the last user message of the thread.
+	Reply string
+	// LastError is the error of a failed state. Defaults to a server error.
+	LastError *openai.RunLastError
+}
+
+// defaultRunScript is the script of runs without a scripted one.
+var defaultRunScript = []RunState{
+	{Status: openai.RunStatusInProgress},
+	{Status: openai.RunStatusCompleted},
+}
+
+// ScriptRun scripts the next run created: it starts queued and moves to the
+// next of states every time it is retrieved. A requires_action state waits
+// for the tool outputs instead. Without a script, runs go through in_progress
+// to completed. Cancelled runs go through cancelling to cancelled.
+func (s *Server) ScriptRun(states ...RunState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runScripts = append(s.runScripts, states)
+}
+
+// storedThread is a thread with its messages.
+type storedThread struct {
+	openai.Thread
+	messages *store[openai.Message]
+}
+
+// storedRun is a run with the states it has yet to go through.
+type storedRun struct {
+	openai.Run
+	script []RunState
+}
+
+func (s *Server) registerAssistants() {
+	s.handle(http.MethodPost, "/assistants", s.createAssistant)
+	s.handle(http.MethodGet, "/assistants", s.listAssistants)
+	s.handle(http.MethodGet, "/assistants/{id}", s.getAssistant)
+	s.handle(http.MethodPost, "/assistants/{id}", s.modifyAssistant)
+	s.handle(http.MethodDelete, "/assistants/{id}", s.deleteAssistant)
+}
+
+func (s *Server) createAssistant(w http.ResponseWriter, r *http.Request, _ []string) {
+	var assistant openai.Assistant
+	if !decodeJSON(w, r, &assistant) {
+		return
+	}
+	if assistant.Model == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "model is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assistant.ID = s.newID("asst_")
+	assistant.Object = "assistant"
+	assistant.CreatedAt = now()
+	s.assistants.add(assistant.ID, &assistant)
+	writeJSON(w, http.StatusOK, assistant)
+}
+
+func (s *Server) listAssistants(w http.ResponseWriter, r *http.Request, _ []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, hasMore := page(r, s.assistants.order)
+	list := openai.AssistantsList{Assistants: []openai.Assistant{}, HasMore: hasMore}
+	for _, id := range ids {
+		assistant, _ := s.assistants.get(id)
+		list.Assistants = append(list.Assistants, *assistant)
+	}
+	list.FirstID, list.LastID = firstLast(ids)
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) getAssistant(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assistant, ok := s.assistants.get(ids[0])
+	if !ok {
+		writeNotFound(w, "assistant", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, assistant)
+}
+
+func (s *Server) modifyAssistant(w http.ResponseWriter, r *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assistant, ok := s.assistants.get(ids[0])
+	if !ok {
+		writeNotFound(w, "assistant", ids[0])
+		return
+	}
+	// Fields missing from the request keep their values.
+	modified := *assistant
+	if !decodeJSON(w, r, &modified) {
+		return
+	}
+	modified.ID, modified.Object, modified.CreatedAt = assistant.ID, assistant.Object, assistant.CreatedAt
+	*assistant = modified
+	writeJSON(w, http.StatusOK, assistant)
+}
+
+func (s *Server) deleteAssistant(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.assistants.remove(ids[0]) {
+		writeNotFound(w, "assistant", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, openai.AssistantDeleteResponse{ID: ids[0], Object: "assistant.deleted", Deleted: true})
+}
+
+func (s *Server) registerThreads() {
+	// /threads/runs is registered before /threads/{id}, which it would match.
+	s.handle(http.MethodPost, "/threads/runs", s.createThreadAndRun)
+	s.handle(http.MethodPost, "/threads", s.createThread)
+	s.handle(http.MethodGet, "/threads/{id}", s.getThread)
+	s.handle(http.MethodPost, "/threads/{id}", s.modifyThread)
+	s.handle(http.MethodDelete, "/threads/{id}", s.deleteThread)
+	s.handle(http.MethodPost, "/threads/{id}/messages", s.createMessage)
+	s.handle(http.MethodGet, "/threads/{id}/messages", s.listMessages)
+	s.handle(http.MethodGet, "/threads/{id}/messages/{id}", s.getMessage)
+	s.handle(http.MethodPost, "/threads/{id}/runs", s.createRun)
+	s.handle(http.MethodGet, "/threads/{id}/runs", s.listRuns)
+	s.handle(http.MethodGet, "/threads/{id}/runs/{id}", s.getRun)
+	s.handle(http.MethodPost, "/threads/{id}/runs/{id}/submit_tool_outputs", s.submitToolOutputs)
+	s.handle(http.MethodPost, "/threads/{id}/runs/{id}/cancel", s.cancelRun)
+}
+
+// addThread creates a thread with request. The caller must hold s.mu.
+func (s *Server) addThread(request openai.ThreadRequest) *storedThread {
+	thread := &storedThread{
+		Thread: openai.Thread{
+			ID:        s.newID("thread_"),
+			Object:    "thread",
+			CreatedAt: now(),
+			Metadata:  request.Metadata,
+		},
+		messages: newStore[openai.Message](),
+	}
+	for _, message := range request.Messages {
+		s.addMessage(thread, string(message.Role), message.Content, nil)
+	}
+	s.threads.add(thread.ID, thread)
+	return thread
+}
+
+// addMessage adds a text message to thread. The caller must hold s.mu.
+func (s *Server) addMessage(thread *storedThread, role, text string, run *openai.Run) openai.Message {
+	message := openai.Message{
+		ID:        s.newID("msg_"),
+		Object:    "thread.message",
+		CreatedAt: int(now()),
+		ThreadID:  thread.ID,
+		Role:      role,
+		Content: []openai.MessageContent{{
+			Type: "text",
+			Text: &openai.MessageText{Value: text, Annotations: []openai.MessageAnnotation{}},
+		}},
+		FileIds:  []string{},
+		Metadata: map[string]any{},
+	}
+	if run != nil {
+		assistantID, runID := run.AssistantID, run.ID
+		message.AssistantID, message.RunID = &assistantID, &runID
+	}
+	thread.messages.add(message.ID, &message)
+	return message
+}
+
+func (s *Server) createThread(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request openai.ThreadRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.addThread(request).Thread)
+}
+
+func (s *Server) getThread(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, thread.Thread)
+}
+
+func (s *Server) modifyThread(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.ModifyThreadRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	thread.Metadata = request.Metadata
+	if request.ToolResources != nil {
+		thread.ToolResources = *request.ToolResources
+	}
+	writeJSON(w, http.StatusOK, thread.Thread)
+}
+
+func (s *Server) deleteThread(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.threads.remove(ids[0]) {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, openai.ThreadDeleteResponse{ID: ids[0], Object: "thread.deleted", Deleted: true})
+}
+
+func (s *Server) createMessage(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.MessageRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addMessage(thread, request.Role, request.Content, nil))
+}
+
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	runID := r.URL.Query().Get("run_id")
+	var messageIDs []string
+	for _, message := range thread.messages.list() {
+		if runID == "" || (message.RunID != nil && *message.RunID == runID) {
+			messageIDs = append(messageIDs, message.ID)
+		}
+	}
+	messageIDs, hasMore := page(r, messageIDs)
+	list := openai.MessagesList{Object: "list", Messages: []openai.Message{}, HasMore: hasMore}
+	for _, id := range messageIDs {
+		message, _ := thread.messages.get(id)
+		list.Messages = append(list.Messages, *message)
+	}
+	list.FirstID, list.LastID = firstLast(messageIDs)
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) getMessage(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	message, ok := thread.messages.get(ids[1])
+	if !ok {
+		writeNotFound(w, "message", ids[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, message)
+}
+
+// addRun starts a run of request on thread. The caller must hold s.mu.
+func (s *Server) addRun(thread *storedThread, request openai.RunRequest) *storedRun {
+	for _, message := range request.AdditionalMessages {
+		s.addMessage(thread, string(message.Role), message.Content, nil)
+	}
+	script := defaultRunScript
+	if len(s.runScripts) > 0 {
+		script = s.runScripts[0]
+		s.runScripts = s.runScripts[1:]
+	}
+	run := &storedRun{
+		Run: openai.Run{
+			ID:           s.newID("run_"),
+			Object:       "thread.run",
+			CreatedAt:    now(),
+			ThreadID:     thread.ID,
+			AssistantID:  request.AssistantID,
+			Status:       openai.RunStatusQueued,
+			Model:        request.Model,
+			Instructions: request.Instructions,
+			Tools:        request.Tools,
+			FileIDS:      []string{},
+			Metadata:     request.Metadata,
+		},
+		script: script,
+	}
+	if assistant, ok := s.assistants.get(request.AssistantID); ok && run.Model == "" {
+		run.Model = assistant.Model
+	}
+	s.runs.add(run.ID, run)
+	return run
+}
+
+func (s *Server) createRun(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.RunRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thread, ok := s.threads.get(ids[0])
+	if !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	if _, ok = s.assistants.get(request.AssistantID); !ok {
+		writeNotFound(w, "assistant", request.AssistantID)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addRun(thread, request).Run)
+}
+
+func (s *Server) createThreadAndRun(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request openai.CreateThreadAndRunRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.assistants.get(request.AssistantID); !ok {
+		writeNotFound(w, "assistant", request.AssistantID)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addRun(s.addThread(request.Thread), request.RunRequest).Run)
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.threads.get(ids[0]); !ok {
+		writeNotFound(w, "thread", ids[0])
+		return
+	}
+	var runIDs []string
+	for _, run := range s.runs.list() {
+		if run.ThreadID == ids[0] {
+			runIDs = append(runIDs, run.ID)
+		}
+	}
+	runIDs, hasMore := page(r, runIDs)
+	list := openai.RunList{Runs: []openai.Run{}, HasMore: hasMore}
+	for _, id := range runIDs {
+		run, _ := s.runs.get(id)
+		list.Runs = append(list.Runs, run.Run)
+	}
+	if first, last := firstLast(runIDs); first != nil {
+		list.FirstID, list.LastID = *first, *last
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// threadRun returns the run with ids[1] on the thread with ids[0], answering
+// 404 when there is none. The caller must hold s.mu.
+func (s *Server) threadRun(w http.ResponseWriter, ids []string) (*storedRun, bool) {
+	run, ok := s.runs.get(ids[1])
+	if !ok || run.ThreadID != ids[0] {
+		writeNotFound(w, "run", ids[1])
+		return nil, false
+	}
+	return run, true
+}
+
+func (s *Server) getRun(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.threadRun(w, ids)
+	if !ok {
+		return
+	}
+	if run.Status != openai.RunStatusRequiresAction {
+		s.advanceRun(run)
+	}
+	writeJSON(w, http.StatusOK, run.Run)
+}
+
+func (s *Server) submitToolOutputs(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.SubmitToolOutputsRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.threadRun(w, ids)
+	if !ok {
+		return
+	}
+	if run.Status != openai.RunStatusRequiresAction {
+		writeError(w, http.StatusBadRequest, "invalid_request_error",
+			"Runs in status \""+string(run.Status)+"\" do not accept tool outputs.")
+		return
+	}
+	pending := map[string]bool{}
+	for _, call := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+		pending[call.ID] = true
+	}
+	for _, output := range request.ToolOutputs {
+		delete(pending, output.ToolCallID)
+	}
+	if len(pending) > 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "Expected tool outputs for every tool call.")
+		return
+	}
+	run.RequiredAction = nil
+	run.Status = openai.RunStatusQueued
+	s.advanceRun(run)
+	writeJSON(w, http.StatusOK, run.Run)
+}
+
+func (s *Server) cancelRun(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.threadRun(w, ids)
+	if !ok {
+		return
+	}
+	if isTerminal(run.Status) {
+		writeError(w, http.StatusBadRequest, "invalid_request_error",
+			"Cannot cancel run with status '"+string(run.Status)+"'.")
+		return
+	}
+	run.Status = openai.RunStatusCancelling
+	run.RequiredAction = nil
+	run.script = []RunState{{Status: openai.RunStatusCancelled}}
+	writeJSON(w, http.StatusOK, run.Run)
+}
+
+func isTerminal(status openai.RunStatus) bool {
+	switch status {
+	case openai.RunStatusCompleted, openai.RunStatusFailed, openai.RunStatusCancelled,
+		openai.RunStatusExpired, openai.RunStatusIncomplete:
+		return true
+	default:
+		return false
+	}
+}
+
+// advanceRun moves run to the next state of its script. The caller must hold s.mu.
+func (s *Server) advanceRun(run *storedRun) {
+	if isTerminal(run.Status) || len(run.script) == 0 {
+		return
+	}
+	state := run.script[0]
+	run.script = run.script[1:]
+	run.Status = state.Status
+	at := now()
+	if run.StartedAt == nil && state.Status != openai.RunStatusQueued {
+		run.StartedAt = &at
+	}
+
+	switch state.Status {
+	case openai.RunStatusRequiresAction:
+		calls := append([]openai.ToolCall(nil), state.ToolCalls...)
+		for i := range calls {
+			if calls[i].ID == "" {
+				calls[i].ID = s.newID("call_")
+			}
+			if calls[i].Type == "" {
+				calls[i].Type = openai.ToolTypeFunction
+			}
+		}
+		run.RequiredAction = &openai.RunRequiredAction{
+			Type:              openai.RequiredActionTypeSubmitToolOutputs,
+			SubmitToolOutputs: &openai.SubmitToolOutputs{ToolCalls: calls},
+		}
+	case openai.RunStatusCompleted:
+		run.CompletedAt = &at
+		thread, ok := s.threads.get(run.ThreadID)
+		if !ok {
+			return
+		}
+		reply := state.Reply
+		if reply == "" {
+			reply = lastUserThreadMessage(thread)
+		}
+		s.addMessage(thread, string(openai.ThreadMessageRoleAssistant), reply, &run.Run)
+		run.Usage = openai.Usage{CompletionTokens: countTokens(reply), TotalTokens: countTokens(reply)}
+	case openai.RunStatusFailed:
+		run.FailedAt = &at
+		run.LastError = state.LastError
+		if run.LastError == nil {
+			run.LastError = &openai.RunLastError{
+				Code:    openai.RunErrorServerError,
+				Message: "Sorry, something went wrong.",
+			}
+		}
+	case openai.RunStatusCancelled:
+		run.CancelledAt = &at
+	default:
+	}
+}
+
+func lastUserThreadMessage(thread *storedThread) string {
+	messages := thread.messages.list()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == string(openai.ThreadMessageRoleUser) && len(messages[i].Content) > 0 &&
+			messages[i].Content[0].Text != nil {
+			return messages[i].Content[0].Text.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+package openaitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ChatReply is a scripted answer to a chat completion.
+type ChatReply struct {
+	Content string
+	// ToolCalls are the calls the assistant makes. Their IDs and types
+	// default to generated IDs and functions.
+	ToolCalls []openai.ToolCall
+	// FinishReason defaults to tool_calls when there are tool calls and to stop otherwise.
+	FinishReason openai.FinishReason
+	// Usage defaults to one token per word of the messages and the reply.
+	Usage *openai.Usage
+	// Stream, when set, is served instead of the chunks of the reply when
+	// the completion is streamed.
+	Stream *StreamFixture
+}
+
+// ScriptChat queues replies, which answer the next chat completions in
+// order. Without a scripted reply, chat completions echo the last user message.
+func (s *Server) ScriptChat(replies ...ChatReply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatReplies = append(s.chatReplies, replies...)
+}
+
+func (s *Server) registerChat() {
+	s.handle(http.MethodPost, "/chat/completions", s.createChatCompletion)
+}
+
+// nextChatReply pops the scripted reply to request, with its defaults set.
+func (s *Server) nextChatReply(request openai.ChatCompletionRequest) ChatReply {
+	s.mu.Lock()
+	var reply ChatReply
+	if len(s.chatReplies) > 0 {
+		reply = s.chatReplies[0]
+		s.chatReplies = s.chatReplies[1:]
+	} else {
+		reply.Content = lastUserMessage(request.Messages)
+	}
+	reply.ToolCalls = append([]openai.ToolCall(nil), reply.ToolCalls...)
+	for i := range reply.ToolCalls {
+		if reply.ToolCalls[i].ID == "" {
+			reply.ToolCalls[i].ID = s.newID("call_")
+		}
+		if reply.ToolCalls[i].Type == "" {
+			reply.ToolCalls[i].Type = openai.ToolTypeFunction
+		}
+	}
+	s.mu.Unlock()
+
+	if reply.FinishReason == "" {
+		reply.FinishReason = openai.FinishReasonStop
+		if len(reply.ToolCalls) > 0 {
+			reply.FinishReason = openai.FinishReasonToolCalls
+		}
+	}
+	if reply.Usage == nil {
+		usage := openai.Usage{CompletionTokens: countTokens(reply.Content)}
+		for _, message := range request.Messages {
+			usage.PromptTokens += countTokens(messageText(message))
+		}
+		for _, call := range reply.ToolCalls {
+			usage.CompletionTokens += countTokens(call.Function.Name + " " + call.Function.Arguments)
+		}
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		reply.Usage = &usage
+	}
+	return reply
+}
+
+// countTokens approximates the tokens of s by its words.
+func countTokens(s string) int {
+	return len(strings.Fields(s))
+}
+
+func messageText(message openai.ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+	var texts []string
+	for _, part := range message.MultiContent {
+		if part.Type == openai.ChatMessagePartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+func lastUserMessage(messages []openai.ChatCompletionMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == openai.ChatMessageRoleUser {
+			return messageText(messages[i])
+		}
+	}
+	return ""
+}
+
+func (s *Server) createChatCompletion(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request openai.ChatCompletionRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if request.Model == "" || len(request.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "model and messages are required")
+		return
+	}
+	reply := s.nextChatReply(request)
+	s.mu.Lock()
+	id := s.newID("chatcmpl-")
+	s.mu.Unlock()
+
+	if request.Stream && reply.Stream != nil {
+		reply.Stream.ServeHTTP(w, r)
+		return
+	}
+	if request.Stream {
+		includeUsage := request.StreamOptions != nil && request.StreamOptions.IncludeUsage
+		failure, _ := streamFailure(r.Context())
+		writeChatStream(w, chatStreamChunks(id, request.Model, reply, includeUsage), failure)
+		return
+	}
+	writeJSON(w, http.StatusOK, openai.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: now(),
+		Model:   request.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message: openai.ChatCompletionMessage{
+				Role:      openai.ChatMessageRoleAssistant,
+				Content:   reply.Content,
+				ToolCalls: reply.ToolCalls,
+			},
+			FinishReason: reply.FinishReason,
+		}},
+		Usage: *reply.Usage,
+	})
+}
+
+// chatStreamChunks splits reply into the chunks the API streams: the role,
+// the content word by word, every tool call with its arguments in two
+// halves, the finish reason and, if requested, the usage.
+func chatStreamChunks(
+	id, model string,
+	reply ChatReply,
+	includeUsage bool,
+) []openai.ChatCompletionStreamResponse {
+	created := now()
+	chunk := func(delta openai.ChatCompletionStreamChoiceDelta) openai.ChatCompletionStreamResponse {
+		return openai.ChatCompletionStreamResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: []openai.ChatCompletionStreamChoice{{Delta: delta}},
+		}
+	}
+
+	chunks := []openai.ChatCompletionStreamResponse{chunk(openai.ChatCompletionStreamChoiceDelta{
+		Role: openai.ChatMessageRoleAssistant,
+	})}
+	for _, word := range splitWords(reply.Content) {
+		chunks = append(chunks, chunk(openai.ChatCompletionStreamChoiceDelta{Content: word}))
+	}
+	for i, call := range reply.ToolCalls {
+		index := i
+		arguments := call.Function.Arguments
+		half := len(arguments) / 2
+		chunks = append(chunks, chunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+			Index:    &index,
+			ID:       call.ID,
+			Type:     call.Type,
+			Function: openai.FunctionCall{Name: call.Function.Name, Arguments: arguments[:half]},
+		}}}))
+		chunks = append(chunks, chunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+			Index:    &index,
+			Function: openai.FunctionCall{Arguments: arguments[half:]},
+		}}}))
+	}
+	last := chunk(openai.ChatCompletionStreamChoiceDelta{})
+	last.Choices[0].FinishReason = reply.FinishReason
+	chunks = append(chunks, last)
+	if includeUsage {
+		usage := chunk(openai.ChatCompletionStreamChoiceDelta{})
+		usage.Choices = []openai.ChatCompletionStreamChoice{}
+		usage.Usage = reply.Usage
+		chunks = append(chunks, usage)
+	}
+	return chunks
+}
+
+// splitWords splits s into words, each with the spaces before it.
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if s[i] == ' ' && s[i-1] != ' ' {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// writeChatStream writes chunks as server-sent events, ending with [DONE],
+// or with an error event after failure.AfterChunks chunks when failure is a
+// mid-stream failure.
+func writeChatStream(w http.ResponseWriter, chunks []openai.ChatCompletionStreamResponse, failure Failure) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	for i, chunk := range chunks {
+		if failure.MidStream && i == failure.AfterChunks {
+			break
+		}
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if failure.MidStream {
+		data, _ := json.Marshal(openai.ErrorResponse{Error: failure.apiError()})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		return
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
@@ -0,0 +1,126 @@
+package openaitest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// defaultDimensions is the length of embeddings when the request doesn't set dimensions.
+const defaultDimensions = 1536
+
+// EmbeddingVector returns the embedding the server answers for input: a unit
+// vector of length dimensions derived from a hash of input, so equal inputs
+// get equal embeddings across runs and servers.
+func EmbeddingVector(input string, dimensions int) []float32 {
+	sum := sha256.Sum256([]byte(input))
+	state := binary.LittleEndian.Uint64(sum[:8])
+	vector := make([]float32, dimensions)
+	var norm float64
+	for i := range vector {
+		// splitmix64 spreads the seed into uniform values in [-1, 1).
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		z ^= z >> 31
+		value := float64(z>>11)/float64(1<<53)*2 - 1
+		vector[i] = float32(value)
+		norm += value * value
+	}
+	if norm = math.Sqrt(norm); norm > 0 {
+		for i := range vector {
+			vector[i] = float32(float64(vector[i]) / norm)
+		}
+	}
+	return vector
+}
+
+func (s *Server) registerEmbeddings() {
+	s.handle(http.MethodPost, "/embeddings", s.createEmbeddings)
+}
+
+// embeddingInputs returns the inputs of an embeddings request, with token
+// inputs written as their space separated tokens.
+func embeddingInputs(raw json.RawMessage) ([]string, bool) {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return []string{text}, true
+	}
+	var texts []string
+	if json.Unmarshal(raw, &texts) == nil {
+		return texts, true
+	}
+	var tokens []int
+	if json.Unmarshal(raw, &tokens) == nil {
+		return []string{joinTokens(tokens)}, true
+	}
+	var tokenLists [][]int
+	if json.Unmarshal(raw, &tokenLists) == nil {
+		inputs := make([]string, len(tokenLists))
+		for i, list := range tokenLists {
+			inputs[i] = joinTokens(list)
+		}
+		return inputs, true
+	}
+	return nil, false
+}
+
+func joinTokens(tokens []int) string {
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = strconv.Itoa(token)
+	}
+	return strings.Join(words, " ")
+}
+
+func (s *Server) createEmbeddings(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request struct {
+		Input          json.RawMessage                `json:"input"`
+		Model          openai.EmbeddingModel          `json:"model"`
+		EncodingFormat openai.EmbeddingEncodingFormat `json:"encoding_format"`
+		Dimensions     int                            `json:"dimensions"`
+	}
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	inputs, ok := embeddingInputs(request.Input)
+	if !ok || len(inputs) == 0 || request.Model == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error",
+			"model and input, a string, strings or tokens, are required")
+		return
+	}
+	dimensions := request.Dimensions
+	if dimensions <= 0 {
+		dimensions = defaultDimensions
+	}
+
+	type embedding struct {
+		Object    string `json:"object"`
+		Embedding any    `json:"embedding"`
+		Index     int    `json:"index"`
+	}
+	data := make([]embedding, len(inputs))
+	var usage openai.Usage
+	for i, input := range inputs {
+		vector := EmbeddingVector(input, dimensions)
+		data[i] = embedding{Object: "embedding", Embedding: vector, Index: i}
+		if request.EncodingFormat == openai.EmbeddingEncodingFormatBase64 {
+			data[i].Embedding = openai.EncodeEmbeddingBase64(vector)
+		}
+		usage.PromptTokens += countTokens(input)
+	}
+	usage.TotalTokens = usage.PromptTokens
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"data":   data,
+		"model":  request.Model,
+		"usage":  usage,
+	})
+}
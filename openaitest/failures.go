@@ -0,0 +1,132 @@
+package openaitest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Failure is an error the server answers a request with instead of serving it.
+type Failure struct {
+	StatusCode int
+	// Type and Code are those of the API error, e.g. "server_error".
+	Type    string
+	Code    string
+	Message string
+	// Header is added to the response, e.g. rate limit headers.
+	Header http.Header
+	// MidStream fails streamed chat completions after AfterChunks chunks
+	// with an error event, rather than failing the request. Other requests
+	// fail as usual.
+	MidStream   bool
+	AfterChunks int
+}
+
+// RateLimitFailure is a 429 with the rate limit headers of the API, asking
+// to retry after retryAfter.
+func RateLimitFailure(retryAfter time.Duration) Failure {
+	return Failure{
+		StatusCode: http.StatusTooManyRequests,
+		Type:       "requests",
+		Code:       "rate_limit_exceeded",
+		Message:    "Rate limit reached for requests. Please try again later.",
+		Header: http.Header{
+			"Retry-After":                    {strconv.Itoa(int(retryAfter.Round(time.Second) / time.Second))},
+			"Retry-After-Ms":                 {strconv.FormatInt(retryAfter.Milliseconds(), 10)},
+			"X-Ratelimit-Limit-Requests":     {"60"},
+			"X-Ratelimit-Limit-Tokens":       {"150000"},
+			"X-Ratelimit-Remaining-Requests": {"0"},
+			"X-Ratelimit-Remaining-Tokens":   {"0"},
+			"X-Ratelimit-Reset-Requests":     {retryAfter.String()},
+			"X-Ratelimit-Reset-Tokens":       {retryAfter.String()},
+		},
+	}
+}
+
+// ServerFailure is a 500.
+func ServerFailure() Failure {
+	return Failure{
+		StatusCode: http.StatusInternalServerError,
+		Type:       "server_error",
+		Message:    "The server had an error while processing your request.",
+	}
+}
+
+// StreamFailure fails a streamed chat completion with an error event after
+// afterChunks chunks.
+func StreamFailure(afterChunks int) Failure {
+	failure := ServerFailure()
+	failure.MidStream = true
+	failure.AfterChunks = afterChunks
+	return failure
+}
+
+// pendingFailure is a failure injected by FailNext.
+type pendingFailure struct {
+	method  string
+	path    *regexp.Regexp
+	failure Failure
+}
+
+// FailNext makes the next requests with method on path, given with or
+// without the /v1 prefix and possibly with * wildcards, fail with failures,
+// one request per failure. When the paths of several calls match a
+// request, the failures of the earliest call are used first.
+func (s *Server) FailNext(method, path string, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pattern := pathPattern(path)
+	for _, failure := range failures {
+		s.failures = append(s.failures, pendingFailure{method: method, path: pattern, failure: failure})
+	}
+}
+
+// nextFailure pops the first failure registered for method on path.
+func (s *Server) nextFailure(method, path string) (Failure, bool) {
+	for i, pending := range s.failures {
+		if pending.method == method && pending.path.MatchString(path) {
+			s.failures = append(s.failures[:i:i], s.failures[i+1:]...)
+			return pending.failure, true
+		}
+	}
+	return Failure{}, false
+}
+
+func (f Failure) apiError() *openai.APIError {
+	apiErr := &openai.APIError{Type: f.Type, Message: f.Message}
+	if f.Code != "" {
+		apiErr.Code = f.Code
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = fmt.Sprintf("injected %d failure", f.StatusCode)
+	}
+	return apiErr
+}
+
+func (f Failure) write(w http.ResponseWriter) {
+	for key, values := range f.Header {
+		w.Header()[key] = values
+	}
+	status := f.StatusCode
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, openai.ErrorResponse{Error: f.apiError()})
+}
+
+type streamFailureKey struct{}
+
+func withStreamFailure(ctx context.Context, failure Failure) context.Context {
+	return context.WithValue(ctx, streamFailureKey{}, failure)
+}
+
+// streamFailure returns the mid-stream failure injected into the request of ctx.
+func streamFailure(ctx context.Context) (Failure, bool) {
+	failure, ok := ctx.Value(streamFailureKey{}).(Failure)
+	return failure, ok
+}
@@ -0,0 +1,308 @@
+package openaitest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var (
+	ErrBatchNotFound   = errors.New("batch not found")
+	ErrBatchNotRunning = errors.New("batch is not in progress")
+)
+
+// batchOutputPurpose is the purpose of the output and error files of batches.
+const batchOutputPurpose = "batch_output"
+
+// storedFile is an uploaded file with its content.
+type storedFile struct {
+	openai.File
+	content []byte
+}
+
+func (s *Server) registerFiles() {
+	s.handle(http.MethodPost, "/files", s.createFile)
+	s.handle(http.MethodGet, "/files", s.listFiles)
+	s.handle(http.MethodGet, "/files/{id}", s.getFile)
+	s.handle(http.MethodDelete, "/files/{id}", s.deleteFile)
+	s.handle(http.MethodGet, "/files/{id}/content", s.getFileContent)
+}
+
+// addFile stores content as a new file.
+func (s *Server) addFile(name, purpose string, content []byte) openai.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file := openai.File{
+		ID:        s.newID("file-"),
+		Object:    "file",
+		Bytes:     len(content),
+		CreatedAt: now(),
+		FileName:  name,
+		Purpose:   purpose,
+		Status:    "processed",
+	}
+	s.files.add(file.ID, &storedFile{File: file, content: content})
+	return file
+}
+
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request, _ []string) {
+	upload, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "a multipart file is required: "+err.Error())
+		return
+	}
+	defer upload.Close()
+	content, err := io.ReadAll(upload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	purpose := r.FormValue("purpose")
+	if purpose == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "purpose is required")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addFile(header.Filename, purpose, content))
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request, _ []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purpose := r.URL.Query().Get("purpose")
+	list := openai.FilesList{Files: []openai.File{}}
+	for _, file := range s.files.list() {
+		if purpose == "" || file.Purpose == purpose {
+			list.Files = append(list.Files, file.File)
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) getFile(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, ok := s.files.get(ids[0])
+	if !ok {
+		writeNotFound(w, "file", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, file.File)
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.files.remove(ids[0]) {
+		writeNotFound(w, "file", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": ids[0], "object": "file", "deleted": true})
+}
+
+func (s *Server) getFileContent(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	file, ok := s.files.get(ids[0])
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w, "file", ids[0])
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(file.content)
+}
+
+func (s *Server) registerBatches() {
+	s.handle(http.MethodPost, "/batches", s.createBatch)
+	s.handle(http.MethodGet, "/batches", s.listBatches)
+	s.handle(http.MethodGet, "/batches/{id}", s.getBatch)
+	s.handle(http.MethodPost, "/batches/{id}/cancel", s.cancelBatch)
+}
+
+func (s *Server) createBatch(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request openai.CreateBatchRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	input, ok := s.files.get(request.InputFileID)
+	if !ok {
+		writeNotFound(w, "file", request.InputFileID)
+		return
+	}
+	created := int(now())
+	total := bytes.Count(bytes.TrimSpace(input.content), []byte("\n")) + 1
+	batch := &openai.Batch{
+		ID:               s.newID("batch_"),
+		Object:           "batch",
+		Endpoint:         request.Endpoint,
+		InputFileID:      request.InputFileID,
+		CompletionWindow: request.CompletionWindow,
+		Status:           "in_progress",
+		CreatedAt:        created,
+		InProgressAt:     &created,
+		RequestCounts:    openai.BatchRequestCounts{Total: total},
+		Metadata:         request.Metadata,
+	}
+	s.batches.add(batch.ID, batch)
+	writeJSON(w, http.StatusOK, batch)
+}
+
+func (s *Server) listBatches(w http.ResponseWriter, r *http.Request, _ []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, hasMore := page(r, s.batches.order)
+	list := openai.ListBatchResponse{Object: "list", Data: []openai.Batch{}, HasMore: hasMore}
+	for _, id := range ids {
+		batch, _ := s.batches.get(id)
+		list.Data = append(list.Data, *batch)
+	}
+	if first, last := firstLast(ids); first != nil {
+		list.FirstID, list.LastID = *first, *last
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) getBatch(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, ok := s.batches.get(ids[0])
+	if !ok {
+		writeNotFound(w, "batch", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+func (s *Server) cancelBatch(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, ok := s.batches.get(ids[0])
+	if !ok {
+		writeNotFound(w, "batch", ids[0])
+		return
+	}
+	if batch.Status == "in_progress" {
+		cancelled := int(now())
+		batch.Status = "cancelled"
+		batch.CancellingAt, batch.CancelledAt = &cancelled, &cancelled
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+// batchInputLine is a line of a batch input file.
+type batchInputLine struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// batchOutputLine is a line of a batch output or error file.
+type batchOutputLine struct {
+	ID       string           `json:"id"`
+	CustomID string           `json:"custom_id"`
+	Response *batchResponse   `json:"response"`
+	Error    *openai.APIError `json:"error"`
+}
+
+type batchResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// CompleteBatch completes the batch with id, which must be in progress, like
+// the API would: every line of its input file is answered by the server, and
+// the answers are written to its output file, or its error file for lines
+// that failed. Batches stay in progress until completed.
+func (s *Server) CompleteBatch(id string) error {
+	s.mu.Lock()
+	batch, ok := s.batches.get(id)
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrBatchNotFound, id)
+	}
+	if batch.Status != "in_progress" {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s is %s", ErrBatchNotRunning, id, batch.Status)
+	}
+	input, ok := s.files.get(batch.InputFileID)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("input file %s of batch %s not found", batch.InputFileID, id)
+	}
+
+	var output, errorOutput bytes.Buffer
+	counts := openai.BatchRequestCounts{}
+	scanner := bufio.NewScanner(bytes.NewReader(input.content))
+	scanner.Buffer(nil, len(input.content)+1)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		counts.Total++
+		line := s.runBatchLine(scanner.Bytes())
+		data, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		if line.Error != nil || line.Response.StatusCode >= http.StatusBadRequest {
+			counts.Failed++
+			errorOutput.Write(append(data, '\n'))
+			continue
+		}
+		counts.Completed++
+		output.Write(append(data, '\n'))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	completed := int(now())
+	outputFile := s.addFile(id+"_output.jsonl", batchOutputPurpose, output.Bytes())
+	var errorFileID *string
+	if errorOutput.Len() > 0 {
+		errorFile := s.addFile(id+"_error.jsonl", batchOutputPurpose, errorOutput.Bytes())
+		errorFileID = &errorFile.ID
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch.Status = "completed"
+	batch.FinalizingAt, batch.CompletedAt = &completed, &completed
+	batch.OutputFileID, batch.ErrorFileID = &outputFile.ID, errorFileID
+	batch.RequestCounts = counts
+	return nil
+}
+
+// runBatchLine answers a line of a batch input file.
+func (s *Server) runBatchLine(data []byte) batchOutputLine {
+	var input batchInputLine
+	s.mu.Lock()
+	line := batchOutputLine{ID: s.newID("batch_req_")}
+	s.mu.Unlock()
+	if err := json.Unmarshal(data, &input); err != nil {
+		line.Error = &openai.APIError{Code: "invalid_json", Message: err.Error()}
+		return line
+	}
+	line.CustomID = input.CustomID
+
+	request := httptest.NewRequest(input.Method, input.URL, bytes.NewReader(input.Body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.route(recorder, request)
+	line.Response = &batchResponse{
+		StatusCode: recorder.Code,
+		RequestID:  recorder.Header().Get("x-request-id"),
+		Body:       bytes.TrimSpace(recorder.Body.Bytes()),
+	}
+	return line
+}
@@ -0,0 +1,145 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	cassetteVersion  = 1
+	cassetteFileMode = 0o600
+	cassetteDirMode  = 0o755
+
+	// bodyEncodingBase64 marks bodies which aren't valid UTF-8, such as
+	// multipart uploads of binary files.
+	bodyEncodingBase64 = "base64"
+)
+
+var ErrUnsupportedCassetteVersion = errors.New("unsupported cassette version")
+
+// Cassette is the interactions recorded by a Recorder. It is saved as YAML
+// when its file name ends in .yaml or .yml, and as JSON otherwise.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// Response is a recorded response. Streamed responses hold the whole event stream.
+type Response struct {
+	Status       string      `json:"status"`
+	StatusCode   int         `json:"status_code"`
+	Header       http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+}
+
+// encodeBody returns body as stored in a cassette.
+func encodeBody(body []byte) (text, encoding string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), bodyEncodingBase64
+}
+
+func decodeBody(text, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(text), nil
+	case bodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return nil, fmt.Errorf("unknown body encoding %q", encoding)
+}
+
+// RequestBody returns the decoded body of the request.
+func (r Request) RequestBody() ([]byte, error) {
+	return decodeBody(r.Body, r.BodyEncoding)
+}
+
+// ResponseBody returns the decoded body of the response.
+func (r Response) ResponseBody() ([]byte, error) {
+	return decodeBody(r.Body, r.BodyEncoding)
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// LoadCassette reads the cassette saved at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isYAML(path) {
+		var value any
+		if value, err = unmarshalYAML(data); err != nil {
+			return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var cassette Cassette
+	if err = json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+	}
+	if cassette.Version != cassetteVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedCassetteVersion, cassette.Version)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	c.Version = cassetteVersion
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if isYAML(path) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		value, decodeErr := decodeOrderedJSON(decoder)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		data = marshalYAML(value)
+	} else {
+		data = append(data, '\n')
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), cassetteDirMode); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, cassetteFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
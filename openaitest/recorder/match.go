@@ -0,0 +1,113 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Matcher reports whether req, whose body is body, matches a recorded request.
+type Matcher func(req *http.Request, body []byte, recorded Request) bool
+
+// MatchAll matches requests matched by all of matchers.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(req *http.Request, body []byte, recorded Request) bool {
+		for _, match := range matchers {
+			if !match(req, body, recorded) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// DefaultMatcher matches requests by method, path and normalized body.
+func DefaultMatcher() Matcher {
+	return MatchAll(MatchMethod, MatchPath, MatchBody)
+}
+
+// MatchMethod matches requests with the recorded method.
+func MatchMethod(req *http.Request, _ []byte, recorded Request) bool {
+	return req.Method == recorded.Method
+}
+
+// MatchPath matches requests with the recorded URL path, whatever their host.
+func MatchPath(req *http.Request, _ []byte, recorded Request) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+	return err == nil && req.URL.Path == recordedURL.Path
+}
+
+// MatchQuery matches requests with the recorded query parameters, in any order.
+func MatchQuery(req *http.Request, _ []byte, recorded Request) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+	return err == nil && req.URL.Query().Encode() == recordedURL.Query().Encode()
+}
+
+// MatchBody matches requests whose body, normalized by NormalizeBody, is the
+// normalized recorded body.
+func MatchBody(req *http.Request, body []byte, recorded Request) bool {
+	recordedBody, err := recorded.RequestBody()
+	if err != nil {
+		return false
+	}
+	recordedType := recorded.Header.Get("Content-Type")
+	return bytes.Equal(NormalizeBody(req.Header.Get("Content-Type"), body), NormalizeBody(recordedType, recordedBody))
+}
+
+// NormalizeBody returns a form of body which doesn't change from one request
+// to the next: JSON with sorted keys and without insignificant whitespace, and
+// multipart forms as their parts without the random boundary. Other bodies are
+// returned as they are.
+func NormalizeBody(contentType string, body []byte) []byte {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType == "multipart/form-data" && params["boundary"] != "" {
+		if normalized, err := normalizeMultipart(body, params["boundary"]); err == nil {
+			return normalized
+		}
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return normalized
+}
+
+// normalizeMultipart lists the parts of a form, sorted by field name, with
+// their file name, content type and content.
+func normalizeMultipart(body []byte, boundary string) ([]byte, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, fmt.Sprintf("%q %q %q\n%s\n",
+			part.FormName(), part.FileName(), part.Header.Get("Content-Type"), content))
+	}
+	sort.Strings(parts)
+	return []byte(strings.Join(parts, "")), nil
+}
@@ -0,0 +1,257 @@
+// Package recorder records the HTTP interactions of an openai.Client to a
+// cassette file and replays them offline, so tests written against the real
+// API can run without network access:
+//
+//	rec, err := recorder.New("testdata/chat.yaml", http.DefaultClient, recorder.Options{
+//		Mode: recorder.ModeReplay,
+//	})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Stop()
+//	config := openai.DefaultConfig(os.Getenv("OPENAI_API_KEY"))
+//	config.HTTPClient = rec
+//	client := openai.NewClientWithConfig(config)
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var (
+	ErrNoInteraction     = errors.New("no recorded interaction matches the request")
+	ErrRecorderStopped   = errors.New("recorder is stopped")
+	ErrCassetteNotFound  = errors.New("cassette not found")
+	ErrUnknownRecordMode = errors.New("unknown record mode")
+)
+
+// Mode is whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay serves requests from the cassette, never from the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests and saves them with their responses to the
+	// cassette, replacing the interactions it held.
+	ModeRecord
+	// ModeReplayOrRecord replays the cassette when it exists and records it
+	// otherwise.
+	ModeReplayOrRecord
+)
+
+// redacted replaces the values of scrubbed headers.
+const redacted = "REDACTED"
+
+// Options configures a Recorder. Zero values use the defaults.
+type Options struct {
+	Mode Mode
+	// Matcher finds the interaction replayed for a request. Defaults to
+	// DefaultMatcher, which compares the method, path and normalized body.
+	Matcher Matcher
+	// ScrubHeaders are the request and response headers whose values are
+	// redacted in the cassette. Defaults to the Authorization, api-key,
+	// x-api-key, OpenAI-Organization and OpenAI-Project headers.
+	ScrubHeaders []string
+	// Scrub, when set, is called on every interaction before it is saved,
+	// e.g. to remove secrets from bodies.
+	Scrub func(*Interaction)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Matcher == nil {
+		o.Matcher = DefaultMatcher()
+	}
+	if o.ScrubHeaders == nil {
+		o.ScrubHeaders = []string{
+			"Authorization", openai.AzureAPIKeyHeader, openai.AnthropicAPIKeyHeader,
+			"OpenAI-Organization", "OpenAI-Project",
+		}
+	}
+	return o
+}
+
+// Recorder is an openai.HTTPDoer recording or replaying the requests it is given.
+type Recorder struct {
+	next    openai.HTTPDoer
+	path    string
+	mode    Mode
+	options Options
+
+	mu       sync.Mutex
+	cassette *Cassette
+	replayed []bool
+	stopped  bool
+}
+
+// New returns a Recorder for the cassette at path, which sends requests it
+// records with next. In ModeReplay the cassette must exist.
+func New(path string, next openai.HTTPDoer, options Options) (*Recorder, error) {
+	options = options.withDefaults()
+	r := &Recorder{next: next, path: path, mode: options.Mode, options: options, cassette: &Cassette{}}
+	switch options.Mode {
+	case ModeRecord:
+		return r, nil
+	case ModeReplayOrRecord:
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			r.mode = ModeRecord
+			return r, nil
+		}
+	case ModeReplay:
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownRecordMode, options.Mode)
+	}
+
+	cassette, err := LoadCassette(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrCassetteNotFound, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.mode = ModeReplay
+	r.cassette = cassette
+	r.replayed = make([]bool, len(cassette.Interactions))
+	return r, nil
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns the interactions recorded or loaded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{
+		Version:      r.cassette.Version,
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Stop ends recording and, when recording, saves the cassette.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil
+	}
+	r.stopped = true
+	if r.mode != ModeRecord {
+		return nil
+	}
+	return r.cassette.Save(r.path)
+}
+
+// Do records or replays req. Bodies, including multipart uploads and event
+// streams, are read completely, so recorded streams reach the client at once.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil, ErrRecorderStopped
+	}
+	// Interactions are replayed once each, in order, so repeated requests,
+	// such as polling a run, get the responses they got when recorded.
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || !r.options.Matcher(req, body, interaction.Request) {
+			continue
+		}
+		responseBody, err := interaction.Response.ResponseBody()
+		if err != nil {
+			return nil, err
+		}
+		r.replayed[i] = true
+		return &http.Response{
+			Status:        interaction.Response.Status,
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(responseBody)),
+			ContentLength: int64(len(responseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.scrub(req.Header),
+		},
+		Response: Response{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Header:     r.scrub(resp.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(responseBody)
+	if r.options.Scrub != nil {
+		r.options.Scrub(&interaction)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return nil, ErrRecorderStopped
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return resp, nil
+}
+
+// scrub returns a copy of header with the values of the scrubbed headers redacted.
+func (r *Recorder) scrub(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for key := range scrubbed {
+		for _, name := range r.options.ScrubHeaders {
+			if strings.EqualFold(key, name) {
+				scrubbed[key] = []string{redacted}
+			}
+		}
+	}
+	return scrubbed
+}
@@ -0,0 +1,225 @@
+package recorder_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/openaitest/recorder"
+)
+
+const testToken = "sk-secret-token"
+
+// failingDoer fails every request, standing in for a network that isn't there.
+type failingDoer struct{}
+
+func (failingDoer) Do(*http.Request) (*http.Response, error) {
+	return nil, errors.New("no network")
+}
+
+func newAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			body, _ := io.ReadAll(r.Body)
+			if bytes.Contains(body, []byte(`"stream":true`)) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, content := range []string{"Hel", "lo"} {
+					fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+				}
+				fmt.Fprint(w, "data: [DONE]\n\n")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`)
+		case "/v1/files":
+			checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"file-1","filename":%q,"purpose":%q}`,
+				r.MultipartForm.File["file"][0].Filename, r.FormValue("purpose"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newClient(baseURL string, doer openai.HTTPDoer) *openai.Client {
+	config := openai.DefaultConfig(testToken)
+	config.BaseURL = baseURL + "/v1"
+	config.HTTPClient = doer
+	return openai.NewClientWithConfig(config)
+}
+
+// exercise sends a chat completion, a streamed one and a file upload, and
+// returns what the client got.
+func exercise(t *testing.T, client *openai.Client, dir string) []string {
+	t.Helper()
+	ctx := context.Background()
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	response, err := client.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	stream, err := client.CreateChatCompletionStream(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	var streamed string
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "Recv error")
+		streamed += chunk.Choices[0].Delta.Content
+	}
+
+	path := filepath.Join(dir, "data.jsonl")
+	checks.NoError(t, os.WriteFile(path, []byte("{\"a\":1}\n\x00\xff"), 0o600), "WriteFile error")
+	file, err := client.CreateFile(ctx, openai.FileRequest{FilePath: path, Purpose: "batch"})
+	checks.NoError(t, err, "CreateFile error")
+
+	return []string{response.Choices[0].Message.Content, streamed, file.FileName}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	for _, name := range []string{"cassette.yaml", "cassette.json"} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "testdata", name)
+			server := newAPIServer(t)
+
+			rec, err := recorder.New(path, &http.Client{}, recorder.Options{Mode: recorder.ModeReplayOrRecord})
+			checks.NoErrorF(t, err, "New error")
+			if rec.Mode() != recorder.ModeRecord {
+				t.Fatal("expected a missing cassette to be recorded")
+			}
+			recorded := exercise(t, newClient(server.URL, rec), dir)
+			checks.NoError(t, rec.Stop(), "Stop error")
+			server.Close()
+
+			data, err := os.ReadFile(path)
+			checks.NoError(t, err, "ReadFile error")
+			if bytes.Contains(data, []byte(testToken)) || !bytes.Contains(data, []byte("REDACTED")) {
+				t.Fatalf("expected the token to be scrubbed:\n%s", data)
+			}
+
+			rec, err = recorder.New(path, failingDoer{}, recorder.Options{Mode: recorder.ModeReplayOrRecord})
+			checks.NoErrorF(t, err, "New error")
+			defer rec.Stop()
+			if rec.Mode() != recorder.ModeReplay {
+				t.Fatal("expected an existing cassette to be replayed")
+			}
+			replayed := exercise(t, newClient(server.URL, rec), dir)
+			if !reflect.DeepEqual(recorded, replayed) || replayed[1] != "Hello" || replayed[2] != "data.jsonl" {
+				t.Fatalf("expected %v to be replayed, got %v", recorded, replayed)
+			}
+
+			// Every interaction is replayed once.
+			_, err = newClient(server.URL, rec).CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+				Model:    openai.GPT4oMini,
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+			})
+			if !errors.Is(err, recorder.ErrNoInteraction) {
+				t.Fatalf("expected ErrNoInteraction, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReplayMissingCassette(t *testing.T) {
+	_, err := recorder.New(filepath.Join(t.TempDir(), "missing.yaml"), failingDoer{}, recorder.Options{})
+	if !errors.Is(err, recorder.ErrCassetteNotFound) {
+		t.Fatalf("expected ErrCassetteNotFound, got %v", err)
+	}
+}
+
+func TestReplayMatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	cassette := recorder.Cassette{Interactions: []recorder.Interaction{{
+		Request: recorder.Request{
+			Method: http.MethodPost,
+			URL:    "https://api.openai.com/v1/chat/completions",
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   `{"model": "gpt-4o-mini", "messages": []}`,
+		},
+		Response: recorder.Response{Status: "200 OK", StatusCode: http.StatusOK, Body: `{"id":"1"}`},
+	}}}
+	checks.NoError(t, cassette.Save(path), "Save error")
+
+	send := func(options recorder.Options, body string) error {
+		rec, err := recorder.New(path, failingDoer{}, options)
+		checks.NoErrorF(t, err, "New error")
+		req, err := http.NewRequest(http.MethodPost, "http://localhost/v1/chat/completions", strings.NewReader(body))
+		checks.NoError(t, err, "NewRequest error")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := rec.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// The body is compared normalized, the host not at all.
+	checks.NoError(t, send(recorder.Options{}, `{"messages":[],"model":"gpt-4o-mini"}`), "Do error")
+	if err := send(recorder.Options{}, `{"messages":[],"model":"gpt-4o"}`); !errors.Is(err, recorder.ErrNoInteraction) {
+		t.Fatalf("expected another body not to match, got %v", err)
+	}
+	options := recorder.Options{Matcher: recorder.MatchAll(recorder.MatchMethod, recorder.MatchPath)}
+	checks.NoError(t, send(options, `{"model":"gpt-4o"}`), "Do error")
+}
+
+func TestCassetteYAML(t *testing.T) {
+	bodies := []string{
+		"data: {\"a\":1}\n\ndata: [DONE]\n\n",
+		"line one\nline two",
+		"line one\n  indented\n",
+		"  leading spaces\nsecond",
+		"tabs\tand\r\ncarriage returns\n",
+		"null",
+		"true",
+		"123",
+		"key: value",
+		"# not a comment",
+		"",
+		"- not a list",
+		"'quoted'",
+	}
+	var cassette recorder.Cassette
+	for _, body := range bodies {
+		cassette.Interactions = append(cassette.Interactions, recorder.Interaction{
+			Request: recorder.Request{Method: http.MethodGet, URL: "https://example.com/a?b=c", Body: body},
+			Response: recorder.Response{
+				Status:     "404 Not Found",
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"X-Multi": {"a", "b: c"}},
+				Body:       body,
+			},
+		})
+	}
+	path := filepath.Join(t.TempDir(), "cassette.yml")
+	checks.NoError(t, cassette.Save(path), "Save error")
+	loaded, err := recorder.LoadCassette(path)
+	checks.NoError(t, err, "LoadCassette error")
+	if !reflect.DeepEqual(*loaded, cassette) {
+		data, _ := os.ReadFile(path)
+		t.Fatalf("cassette changed by a round trip:\n%s\n%+v", data, loaded.Interactions)
+	}
+}
@@ -0,0 +1,491 @@
+package recorder
+
+// Cassettes are written and read with a small YAML codec instead of a YAML
+// dependency. It writes block mappings and sequences, double-quoted or plain
+// scalars, and literal blocks for multi-line strings, and reads that subset
+// back, which covers cassettes edited by hand as long as they keep to it.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const yamlIndent = 2
+
+var errInvalidYAML = errors.New("invalid cassette YAML")
+
+var (
+	plainYAMLKey    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-/]*$`)
+	plainYAMLString = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_./+\-]*( [A-Za-z0-9_./+\-]+)*$`)
+	yamlKeywords    = map[string]bool{
+		"true": true, "false": true, "null": true, "yes": true, "no": true, "on": true, "off": true,
+		"inf": true, "nan": true, "infinity": true,
+	}
+)
+
+// yamlField is a key of a mapping, which keeps its keys in order.
+type yamlField struct {
+	key   string
+	value any
+}
+
+type yamlMapping []yamlField
+
+// decodeOrderedJSON decodes a JSON value keeping the order of object keys.
+func decodeOrderedJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		mapping := yamlMapping{}
+		for decoder.More() {
+			var key json.Token
+			if key, err = decoder.Token(); err != nil {
+				return nil, err
+			}
+			var value any
+			if value, err = decodeOrderedJSON(decoder); err != nil {
+				return nil, err
+			}
+			name, _ := key.(string)
+			mapping = append(mapping, yamlField{key: name, value: value})
+		}
+		_, err = decoder.Token()
+		return mapping, err
+	case json.Delim('['):
+		sequence := []any{}
+		for decoder.More() {
+			var value any
+			if value, err = decodeOrderedJSON(decoder); err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+		}
+		_, err = decoder.Token()
+		return sequence, err
+	}
+	return token, nil
+}
+
+// marshalYAML writes value, made of yamlMapping, map[string]any, []any,
+// strings, numbers, booleans and nil, as a YAML document.
+func marshalYAML(value any) []byte {
+	var out bytes.Buffer
+	writeYAMLNode(&out, value, 0)
+	return out.Bytes()
+}
+
+func writeYAMLNode(out *bytes.Buffer, value any, indent int) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		mapping := make(yamlMapping, len(keys))
+		for i, key := range keys {
+			mapping[i] = yamlField{key: key, value: v[key]}
+		}
+		writeYAMLNode(out, mapping, indent)
+	case yamlMapping:
+		for i, field := range v {
+			// The first key of a sequence item follows its dash.
+			if i > 0 || out.Len() == 0 || out.Bytes()[out.Len()-1] == '\n' {
+				writeIndent(out, indent)
+			}
+			out.WriteString(yamlKey(field.key))
+			out.WriteByte(':')
+			writeYAMLValue(out, field.value, indent)
+		}
+	case []any:
+		for _, item := range v {
+			writeIndent(out, indent)
+			if _, nested := item.([]any); nested && isYAMLCollection(item) {
+				out.WriteString("-\n")
+				writeYAMLNode(out, item, indent+yamlIndent)
+				continue
+			}
+			out.WriteString("- ")
+			if isYAMLCollection(item) {
+				writeYAMLNode(out, item, indent+yamlIndent)
+				continue
+			}
+			writeYAMLScalar(out, item, indent+yamlIndent)
+			out.WriteByte('\n')
+		}
+	}
+}
+
+// writeYAMLValue writes the value of a mapping key, after its colon.
+func writeYAMLValue(out *bytes.Buffer, value any, indent int) {
+	if !isYAMLCollection(value) {
+		out.WriteByte(' ')
+		writeYAMLScalar(out, value, indent+yamlIndent)
+		out.WriteByte('\n')
+		return
+	}
+	out.WriteByte('\n')
+	writeYAMLNode(out, value, indent+yamlIndent)
+}
+
+func isYAMLCollection(value any) bool {
+	switch v := value.(type) {
+	case yamlMapping:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	case []any:
+		return len(v) > 0
+	}
+	return false
+}
+
+func writeIndent(out *bytes.Buffer, indent int) {
+	out.WriteString(strings.Repeat(" ", indent))
+}
+
+func yamlKey(key string) string {
+	if plainYAMLKey.MatchString(key) && !yamlKeywords[strings.ToLower(key)] {
+		return key
+	}
+	return quoteYAML(key)
+}
+
+// writeYAMLScalar writes value, where a literal block is indented by indent.
+func writeYAMLScalar(out *bytes.Buffer, value any, indent int) {
+	switch v := value.(type) {
+	case nil:
+		out.WriteString("null")
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		out.WriteString(v.String())
+	case float64:
+		out.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case yamlMapping, map[string]any:
+		out.WriteString("{}")
+	case []any:
+		out.WriteString("[]")
+	case string:
+		switch {
+		case plainYAMLString.MatchString(v) && !yamlKeywords[strings.ToLower(v)]:
+			out.WriteString(v)
+		case isYAMLBlock(v):
+			writeYAMLBlock(out, v, indent)
+		case isSingleQuotable(v):
+			out.WriteString("'" + strings.ReplaceAll(v, "'", "''") + "'")
+		default:
+			out.WriteString(quoteYAML(v))
+		}
+	default:
+		out.WriteString(quoteYAML(fmt.Sprint(v)))
+	}
+}
+
+// quoteYAML double quotes s. JSON string escapes are valid YAML ones.
+func quoteYAML(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// isSingleQuotable reports whether s can be single quoted, which needs no
+// escapes but for quotes and so keeps JSON bodies readable.
+func isSingleQuotable(s string) bool {
+	for _, r := range s {
+		if r < ' ' || r == unicode.MaxASCII || r == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
+
+// isYAMLBlock reports whether s is written as a literal block: it is made of
+// several lines, without carriage returns or tabs, and its first line doesn't
+// start with a space, which would be taken for indentation.
+func isYAMLBlock(s string) bool {
+	return strings.Contains(strings.TrimRight(s, "\n"), "\n") &&
+		!strings.ContainsAny(s, "\r\t") && !strings.HasPrefix(s, " ")
+}
+
+// writeYAMLBlock writes s as a literal block, whose chomping indicator keeps
+// exactly the trailing newlines of s.
+func writeYAMLBlock(out *bytes.Buffer, s string, indent int) {
+	content := strings.TrimRight(s, "\n")
+	switch len(s) - len(content) {
+	case 0:
+		out.WriteString("|-")
+	case 1:
+		out.WriteString("|")
+	default:
+		out.WriteString("|+")
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for _, line := range lines {
+		out.WriteByte('\n')
+		if line != "" {
+			writeIndent(out, indent)
+			out.WriteString(line)
+		}
+	}
+}
+
+// yamlParser reads the YAML written by marshalYAML into map[string]any, []any,
+// strings, float64, booleans and nil.
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func unmarshalYAML(data []byte) (any, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	p := &yamlParser{lines: strings.Split(text, "\n")}
+	indent, ok := p.next()
+	if !ok {
+		return nil, nil //nolint:nilnil // an empty document is null
+	}
+	value, err := p.parseNode(indent)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok = p.next(); ok {
+		return nil, p.errorf("unexpected content")
+	}
+	return value, nil
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", errInvalidYAML, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next skips blank and comment lines and returns the indentation of the next line.
+func (p *yamlParser) next() (int, bool) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		trimmed := strings.TrimLeft(p.lines[p.pos], " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		return len(p.lines[p.pos]) - len(trimmed), true
+	}
+	return 0, false
+}
+
+func (p *yamlParser) text() string {
+	return strings.TrimSpace(p.lines[p.pos])
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits a "key: value" line, reporting false for scalars.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if strings.HasPrefix(text, `"`) {
+		decoder := json.NewDecoder(strings.NewReader(text))
+		if err := decoder.Decode(&key); err != nil {
+			return "", "", false
+		}
+		rest = strings.TrimLeft(text[decoder.InputOffset():], " ")
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+	if strings.HasPrefix(text, "'") {
+		// Single-quoted keys aren't written, so this is a scalar.
+		return "", "", false
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return text[:i], strings.TrimSpace(text[i+1:]), true
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSuffix(text, ":"), "", true
+	}
+	return "", "", false
+}
+
+// parseNode parses the mapping, sequence or scalar starting on the current
+// line, indented by indent.
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	text := p.text()
+	if isSequenceItem(text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(text); ok {
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	return parseYAMLScalar(text)
+}
+
+func (p *yamlParser) parseMapping(indent int) (any, error) {
+	mapping := map[string]any{}
+	for {
+		lineIndent, ok := p.next()
+		if !ok || lineIndent < indent {
+			return mapping, nil
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		text := p.text()
+		if isSequenceItem(text) {
+			return mapping, nil
+		}
+		key, rest, ok := splitYAMLKey(text)
+		if !ok {
+			return nil, p.errorf("expected a key")
+		}
+		if _, ok = mapping[key]; ok {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		value, err := p.parseValue(indent, rest)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+}
+
+// parseValue parses the value of a key indented by indent, whose line
+// continues with rest.
+func (p *yamlParser) parseValue(indent int, rest string) (any, error) {
+	if strings.HasPrefix(rest, "|") {
+		return p.parseBlock(indent, rest)
+	}
+	p.pos++
+	if rest != "" {
+		return parseYAMLScalar(rest)
+	}
+	childIndent, ok := p.next()
+	switch {
+	case ok && childIndent > indent:
+		return p.parseNode(childIndent)
+	case ok && childIndent == indent && isSequenceItem(p.text()):
+		return p.parseSequence(indent)
+	}
+	return nil, nil //nolint:nilnil // a key without a value is null
+}
+
+func (p *yamlParser) parseSequence(indent int) (any, error) {
+	sequence := []any{}
+	for {
+		lineIndent, ok := p.next()
+		if !ok || lineIndent < indent || !isSequenceItem(p.text()) {
+			return sequence, nil
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(p.text(), "-"))
+		var (
+			item any
+			err  error
+		)
+		if _, _, isKey := splitYAMLKey(rest); isKey {
+			// The item is a mapping starting after the dash: parse the line
+			// as if the dash were indentation.
+			p.lines[p.pos] = strings.Repeat(" ", indent+yamlIndent) + rest
+			item, err = p.parseMapping(indent + yamlIndent)
+		} else {
+			item, err = p.parseValue(indent, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, item)
+	}
+}
+
+// parseBlock parses a literal block whose header, "|", "|-" or "|+", ends
+// the line of a key indented by indent.
+func (p *yamlParser) parseBlock(indent int, header string) (any, error) {
+	chomping := strings.TrimPrefix(header, "|")
+	if chomping != "" && chomping != "-" && chomping != "+" {
+		return nil, p.errorf("unsupported block header %q", header)
+	}
+	p.pos++
+
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+		if trimmed == "" {
+			if blockIndent >= 0 && len(line) > blockIndent {
+				lines = append(lines, line[blockIndent:])
+			} else {
+				lines = append(lines, "")
+			}
+			continue
+		}
+		if blockIndent < 0 {
+			if lineIndent <= indent {
+				break
+			}
+			blockIndent = lineIndent
+		}
+		if lineIndent < blockIndent {
+			break
+		}
+		lines = append(lines, line[blockIndent:])
+	}
+	if p.pos == len(p.lines) && len(lines) > 0 && lines[len(lines)-1] == "" {
+		// The newline ending the document isn't an empty line of the block.
+		lines = lines[:len(lines)-1]
+	}
+
+	content := strings.Join(lines, "\n") + "\n"
+	switch chomping {
+	case "-":
+		return strings.TrimRight(content, "\n"), nil
+	case "+":
+		return content, nil
+	}
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return "", nil
+	}
+	return content + "\n", nil
+}
+
+func parseYAMLScalar(text string) (any, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		var s string
+		if err := json.Unmarshal([]byte(text), &s); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errInvalidYAML, text, err)
+		}
+		return s, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("%w: unterminated string %s", errInvalidYAML, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "{}":
+		return map[string]any{}, nil
+	case text == "[]":
+		return []any{}, nil
+	case text == "null" || text == "~":
+		return nil, nil //nolint:nilnil // YAML null
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	}
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return number, nil
+	}
+	return text, nil
+}
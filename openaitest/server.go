@@ -0,0 +1,375 @@
+// Package openaitest provides a fake OpenAI API server for testing code built
+// on the openai package without network access or credentials.
+//
+// The fake keeps state like the real API: uploaded files can be batched,
+// assistants run on threads, and files added to vector stores. Chat
+// completions answer with scripted replies, embeddings are deterministic
+// vectors derived from a hash of their input, and failures can be injected
+// into any endpoint. Every request is recorded for assertions:
+//
+//	server := openaitest.NewServer()
+//	defer server.Close()
+//	server.ScriptChat(openaitest.ChatReply{Content: "Paris"})
+//
+//	client := server.Client()
+//	resp, err := client.CreateChatCompletion(ctx, request)
+//	...
+//	server.AssertRequestCount(t, http.MethodPost, "/chat/completions", 1)
+package openaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// apiPrefix is the path of the API on the server, which clients include in their base URL.
+const apiPrefix = "/v1"
+
+// Token is the API key of the clients returned by Server.Client. The fake
+// accepts any key.
+const Token = "sk-openaitest"
+
+// Server is a fake OpenAI API. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, without the /v1 API prefix.
+	URL string
+
+	server *httptest.Server
+	routes []route
+
+	mu           sync.Mutex
+	ids          int
+	requests     []Request
+	failures     []pendingFailure
+	chatReplies  []ChatReply
+	runScripts   [][]RunState
+	files        *store[storedFile]
+	batches      *store[openai.Batch]
+	assistants   *store[openai.Assistant]
+	threads      *store[storedThread]
+	runs         *store[storedRun]
+	vectorStores *store[storedVectorStore]
+}
+
+// NewServer starts a fake server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		files:        newStore[storedFile](),
+		batches:      newStore[openai.Batch](),
+		assistants:   newStore[openai.Assistant](),
+		threads:      newStore[storedThread](),
+		runs:         newStore[storedRun](),
+		vectorStores: newStore[storedVectorStore](),
+	}
+	s.registerChat()
+	s.registerEmbeddings()
+	s.registerFiles()
+	s.registerBatches()
+	s.registerAssistants()
+	s.registerThreads()
+	s.registerVectorStores()
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// ClientConfig returns a config for clients of the server.
+func (s *Server) ClientConfig() openai.ClientConfig {
+	config := openai.DefaultConfig(Token)
+	config.BaseURL = s.URL + apiPrefix
+	config.HTTPClient = s.server.Client()
+	return config
+}
+
+// Client returns a client of the server.
+func (s *Server) Client() *openai.Client {
+	return openai.NewClientWithConfig(s.ClientConfig())
+}
+
+// newID returns a new ID starting with prefix, e.g. "file-".
+func (s *Server) newID(prefix string) string {
+	s.ids++
+	return prefix + strconv.Itoa(s.ids)
+}
+
+func now() int64 {
+	return time.Now().Unix()
+}
+
+// route is an endpoint of the fake. pattern matches the path after /v1, with
+// a group for every ID in it.
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(w http.ResponseWriter, r *http.Request, ids []string)
+}
+
+// handle registers an endpoint, where every {id} in path matches an ID.
+func (s *Server) handle(method, path string, handle func(w http.ResponseWriter, r *http.Request, ids []string)) {
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\{id\}`, "([^/]+)") + "$"
+	s.routes = append(s.routes, route{method: method, pattern: regexp.MustCompile(pattern), handle: handle})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   apiPath(r.URL.Path),
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	failure, failed := s.nextFailure(r.Method, apiPath(r.URL.Path))
+	s.mu.Unlock()
+
+	if failed && failure.MidStream && isStreamRequest(body) {
+		r = r.WithContext(withStreamFailure(r.Context(), failure))
+	} else if failed {
+		failure.write(w)
+		return
+	}
+	s.route(w, r)
+}
+
+// route serves r without recording it, which batches use to run their requests.
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	path := apiPath(r.URL.Path)
+	methodAllowed := true
+	for _, rt := range s.routes {
+		matches := rt.pattern.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		if rt.method != r.Method {
+			methodAllowed = false
+			continue
+		}
+		rt.handle(w, r, matches[1:])
+		return
+	}
+	if !methodAllowed {
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request_error",
+			fmt.Sprintf("%s is not allowed on %s", r.Method, path))
+		return
+	}
+	writeError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("unknown path %s", path))
+}
+
+func isStreamRequest(body []byte) bool {
+	var request struct {
+		Stream bool `json:"stream"`
+	}
+	return json.Unmarshal(body, &request) == nil && request.Stream
+}
+
+// apiPath strips the /v1 prefix of path.
+func apiPath(path string) string {
+	if trimmed := strings.TrimPrefix(path, apiPrefix); strings.HasPrefix(trimmed, "/") {
+		return trimmed
+	}
+	return path
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-request-id", fmt.Sprintf("req_%d", time.Now().UnixNano()))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType, message string) {
+	writeJSON(w, status, openai.ErrorResponse{Error: &openai.APIError{Type: errorType, Message: message}})
+}
+
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("No %s found with id '%s'.", kind, id))
+}
+
+// decodeJSON decodes the body of r into v, answering 400 when it can't.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	// Path is the path of the request without the /v1 prefix, e.g. "/chat/completions".
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// DecodeJSON decodes the JSON body of the request into v.
+func (r Request) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received with method on path, which may be
+// given with or without the /v1 prefix and may contain * wildcards, e.g.
+// "/threads/*/runs".
+func (s *Server) RequestsTo(method, path string) []Request {
+	pattern := pathPattern(path)
+	var matching []Request
+	for _, r := range s.Requests() {
+		if r.Method == method && pattern.MatchString(r.Path) {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+func pathPattern(path string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(apiPath(path)), `\*`, "[^/]+") + "$")
+}
+
+// AssertRequested fails t unless a request was received with method on path,
+// and returns the last one.
+func (s *Server) AssertRequested(t testing.TB, method, path string) Request {
+	t.Helper()
+	requests := s.RequestsTo(method, path)
+	if len(requests) == 0 {
+		t.Fatalf("expected a %s %s request, got none", method, path)
+		return Request{}
+	}
+	return requests[len(requests)-1]
+}
+
+// AssertNotRequested fails t if a request was received with method on path.
+func (s *Server) AssertNotRequested(t testing.TB, method, path string) {
+	t.Helper()
+	if requests := s.RequestsTo(method, path); len(requests) > 0 {
+		t.Errorf("expected no %s %s request, got %d", method, path, len(requests))
+	}
+}
+
+// AssertRequestCount fails t unless n requests were received with method on path.
+func (s *Server) AssertRequestCount(t testing.TB, method, path string, n int) {
+	t.Helper()
+	if requests := s.RequestsTo(method, path); len(requests) != n {
+		t.Errorf("expected %d %s %s requests, got %d", n, method, path, len(requests))
+	}
+}
+
+// Reset forgets the received requests and pending failures and replies,
+// keeping the stored objects.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.failures = nil
+	s.chatReplies = nil
+	s.runScripts = nil
+}
+
+// store holds objects by ID in creation order.
+type store[T any] struct {
+	items map[string]*T
+	order []string
+}
+
+func newStore[T any]() *store[T] {
+	return &store[T]{items: map[string]*T{}}
+}
+
+func (st *store[T]) add(id string, item *T) {
+	st.items[id] = item
+	st.order = append(st.order, id)
+}
+
+func (st *store[T]) get(id string) (*T, bool) {
+	item, ok := st.items[id]
+	return item, ok
+}
+
+func (st *store[T]) remove(id string) bool {
+	if _, ok := st.items[id]; !ok {
+		return false
+	}
+	delete(st.items, id)
+	for i, other := range st.order {
+		if other == id {
+			st.order = append(st.order[:i], st.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// list returns the objects in creation order.
+func (st *store[T]) list() []*T {
+	items := make([]*T, len(st.order))
+	for i, id := range st.order {
+		items[i] = st.items[id]
+	}
+	return items
+}
+
+// page applies the limit, order and after query parameters of r to ids,
+// which are in creation order, newest last. Lists are newest first unless
+// order=asc.
+func page(r *http.Request, ids []string) (selected []string, hasMore bool) {
+	query := r.URL.Query()
+	ids = append([]string(nil), ids...)
+	if query.Get("order") != "asc" {
+		for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+	}
+	if after := query.Get("after"); after != "" {
+		for i, id := range ids {
+			if id == after {
+				ids = ids[i+1:]
+				break
+			}
+		}
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit < len(ids) {
+		return ids[:limit], true
+	}
+	return ids, false
+}
+
+// firstLast returns the first and last of ids, for the first_id and last_id of lists.
+func firstLast(ids []string) (first, last *string) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], &ids[len(ids)-1]
+}
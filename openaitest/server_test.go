@@ -0,0 +1,379 @@
+package openaitest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/openaitest"
+)
+
+func chatRequest(content string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: content}},
+	}
+}
+
+// readStream returns the content and the tool call arguments of a chat
+// completion stream, with the usage it ended with.
+func readStream(stream *openai.ChatCompletionStream) (string, []openai.ToolCall, *openai.Usage, error) {
+	defer stream.Close()
+	var content string
+	var calls []openai.ToolCall
+	var usage *openai.Usage
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return content, calls, usage, nil
+		}
+		if err != nil {
+			return content, calls, usage, err
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		content += chunk.Choices[0].Delta.Content
+		for _, call := range chunk.Choices[0].Delta.ToolCalls {
+			if call.Index == nil {
+				continue
+			}
+			if *call.Index == len(calls) {
+				calls = append(calls, call)
+				continue
+			}
+			calls[*call.Index].Function.Arguments += call.Function.Arguments
+		}
+	}
+}
+
+func TestServerChat(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	server.ScriptChat(
+		openaitest.ChatReply{Content: "Paris is the capital."},
+		openaitest.ChatReply{ToolCalls: []openai.ToolCall{{
+			Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+	)
+	response, err := client.CreateChatCompletion(ctx, chatRequest("Capital of France?"))
+	checks.NoErrorF(t, err, "CreateChatCompletion error")
+	if response.Choices[0].Message.Content != "Paris is the capital." ||
+		response.Choices[0].FinishReason != openai.FinishReasonStop || response.Usage.TotalTokens != 7 {
+		t.Fatalf("unexpected scripted response %+v", response)
+	}
+
+	request := chatRequest("Weather?")
+	request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	stream, err := client.CreateChatCompletionStream(ctx, request)
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	_, calls, usage, err := readStream(stream)
+	checks.NoErrorF(t, err, "stream error")
+	if len(calls) != 1 || calls[0].ID == "" || calls[0].Function.Name != "get_weather" ||
+		calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("unexpected streamed tool calls %+v", calls)
+	}
+	if usage == nil || usage.PromptTokens != 1 {
+		t.Fatalf("expected the usage to be streamed, got %+v", usage)
+	}
+
+	// Without a scripted reply, the last user message is echoed.
+	stream, err = client.CreateChatCompletionStream(ctx, chatRequest("Say this back"))
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	content, _, usage, err := readStream(stream)
+	checks.NoErrorF(t, err, "stream error")
+	if content != "Say this back" || usage != nil {
+		t.Fatalf("expected the message to be echoed without usage, got %q and %+v", content, usage)
+	}
+
+	server.AssertRequestCount(t, http.MethodPost, "/chat/completions", 3)
+	server.AssertNotRequested(t, http.MethodPost, "/embeddings")
+	var last openai.ChatCompletionRequest
+	checks.NoError(t, server.AssertRequested(t, http.MethodPost, "/v1/chat/completions").DecodeJSON(&last),
+		"DecodeJSON error")
+	if !last.Stream || last.Messages[0].Content != "Say this back" {
+		t.Fatalf("unexpected last request %+v", last)
+	}
+	if got := server.Requests()[0].Header.Get("Authorization"); got != "Bearer "+openaitest.Token {
+		t.Fatalf("unexpected Authorization header %q", got)
+	}
+}
+
+func TestServerEmbeddings(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	response, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: []string{"a", "b", "a"},
+		Model: openai.SmallEmbedding3,
+	})
+	checks.NoErrorF(t, err, "CreateEmbeddings error")
+	if len(response.Data) != 3 || len(response.Data[0].Embedding) != 1536 ||
+		!reflect.DeepEqual(response.Data[0].Embedding, response.Data[2].Embedding) ||
+		reflect.DeepEqual(response.Data[0].Embedding, response.Data[1].Embedding) {
+		t.Fatal("expected equal inputs, and only those, to get equal embeddings")
+	}
+	if !reflect.DeepEqual(response.Data[1].Embedding, openaitest.EmbeddingVector("b", 1536)) {
+		t.Fatal("expected the embeddings of EmbeddingVector")
+	}
+	similarity, err := response.Data[0].DotProduct(&response.Data[0])
+	checks.NoError(t, err, "DotProduct error")
+	if similarity < 0.999 || similarity > 1.001 {
+		t.Fatalf("expected unit vectors, got a norm of %v", similarity)
+	}
+
+	response, err = client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input:          "b",
+		Model:          openai.SmallEmbedding3,
+		EncodingFormat: openai.EmbeddingEncodingFormatBase64,
+		Dimensions:     8,
+	})
+	checks.NoErrorF(t, err, "CreateEmbeddings error")
+	if !reflect.DeepEqual(response.Data[0].Embedding, openaitest.EmbeddingVector("b", 8)) {
+		t.Fatalf("unexpected base64 embedding %v", response.Data[0].Embedding)
+	}
+}
+
+func TestServerBatches(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	server.ScriptChat(openaitest.ChatReply{Content: "first"})
+	input := openai.UploadBatchFileRequest{FileName: "input.jsonl"}
+	input.AddChatCompletion("ok", chatRequest("Hi"))
+	input.AddChatCompletion("invalid", openai.ChatCompletionRequest{Model: openai.GPT4oMini})
+	batch, err := client.CreateBatchWithUploadFile(ctx, openai.CreateBatchWithUploadFileRequest{
+		Endpoint:               openai.BatchEndpointChatCompletions,
+		UploadBatchFileRequest: input,
+	})
+	checks.NoErrorF(t, err, "CreateBatchWithUploadFile error")
+	if batch.Status != "in_progress" || batch.RequestCounts.Total != 2 {
+		t.Fatalf("unexpected new batch %+v", batch.Batch)
+	}
+
+	checks.NoErrorF(t, server.CompleteBatch(batch.ID), "CompleteBatch error")
+	if err = server.CompleteBatch(batch.ID); !errors.Is(err, openaitest.ErrBatchNotRunning) {
+		t.Fatalf("expected ErrBatchNotRunning, got %v", err)
+	}
+	if err = server.CompleteBatch("batch_missing"); !errors.Is(err, openaitest.ErrBatchNotFound) {
+		t.Fatalf("expected ErrBatchNotFound, got %v", err)
+	}
+	batch, err = client.RetrieveBatch(ctx, batch.ID)
+	checks.NoErrorF(t, err, "RetrieveBatch error")
+	if batch.Status != "completed" || batch.RequestCounts.Completed != 1 || batch.RequestCounts.Failed != 1 {
+		t.Fatalf("unexpected completed batch %+v", batch.Batch)
+	}
+
+	results, err := client.ReadBatchResults(ctx, batch.Batch).CollectByCustomID()
+	checks.NoErrorF(t, err, "ReadBatchResults error")
+	if results["ok"].ChatCompletion == nil || results["ok"].ChatCompletion.Choices[0].Message.Content != "first" {
+		t.Fatalf("unexpected result %+v", results["ok"])
+	}
+	if results["invalid"].StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the invalid request to fail, got %+v", results["invalid"])
+	}
+	// Batch requests are answered by the server without being recorded.
+	server.AssertNotRequested(t, http.MethodPost, "/chat/completions")
+
+	files, err := client.ListFiles(ctx)
+	checks.NoError(t, err, "ListFiles error")
+	if len(files.Files) != 3 {
+		t.Fatalf("expected the input, output and error files, got %+v", files.Files)
+	}
+	checks.NoError(t, client.DeleteFile(ctx, batch.InputFileID), "DeleteFile error")
+	if _, err = client.GetFile(ctx, batch.InputFileID); err == nil {
+		t.Fatal("expected a deleted file not to be found")
+	}
+}
+
+// waitForRun polls the run until it leaves the queued and in_progress states.
+func waitForRun(t *testing.T, client *openai.Client, run openai.Run) openai.Run {
+	t.Helper()
+	for run.Status == openai.RunStatusQueued || run.Status == openai.RunStatusInProgress ||
+		run.Status == openai.RunStatusCancelling {
+		var err error
+		run, err = client.RetrieveRun(context.Background(), run.ThreadID, run.ID)
+		checks.NoErrorF(t, err, "RetrieveRun error")
+	}
+	return run
+}
+
+func TestServerRuns(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	assistant, err := client.CreateAssistant(ctx, openai.AssistantRequest{Model: openai.GPT4oMini})
+	checks.NoErrorF(t, err, "CreateAssistant error")
+	server.ScriptRun(
+		openaitest.RunState{Status: openai.RunStatusInProgress},
+		openaitest.RunState{Status: openai.RunStatusRequiresAction, ToolCalls: []openai.ToolCall{{
+			Function: openai.FunctionCall{Name: "lookup", Arguments: "{}"},
+		}}},
+		openaitest.RunState{Status: openai.RunStatusCompleted, Reply: "Found it."},
+	)
+	run, err := client.CreateThreadAndRun(ctx, openai.CreateThreadAndRunRequest{
+		RunRequest: openai.RunRequest{AssistantID: assistant.ID},
+		Thread: openai.ThreadRequest{Messages: []openai.ThreadMessage{{
+			Role: openai.ThreadMessageRoleUser, Content: "Find it",
+		}}},
+	})
+	checks.NoErrorF(t, err, "CreateThreadAndRun error")
+	run = waitForRun(t, client, run)
+	if run.Status != openai.RunStatusRequiresAction || run.Model != openai.GPT4oMini {
+		t.Fatalf("expected the run to require action, got %+v", run)
+	}
+	call := run.RequiredAction.SubmitToolOutputs.ToolCalls[0]
+	run, err = client.SubmitToolOutputs(ctx, run.ThreadID, run.ID, openai.SubmitToolOutputsRequest{
+		ToolOutputs: []openai.ToolOutput{{ToolCallID: call.ID, Output: "here"}},
+	})
+	checks.NoErrorF(t, err, "SubmitToolOutputs error")
+	if run.Status != openai.RunStatusCompleted {
+		t.Fatalf("expected the run to complete, got %s", run.Status)
+	}
+	messages, err := client.ListMessage(ctx, run.ThreadID, openai.ListMessageOptions{})
+	checks.NoErrorF(t, err, "ListMessage error")
+	if len(messages.Messages) != 2 || messages.Messages[0].Content[0].Text.Value != "Found it." ||
+		*messages.Messages[0].RunID != run.ID {
+		t.Fatalf("expected the reply to be the newest message, got %+v", messages.Messages)
+	}
+
+	// Unscripted runs echo the thread, and cancelled ones end cancelled.
+	_, err = client.CreateMessage(ctx, run.ThreadID, openai.MessageRequest{Role: "user", Content: "Echo"})
+	checks.NoErrorF(t, err, "CreateMessage error")
+	run, err = client.CreateRun(ctx, run.ThreadID, openai.RunRequest{AssistantID: assistant.ID})
+	checks.NoErrorF(t, err, "CreateRun error")
+	if run = waitForRun(t, client, run); run.Status != openai.RunStatusCompleted {
+		t.Fatalf("expected the run to complete, got %s", run.Status)
+	}
+	run, err = client.CreateRun(ctx, run.ThreadID, openai.RunRequest{AssistantID: assistant.ID})
+	checks.NoErrorF(t, err, "CreateRun error")
+	run, err = client.CancelRun(ctx, run.ThreadID, run.ID)
+	checks.NoErrorF(t, err, "CancelRun error")
+	if run = waitForRun(t, client, run); run.Status != openai.RunStatusCancelled {
+		t.Fatalf("expected the run to be cancelled, got %s", run.Status)
+	}
+
+	runs, err := client.ListRuns(ctx, run.ThreadID, openai.Pagination{})
+	checks.NoErrorF(t, err, "ListRuns error")
+	messages, err = client.ListMessage(ctx, run.ThreadID, openai.ListMessageOptions{})
+	checks.NoErrorF(t, err, "ListMessage error")
+	if len(runs.Runs) != 3 || len(messages.Messages) != 4 || messages.Messages[0].Content[0].Text.Value != "Echo" {
+		t.Fatalf("unexpected runs %+v and messages %+v", runs.Runs, messages.Messages)
+	}
+	server.AssertRequested(t, http.MethodPost, "/threads/*/runs/*/submit_tool_outputs")
+}
+
+func TestServerVectorStores(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	var fileIDs []string
+	for _, content := range []string{"the cat sat", "a dog barked", "the cat and the dog"} {
+		file, err := client.CreateFileBytes(ctx, openai.FileBytesRequest{
+			Name:    "doc.txt",
+			Bytes:   []byte(content),
+			Purpose: openai.PurposeAssistants,
+		})
+		checks.NoErrorF(t, err, "CreateFileBytes error")
+		fileIDs = append(fileIDs, file.ID)
+	}
+	vectorStore, err := client.CreateVectorStore(ctx, openai.VectorStoreRequest{Name: "docs", FileIDs: fileIDs[:1]})
+	checks.NoErrorF(t, err, "CreateVectorStore error")
+	batch, err := client.CreateVectorStoreFileBatch(ctx, vectorStore.ID, openai.VectorStoreFileBatchRequest{
+		FileIDs: append(fileIDs[1:], "file-missing"),
+	})
+	checks.NoErrorF(t, err, "CreateVectorStoreFileBatch error")
+	if batch.Status != "completed" || batch.FileCounts.Completed != 2 || batch.FileCounts.Failed != 1 {
+		t.Fatalf("unexpected file batch %+v", batch)
+	}
+	vectorStore, err = client.RetrieveVectorStore(ctx, vectorStore.ID)
+	checks.NoErrorF(t, err, "RetrieveVectorStore error")
+	if vectorStore.FileCounts.Total != 3 {
+		t.Fatalf("expected 3 files, got %+v", vectorStore.FileCounts)
+	}
+
+	results, err := client.SearchVectorStore(ctx, vectorStore.ID, openai.VectorStoreSearchRequest{Query: "cat dog"})
+	checks.NoErrorF(t, err, "SearchVectorStore error")
+	if len(results.Data) != 3 || results.Data[0].FileID != fileIDs[2] || results.Data[0].Score != 1 {
+		t.Fatalf("unexpected search results %+v", results.Data)
+	}
+	checks.NoError(t, client.DeleteVectorStoreFile(ctx, vectorStore.ID, fileIDs[0]), "DeleteVectorStoreFile error")
+	files, err := client.ListVectorStoreFiles(ctx, vectorStore.ID, openai.Pagination{})
+	checks.NoErrorF(t, err, "ListVectorStoreFiles error")
+	if len(files.VectorStoreFiles) != 2 {
+		t.Fatalf("expected 2 files after a deletion, got %+v", files.VectorStoreFiles)
+	}
+}
+
+func TestServerFailures(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	server.FailNext(http.MethodPost, "/chat/completions",
+		openaitest.RateLimitFailure(1500*time.Millisecond), openaitest.ServerFailure())
+	_, err := client.CreateChatCompletion(ctx, chatRequest("Hi"))
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests ||
+		apiErr.Code != "rate_limit_exceeded" {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	_, err = client.CreateChatCompletion(ctx, chatRequest("Hi"))
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+	_, err = client.CreateChatCompletion(ctx, chatRequest("Hi"))
+	checks.NoError(t, err, "expected failures to be used once")
+
+	server.FailNext(http.MethodPost, "/chat/completions", openaitest.StreamFailure(2))
+	stream, err := client.CreateChatCompletionStream(ctx, chatRequest("one two three"))
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	content, _, _, err := readStream(stream)
+	if err == nil || content != "one" {
+		t.Fatalf("expected the stream to fail after a word, got %q and %v", content, err)
+	}
+
+	server.FailNext(http.MethodGet, "/threads/*", openaitest.RateLimitFailure(time.Second))
+	_, err = client.RetrieveThread(ctx, "thread_1")
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected a wildcard failure, got %v", err)
+	}
+
+	// Failures of overlapping paths are used in the order they were registered.
+	for i := 0; i < 10; i++ {
+		server.FailNext(http.MethodGet, "/threads/*", openaitest.ServerFailure())
+		server.FailNext(http.MethodGet, "/threads/thread_1", openaitest.RateLimitFailure(time.Second))
+		for _, status := range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+			_, err = client.RetrieveThread(ctx, "thread_1")
+			if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != status {
+				t.Fatalf("expected a %d failure, got %v", status, err)
+			}
+		}
+	}
+
+	server.Reset()
+	if len(server.Requests()) != 0 {
+		t.Fatal("expected Reset to forget the requests")
+	}
+}
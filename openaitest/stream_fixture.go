@@ -0,0 +1,254 @@
+package openaitest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// StreamFixture builds a server-sent event stream of chat completion chunks,
+// with the faults stream consumers must survive: slow chunks, reads ending
+// in the middle of a UTF-8 sequence, keep-alive comments, error events,
+// truncated connections and a missing [DONE]. A fixture is served by a fake
+// server as a ChatReply, by itself as an http.Handler, or read as an
+// io.Reader:
+//
+//	fixture := openaitest.NewStreamFixture().
+//		Content("Hé", "llo").
+//		KeepAlive().
+//		Delay(50 * time.Millisecond).
+//		Content(" wörld").
+//		Fragment(3).
+//		Done()
+//	server.ScriptChat(openaitest.ChatReply{Stream: fixture})
+//
+// Events are written in the order they are added. The stream ends with
+// [DONE] only when Done is called.
+type StreamFixture struct {
+	events   []streamEvent
+	fragment int
+	interval time.Duration
+}
+
+// streamEvent is bytes written to the stream after a delay, or the end of a
+// truncated stream.
+type streamEvent struct {
+	data     []byte
+	delay    time.Duration
+	truncate bool
+}
+
+// NewStreamFixture returns an empty fixture.
+func NewStreamFixture() *StreamFixture {
+	return &StreamFixture{}
+}
+
+func (f *StreamFixture) add(data []byte) *StreamFixture {
+	f.events = append(f.events, streamEvent{data: data})
+	return f
+}
+
+// Chunk adds an event for each of chunks.
+func (f *StreamFixture) Chunk(chunks ...openai.ChatCompletionStreamResponse) *StreamFixture {
+	for _, chunk := range chunks {
+		data, _ := json.Marshal(chunk)
+		f.Data(string(data))
+	}
+	return f
+}
+
+// Content adds a chunk for each of contents, with the content as its delta.
+func (f *StreamFixture) Content(contents ...string) *StreamFixture {
+	for _, content := range contents {
+		f.Chunk(openai.ChatCompletionStreamResponse{
+			Object: "chat.completion.chunk",
+			Choices: []openai.ChatCompletionStreamChoice{{
+				Delta: openai.ChatCompletionStreamChoiceDelta{Content: content},
+			}},
+		})
+	}
+	return f
+}
+
+// Data adds an event with data as its data line.
+func (f *StreamFixture) Data(data string) *StreamFixture {
+	return f.add([]byte("data: " + data + "\n\n"))
+}
+
+// KeepAlive adds a comment, which servers send to keep idle connections open.
+func (f *StreamFixture) KeepAlive() *StreamFixture {
+	return f.add([]byte(": keep-alive\n\n"))
+}
+
+// Error adds an error event, as the API sends when a request fails after
+// the stream started.
+func (f *StreamFixture) Error(apiErr openai.APIError) *StreamFixture {
+	data, _ := json.Marshal(openai.ErrorResponse{Error: &apiErr})
+	return f.Data(string(data))
+}
+
+// Raw adds data as it is, e.g. half an event before Truncate.
+func (f *StreamFixture) Raw(data string) *StreamFixture {
+	return f.add([]byte(data))
+}
+
+// Done adds the [DONE] event ending a complete stream.
+func (f *StreamFixture) Done() *StreamFixture {
+	return f.Data("[DONE]")
+}
+
+// Truncate ends the stream as a dropped connection would: readers get
+// io.ErrUnexpectedEOF and clients of a served fixture see the connection
+// closed without the end of the response. Events added later are not sent.
+func (f *StreamFixture) Truncate() *StreamFixture {
+	f.events = append(f.events, streamEvent{truncate: true})
+	return f
+}
+
+// Delay pauses the stream for d before the next event.
+func (f *StreamFixture) Delay(d time.Duration) *StreamFixture {
+	f.events = append(f.events, streamEvent{delay: d})
+	return f
+}
+
+// Interval pauses the stream for d before every event, on top of delays.
+func (f *StreamFixture) Interval(d time.Duration) *StreamFixture {
+	f.interval = d
+	return f
+}
+
+// Fragment splits every event into writes, and reads, of at most size bytes,
+// which splits multi-byte UTF-8 sequences and lines across reads. Zero
+// writes every event at once.
+func (f *StreamFixture) Fragment(size int) *StreamFixture {
+	f.fragment = size
+	return f
+}
+
+// Bytes returns the stream without its delays, up to where it is truncated.
+func (f *StreamFixture) Bytes() []byte {
+	var data []byte
+	for _, event := range f.events {
+		if event.truncate {
+			break
+		}
+		data = append(data, event.data...)
+	}
+	return data
+}
+
+// write is one write of the stream: data after delay, or the end of a
+// truncated stream.
+type write struct {
+	data     []byte
+	delay    time.Duration
+	truncate bool
+}
+
+// writes returns the writes making up the stream.
+func (f *StreamFixture) writes() []write {
+	var writes []write
+	var delay time.Duration
+	for _, event := range f.events {
+		if event.data == nil && !event.truncate {
+			delay += event.delay
+			continue
+		}
+		if event.truncate {
+			return append(writes, write{delay: delay, truncate: true})
+		}
+		delay += f.interval
+		data := event.data
+		for len(data) > 0 {
+			size := len(data)
+			if f.fragment > 0 && f.fragment < size {
+				size = f.fragment
+			}
+			writes = append(writes, write{data: data[:size], delay: delay})
+			data, delay = data[size:], 0
+		}
+	}
+	return writes
+}
+
+// ServeHTTP writes the stream as the response to r, flushing every write.
+// It stops when the request is cancelled.
+func (f *StreamFixture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// The headers are sent at once, as the API does, so delays hold back chunks only.
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for _, wr := range f.writes() {
+		if !sleep(r.Context(), wr.delay) {
+			return
+		}
+		if wr.truncate {
+			// Aborting the handler closes the connection mid-response.
+			panic(http.ErrAbortHandler)
+		}
+		if _, err := w.Write(wr.data); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Reader returns a reader of the stream. Every read returns at most one
+// write of the stream, after its delay.
+func (f *StreamFixture) Reader() io.Reader {
+	return &streamFixtureReader{writes: f.writes()}
+}
+
+type streamFixtureReader struct {
+	writes []write
+	// pending is the rest of the current write, which a short read left.
+	pending   []byte
+	truncated bool
+}
+
+func (r *streamFixtureReader) Read(p []byte) (int, error) {
+	if r.truncated {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(r.pending) == 0 {
+		if len(r.writes) == 0 {
+			return 0, io.EOF
+		}
+		next := r.writes[0]
+		r.writes = r.writes[1:]
+		time.Sleep(next.delay)
+		if next.truncate {
+			r.truncated = true
+			return 0, io.ErrUnexpectedEOF
+		}
+		r.pending = next.data
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
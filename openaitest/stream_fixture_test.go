@@ -0,0 +1,109 @@
+package openaitest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/openaitest"
+)
+
+func TestStreamFixtureReader(t *testing.T) {
+	fixture := openaitest.NewStreamFixture().
+		Content("Hé").
+		KeepAlive().
+		Delay(30 * time.Millisecond).
+		Error(openai.APIError{Type: "server_error", Message: "boom"}).
+		Fragment(2)
+	stream := string(fixture.Bytes())
+	tail := "\n\n: keep-alive\n\n" + `data: {"error":{"message":"boom","type":"server_error"}}` + "\n\n"
+	if !strings.HasPrefix(stream, `data: {"id":`) || !strings.Contains(stream, `"content":"Hé"`) ||
+		!strings.HasSuffix(stream, tail) {
+		t.Fatalf("unexpected stream %q", stream)
+	}
+
+	reader := fixture.Reader()
+	var read []byte
+	buf := make([]byte, 16)
+	start := time.Now()
+	for {
+		n, err := reader.Read(buf)
+		if n > 2 {
+			t.Fatalf("expected reads of at most 2 bytes, got %d", n)
+		}
+		read = append(read, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, err, "Read error")
+	}
+	if !bytes.Equal(read, fixture.Bytes()) {
+		t.Fatalf("expected the reader to return the stream, got %q", read)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected the delay to be honored, took %v", elapsed)
+	}
+
+	truncated := openaitest.NewStreamFixture().Content("a").Raw(`data: {"choi`).Truncate().Done()
+	read, err := io.ReadAll(truncated.Reader())
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !bytes.HasSuffix(read, []byte(`data: {"choi`)) {
+		t.Fatalf("expected a truncated stream, got %q and %v", read, err)
+	}
+	if bytes.Contains(truncated.Bytes(), []byte("[DONE]")) {
+		t.Fatal("expected events after the truncation to be dropped")
+	}
+}
+
+func TestStreamFixtureServed(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	request := chatRequest("Hi")
+	request.Stream = true
+	stream := func(fixture *openaitest.StreamFixture) (string, error) {
+		server.ScriptChat(openaitest.ChatReply{Stream: fixture})
+		chatStream, err := client.CreateChatCompletionStream(ctx, request)
+		checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+		content, _, _, err := readStream(chatStream)
+		return content, err
+	}
+
+	// Every byte is read separately, splitting UTF-8 sequences.
+	content, err := stream(openaitest.NewStreamFixture().
+		Content("Hé", "llo").KeepAlive().Content(" wörld", " 🌍").Fragment(1).Interval(time.Millisecond).Done())
+	checks.NoError(t, err, "stream error")
+	if content != "Héllo wörld 🌍" {
+		t.Fatalf("unexpected content %q", content)
+	}
+
+	content, err = stream(openaitest.NewStreamFixture().
+		Content("partial").Error(openai.APIError{Type: "server_error", Message: "overloaded"}))
+	if err == nil || !strings.Contains(err.Error(), "overloaded") || content != "partial" {
+		t.Fatalf("expected the error event to fail the stream, got %q and %v", content, err)
+	}
+
+	content, err = stream(openaitest.NewStreamFixture().Content("partial").Raw("data: {").Truncate())
+	if err == nil || errors.Is(err, io.EOF) || content != "partial" {
+		t.Fatalf("expected the truncated stream to fail, got %q and %v", content, err)
+	}
+
+	// Fixtures are also handlers of their own.
+	httpServer := httptest.NewServer(openaitest.NewStreamFixture().Content("direct").Done())
+	defer httpServer.Close()
+	config := openai.DefaultConfig(openaitest.Token)
+	config.BaseURL = httpServer.URL
+	chatStream, err := openai.NewClientWithConfig(config).CreateChatCompletionStream(ctx, request)
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	if content, _, _, err = readStream(chatStream); err != nil || content != "direct" {
+		t.Fatalf("unexpected stream %q and %v", content, err)
+	}
+}
@@ -0,0 +1,364 @@
+package openaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// storedVectorStore is a vector store with its files and file batches.
+type storedVectorStore struct {
+	openai.VectorStore
+	files   *store[openai.VectorStoreFile]
+	batches *store[storedFileBatch]
+}
+
+// storedFileBatch is a vector store file batch with the IDs of its files.
+type storedFileBatch struct {
+	openai.VectorStoreFileBatch
+	fileIDs []string
+}
+
+func (s *Server) registerVectorStores() {
+	s.handle(http.MethodPost, "/vector_stores", s.createVectorStore)
+	s.handle(http.MethodGet, "/vector_stores", s.listVectorStores)
+	s.handle(http.MethodGet, "/vector_stores/{id}", s.getVectorStore)
+	s.handle(http.MethodPost, "/vector_stores/{id}", s.modifyVectorStore)
+	s.handle(http.MethodDelete, "/vector_stores/{id}", s.deleteVectorStore)
+	s.handle(http.MethodPost, "/vector_stores/{id}/search", s.searchVectorStore)
+	s.handle(http.MethodPost, "/vector_stores/{id}/files", s.createVectorStoreFile)
+	s.handle(http.MethodGet, "/vector_stores/{id}/files", s.listVectorStoreFiles)
+	s.handle(http.MethodGet, "/vector_stores/{id}/files/{id}", s.getVectorStoreFile)
+	s.handle(http.MethodDelete, "/vector_stores/{id}/files/{id}", s.deleteVectorStoreFile)
+	s.handle(http.MethodPost, "/vector_stores/{id}/file_batches", s.createVectorStoreFileBatch)
+	s.handle(http.MethodGet, "/vector_stores/{id}/file_batches/{id}", s.getVectorStoreFileBatch)
+	s.handle(http.MethodPost, "/vector_stores/{id}/file_batches/{id}/cancel", s.getVectorStoreFileBatch)
+	s.handle(http.MethodGet, "/vector_stores/{id}/file_batches/{id}/files", s.listVectorStoreFileBatchFiles)
+}
+
+// vectorStore returns the vector store with id, answering 404 when there is
+// none. The caller must hold s.mu.
+func (s *Server) vectorStore(w http.ResponseWriter, id string) (*storedVectorStore, bool) {
+	vectorStore, ok := s.vectorStores.get(id)
+	if !ok {
+		writeNotFound(w, "vector store", id)
+	}
+	return vectorStore, ok
+}
+
+// addVectorStoreFile adds the uploaded file with fileID to vectorStore,
+// where it is processed at once. The caller must hold s.mu.
+func (s *Server) addVectorStoreFile(
+	vectorStore *storedVectorStore,
+	fileID string,
+	attributes map[string]any,
+) (*openai.VectorStoreFile, bool) {
+	file, ok := s.files.get(fileID)
+	if !ok {
+		return nil, false
+	}
+	if existing, exists := vectorStore.files.get(fileID); exists {
+		return existing, true
+	}
+	vectorStoreFile := &openai.VectorStoreFile{
+		ID:            fileID,
+		Object:        "vector_store.file",
+		CreatedAt:     now(),
+		VectorStoreID: vectorStore.ID,
+		UsageBytes:    len(file.content),
+		Status:        "completed",
+		Attributes:    attributes,
+	}
+	vectorStore.files.add(fileID, vectorStoreFile)
+	vectorStore.UsageBytes += len(file.content)
+	vectorStore.FileCounts.Completed++
+	vectorStore.FileCounts.Total++
+	return vectorStoreFile, true
+}
+
+func (s *Server) createVectorStore(w http.ResponseWriter, r *http.Request, _ []string) {
+	var request openai.VectorStoreRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore := &storedVectorStore{
+		VectorStore: openai.VectorStore{
+			ID:           s.newID("vs_"),
+			Object:       "vector_store",
+			CreatedAt:    now(),
+			Name:         request.Name,
+			Status:       "completed",
+			ExpiresAfter: request.ExpiresAfter,
+			Metadata:     request.Metadata,
+		},
+		files:   newStore[openai.VectorStoreFile](),
+		batches: newStore[storedFileBatch](),
+	}
+	for _, fileID := range request.FileIDs {
+		if _, ok := s.addVectorStoreFile(vectorStore, fileID, nil); !ok {
+			writeNotFound(w, "file", fileID)
+			return
+		}
+	}
+	s.vectorStores.add(vectorStore.ID, vectorStore)
+	writeJSON(w, http.StatusOK, vectorStore.VectorStore)
+}
+
+func (s *Server) listVectorStores(w http.ResponseWriter, r *http.Request, _ []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, hasMore := page(r, s.vectorStores.order)
+	list := openai.VectorStoresList{VectorStores: []openai.VectorStore{}, HasMore: hasMore}
+	for _, id := range ids {
+		vectorStore, _ := s.vectorStores.get(id)
+		list.VectorStores = append(list.VectorStores, vectorStore.VectorStore)
+	}
+	list.FirstID, list.LastID = firstLast(ids)
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) getVectorStore(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if vectorStore, ok := s.vectorStore(w, ids[0]); ok {
+		writeJSON(w, http.StatusOK, vectorStore.VectorStore)
+	}
+}
+
+func (s *Server) modifyVectorStore(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.VectorStoreRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	if request.Name != "" {
+		vectorStore.Name = request.Name
+	}
+	if request.ExpiresAfter != nil {
+		vectorStore.ExpiresAfter = request.ExpiresAfter
+	}
+	if request.Metadata != nil {
+		vectorStore.Metadata = request.Metadata
+	}
+	writeJSON(w, http.StatusOK, vectorStore.VectorStore)
+}
+
+func (s *Server) deleteVectorStore(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.vectorStores.remove(ids[0]) {
+		writeNotFound(w, "vector store", ids[0])
+		return
+	}
+	writeJSON(w, http.StatusOK, openai.VectorStoreDeleteResponse{
+		ID:      ids[0],
+		Object:  "vector_store.deleted",
+		Deleted: true,
+	})
+}
+
+func (s *Server) createVectorStoreFile(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.VectorStoreFileRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	file, ok := s.addVectorStoreFile(vectorStore, request.FileID, request.Attributes)
+	if !ok {
+		writeNotFound(w, "file", request.FileID)
+		return
+	}
+	writeJSON(w, http.StatusOK, file)
+}
+
+// writeVectorStoreFiles writes the page of the files with fileIDs of vectorStore that r asks for.
+func writeVectorStoreFiles(w http.ResponseWriter, r *http.Request, vectorStore *storedVectorStore, fileIDs []string) {
+	fileIDs, hasMore := page(r, fileIDs)
+	list := openai.VectorStoreFilesList{VectorStoreFiles: []openai.VectorStoreFile{}, HasMore: hasMore}
+	for _, id := range fileIDs {
+		if file, ok := vectorStore.files.get(id); ok {
+			list.VectorStoreFiles = append(list.VectorStoreFiles, *file)
+		}
+	}
+	list.FirstID, list.LastID = firstLast(fileIDs)
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) listVectorStoreFiles(w http.ResponseWriter, r *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if vectorStore, ok := s.vectorStore(w, ids[0]); ok {
+		writeVectorStoreFiles(w, r, vectorStore, vectorStore.files.order)
+	}
+}
+
+func (s *Server) getVectorStoreFile(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	file, ok := vectorStore.files.get(ids[1])
+	if !ok {
+		writeNotFound(w, "vector store file", ids[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, file)
+}
+
+func (s *Server) deleteVectorStoreFile(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	file, ok := vectorStore.files.get(ids[1])
+	if !ok {
+		writeNotFound(w, "vector store file", ids[1])
+		return
+	}
+	vectorStore.files.remove(ids[1])
+	vectorStore.UsageBytes -= file.UsageBytes
+	vectorStore.FileCounts.Completed--
+	vectorStore.FileCounts.Total--
+	writeJSON(w, http.StatusOK, map[string]any{"id": ids[1], "object": "vector_store.file.deleted", "deleted": true})
+}
+
+func (s *Server) createVectorStoreFileBatch(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request openai.VectorStoreFileBatchRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	batch := &storedFileBatch{VectorStoreFileBatch: openai.VectorStoreFileBatch{
+		ID:            s.newID("vsfb_"),
+		Object:        "vector_store.file_batch",
+		CreatedAt:     now(),
+		VectorStoreID: vectorStore.ID,
+		Status:        "completed",
+	}}
+	for _, fileID := range request.FileIDs {
+		if _, ok = s.addVectorStoreFile(vectorStore, fileID, request.Attributes); ok {
+			batch.FileCounts.Completed++
+		} else {
+			batch.FileCounts.Failed++
+		}
+		batch.FileCounts.Total++
+		batch.fileIDs = append(batch.fileIDs, fileID)
+	}
+	vectorStore.batches.add(batch.ID, batch)
+	writeJSON(w, http.StatusOK, batch.VectorStoreFileBatch)
+}
+
+// getVectorStoreFileBatch also answers cancellations, which come too late
+// for batches that complete at once.
+func (s *Server) getVectorStoreFileBatch(w http.ResponseWriter, _ *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	batch, ok := vectorStore.batches.get(ids[1])
+	if !ok {
+		writeNotFound(w, "vector store file batch", ids[1])
+		return
+	}
+	writeJSON(w, http.StatusOK, batch.VectorStoreFileBatch)
+}
+
+func (s *Server) listVectorStoreFileBatchFiles(w http.ResponseWriter, r *http.Request, ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	batch, ok := vectorStore.batches.get(ids[1])
+	if !ok {
+		writeNotFound(w, "vector store file batch", ids[1])
+		return
+	}
+	writeVectorStoreFiles(w, r, vectorStore, batch.fileIDs)
+}
+
+// searchVectorStore ranks the files of the vector store by the share of the
+// query words they contain, returning every file that contains one.
+func (s *Server) searchVectorStore(w http.ResponseWriter, r *http.Request, ids []string) {
+	var request struct {
+		Query         json.RawMessage `json:"query"`
+		MaxNumResults int             `json:"max_num_results"`
+	}
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	var query openai.VectorStoreSearchQuery
+	if err := json.Unmarshal(request.Query, &query); err != nil || len(query) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "query must be a string or strings")
+		return
+	}
+	words := strings.Fields(strings.ToLower(strings.Join(query, " ")))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vectorStore, ok := s.vectorStore(w, ids[0])
+	if !ok {
+		return
+	}
+	results := openai.VectorStoreSearchResults{
+		Object:      "vector_store.search_results.page",
+		SearchQuery: query,
+		Data:        []openai.VectorStoreSearchResult{},
+	}
+	for _, vectorStoreFile := range vectorStore.files.list() {
+		file, exists := s.files.get(vectorStoreFile.ID)
+		if !exists {
+			continue
+		}
+		content := bytes.ToLower(file.content)
+		var found int
+		for _, word := range words {
+			if bytes.Contains(content, []byte(word)) {
+				found++
+			}
+		}
+		if found == 0 {
+			continue
+		}
+		results.Data = append(results.Data, openai.VectorStoreSearchResult{
+			FileID:     file.ID,
+			Filename:   file.FileName,
+			Score:      float64(found) / float64(len(words)),
+			Attributes: vectorStoreFile.Attributes,
+			Content:    []openai.VectorStoreSearchResultContent{{Type: "text", Text: string(file.content)}},
+		})
+	}
+	sort.SliceStable(results.Data, func(i, j int) bool { return results.Data[i].Score > results.Data[j].Score })
+	if request.MaxNumResults > 0 && len(results.Data) > request.MaxNumResults {
+		results.Data, results.HasMore = results.Data[:request.MaxNumResults], true
+	}
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,331 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Seq2 has the same shape as iter.Seq2 from Go 1.23, so on newer Go versions
+// the result of Pager.All can be used directly in a range statement:
+//
+//	for assistant, err := range client.ListAssistantsPager(ctx, openai.Pagination{}, openai.PagerOptions{}).All() {
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// Page is a single page of a cursor-paginated list.
+type Page[T any] struct {
+	Items []T
+	// LastID is the cursor passed as after to fetch the next page.
+	LastID  string
+	HasMore bool
+}
+
+// PageFetcher fetches the page described by pagination.
+type PageFetcher[T any] func(ctx context.Context, pagination Pagination) (Page[T], error)
+
+// PagerOptions limits how much a Pager fetches.
+type PagerOptions struct {
+	// MaxItems stops the pager after this many items. Zero means no limit.
+	MaxItems int
+}
+
+// Pager iterates over every item of a cursor-paginated list, fetching the
+// next page with the after cursor of the previous one as needed.
+//
+//	pager := client.ListAssistantsPager(ctx, openai.Pagination{Limit: &pageSize}, openai.PagerOptions{})
+//	for pager.Next() {
+//		fmt.Println(pager.Current().ID)
+//	}
+//	if err := pager.Err(); err != nil {
+//		return err
+//	}
+type Pager[T any] struct {
+	ctx        context.Context
+	fetch      PageFetcher[T]
+	pagination Pagination
+	options    PagerOptions
+
+	page     Page[T]
+	index    int
+	fetched  bool
+	returned int
+	current  T
+	err      error
+}
+
+// NewPager returns a Pager starting at pagination. Pagination.Limit is used as
+// the page size and Pagination.After as the starting cursor.
+func NewPager[T any](ctx context.Context, fetch PageFetcher[T], pagination Pagination, options PagerOptions) *Pager[T] {
+	return &Pager[T]{ctx: ctx, fetch: fetch, pagination: pagination, options: options}
+}
+
+// Next advances to the next item, fetching a new page when the current one
+// is exhausted. It returns false when there are no more items or an error occurred.
+func (p *Pager[T]) Next() bool {
+	if p.err != nil || (p.options.MaxItems > 0 && p.returned >= p.options.MaxItems) {
+		return false
+	}
+	for p.index >= len(p.page.Items) {
+		if p.fetched && (!p.page.HasMore || p.page.LastID == "") {
+			return false
+		}
+		if p.fetched {
+			after := p.page.LastID
+			p.pagination.After = &after
+		}
+		page, err := p.fetch(p.ctx, p.pagination)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page, p.index, p.fetched = page, 0, true
+	}
+	p.current = p.page.Items[p.index]
+	p.index++
+	p.returned++
+	return true
+}
+
+// Current returns the item Next advanced to.
+func (p *Pager[T]) Current() T {
+	return p.current
+}
+
+// Err returns the error which stopped the pager, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns a sequence of the remaining items. An error is yielded once,
+// with the zero value of T, and ends the sequence.
+func (p *Pager[T]) All() Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.Next() {
+			if !yield(p.Current(), nil) {
+				return
+			}
+		}
+		if err := p.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Collect returns the remaining items as a slice.
+func (p *Pager[T]) Collect() ([]T, error) {
+	var items []T
+	for p.Next() {
+		items = append(items, p.Current())
+	}
+	return items, p.Err()
+}
+
+// newListPager adapts a List method and the conversion of its response to a Pager.
+func newListPager[L, T any](
+	ctx context.Context,
+	list func(ctx context.Context, pagination Pagination) (L, error),
+	toPage func(L) Page[T],
+	pagination Pagination,
+	options PagerOptions,
+) *Pager[T] {
+	return NewPager(ctx, func(ctx context.Context, pagination Pagination) (Page[T], error) {
+		response, err := list(ctx, pagination)
+		if err != nil {
+			return Page[T]{}, err
+		}
+		return toPage(response), nil
+	}, pagination, options)
+}
+
+// newPage builds a Page, falling back to the ID of the last item when the
+// list response doesn't carry last_id.
+func newPage[T any](items []T, lastID *string, hasMore bool, id func(T) string) Page[T] {
+	page := Page[T]{Items: items, HasMore: hasMore}
+	switch {
+	case lastID != nil && *lastID != "":
+		page.LastID = *lastID
+	case len(items) > 0:
+		page.LastID = id(items[len(items)-1])
+	}
+	return page
+}
+
+// values returns the query parameters for p.
+func (p Pagination) values() url.Values {
+	values := url.Values{}
+	if p.Limit != nil {
+		values.Add("limit", fmt.Sprintf("%d", *p.Limit))
+	}
+	if p.Order != nil {
+		values.Add("order", *p.Order)
+	}
+	if p.After != nil {
+		values.Add("after", *p.After)
+	}
+	if p.Before != nil {
+		values.Add("before", *p.Before)
+	}
+	return values
+}
+
+// encodeQuery returns values as a query string including the leading "?",
+// or an empty string when there are no values.
+func encodeQuery(values url.Values) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+func (l AssistantsList) page() Page[Assistant] {
+	return newPage(l.Assistants, l.LastID, l.HasMore, func(a Assistant) string { return a.ID })
+}
+
+func (l AssistantFilesList) page() Page[AssistantFile] {
+	return newPage(l.AssistantFiles, l.LastID, l.HasMore, func(f AssistantFile) string { return f.ID })
+}
+
+func (l MessagesList) page() Page[Message] {
+	return newPage(l.Messages, l.LastID, l.HasMore, func(m Message) string { return m.ID })
+}
+
+func (l RunList) page() Page[Run] {
+	return newPage(l.Runs, &l.LastID, l.HasMore, func(r Run) string { return r.ID })
+}
+
+func (l RunStepList) page() Page[RunStep] {
+	return newPage(l.RunSteps, &l.LastID, l.HasMore, func(s RunStep) string { return s.ID })
+}
+
+func (l VectorStoresList) page() Page[VectorStore] {
+	return newPage(l.VectorStores, l.LastID, l.HasMore, func(v VectorStore) string { return v.ID })
+}
+
+func (l VectorStoreFilesList) page() Page[VectorStoreFile] {
+	return newPage(l.VectorStoreFiles, l.LastID, l.HasMore, func(f VectorStoreFile) string { return f.ID })
+}
+
+func (l ListBatchResponse) page() Page[Batch] {
+	return newPage(l.Data, &l.LastID, l.HasMore, func(b Batch) string { return b.ID })
+}
+
+func (l FineTuningJobEventList) page() Page[FineTuningJobEvent] {
+	return newPage(l.Events, nil, l.HasMore, func(e FineTuningJobEvent) string { return e.ID })
+}
+
+// ListAssistantsPager returns a Pager over all assistants.
+func (c *Client) ListAssistantsPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Assistant] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (AssistantsList, error) {
+		return c.ListAssistants(ctx, pagination, opts...)
+	}, AssistantsList.page, pagination, options)
+}
+
+// ListAssistantFilesPager returns a Pager over all files of the assistant.
+func (c *Client) ListAssistantFilesPager(
+	ctx context.Context,
+	assistantID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[AssistantFile] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (AssistantFilesList, error) {
+		return c.ListAssistantFiles(ctx, assistantID, pagination, opts...)
+	}, AssistantFilesList.page, pagination, options)
+}
+
+// ListMessagePager returns a Pager over all messages in the thread.
+func (c *Client) ListMessagePager(
+	ctx context.Context,
+	threadID string,
+	listOptions ListMessageOptions,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Message] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (MessagesList, error) {
+		listOptions.Pagination = pagination
+		return c.ListMessage(ctx, threadID, listOptions, opts...)
+	}, MessagesList.page, listOptions.Pagination, options)
+}
+
+// ListRunsPager returns a Pager over all runs of the thread.
+func (c *Client) ListRunsPager(
+	ctx context.Context,
+	threadID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Run] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (RunList, error) {
+		return c.ListRuns(ctx, threadID, pagination, opts...)
+	}, RunList.page, pagination, options)
+}
+
+// ListRunStepsPager returns a Pager over all steps of the run.
+func (c *Client) ListRunStepsPager(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[RunStep] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (RunStepList, error) {
+		return c.ListRunSteps(ctx, threadID, runID, pagination, opts...)
+	}, RunStepList.page, pagination, options)
+}
+
+// ListVectorStoresPager returns a Pager over all vector stores.
+func (c *Client) ListVectorStoresPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[VectorStore] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (VectorStoresList, error) {
+		return c.ListVectorStores(ctx, pagination, opts...)
+	}, VectorStoresList.page, pagination, options)
+}
+
+// ListVectorStoreFilesPager returns a Pager over all files of the vector store.
+func (c *Client) ListVectorStoreFilesPager(
+	ctx context.Context,
+	vectorStoreID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[VectorStoreFile] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (VectorStoreFilesList, error) {
+		return c.ListVectorStoreFiles(ctx, vectorStoreID, pagination, opts...)
+	}, VectorStoreFilesList.page, pagination, options)
+}
+
+// ListBatchPager returns a Pager over all batches.
+func (c *Client) ListBatchPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Batch] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (ListBatchResponse, error) {
+		return c.ListBatch(ctx, pagination, opts...)
+	}, ListBatchResponse.page, pagination, options)
+}
+
+// ListFineTuningJobEventsPager returns a Pager over all events of the fine tuning job.
+func (c *Client) ListFineTuningJobEventsPager(
+	ctx context.Context,
+	fineTuningJobID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[FineTuningJobEvent] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (FineTuningJobEventList, error) {
+		return c.ListFineTuningJobEvents(ctx, fineTuningJobID, pagination, opts...)
+	}, FineTuningJobEventList.page, pagination, options)
+}
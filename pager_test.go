@@ -0,0 +1,242 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// registerPagedAssistants serves total assistants named asst_0..asst_{total-1},
+// honouring the limit and after query parameters, and records every query.
+func registerPagedAssistants(server *test.ServerTest, total int, queries *[]string) {
+	server.RegisterHandler("/v1/assistants", func(w http.ResponseWriter, r *http.Request) {
+		*queries = append(*queries, r.URL.RawQuery)
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = 20
+		}
+		start := 0
+		if after := r.URL.Query().Get("after"); after != "" {
+			_, _ = fmt.Sscanf(after, "asst_%d", &start)
+			start++
+		}
+		end := start + limit
+		if end > total {
+			end = total
+		}
+		var list openai.AssistantsList
+		for i := start; i < end; i++ {
+			list.Assistants = append(list.Assistants, openai.Assistant{ID: fmt.Sprintf("asst_%d", i)})
+		}
+		if end > start {
+			lastID := list.Assistants[len(list.Assistants)-1].ID
+			list.LastID = &lastID
+		}
+		list.HasMore = end < total
+		resBytes, _ := json.Marshal(list)
+		fmt.Fprintln(w, string(resBytes))
+	})
+}
+
+func TestPagerFollowsCursor(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	registerPagedAssistants(server, 5, &queries)
+
+	pageSize := 2
+	assistants, err := client.ListAssistantsPager(
+		context.Background(),
+		openai.Pagination{Limit: &pageSize},
+		openai.PagerOptions{},
+	).Collect()
+	checks.NoError(t, err, "Collect error")
+
+	var ids []string
+	for _, assistant := range assistants {
+		ids = append(ids, assistant.ID)
+	}
+	if want := []string{"asst_0", "asst_1", "asst_2", "asst_3", "asst_4"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	wantQueries := []string{"limit=2", "after=asst_1&limit=2", "after=asst_3&limit=2"}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Fatalf("expected queries %v, got %v", wantQueries, queries)
+	}
+}
+
+func TestPagerMaxItems(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	registerPagedAssistants(server, 10, &queries)
+
+	pageSize := 2
+	pager := client.ListAssistantsPager(
+		context.Background(),
+		openai.Pagination{Limit: &pageSize},
+		openai.PagerOptions{MaxItems: 3},
+	)
+	count := 0
+	for pager.Next() {
+		count++
+	}
+	checks.NoError(t, pager.Err(), "pager error")
+	if count != 3 {
+		t.Fatalf("expected 3 items, got %d", count)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 page requests, got %d", len(queries))
+	}
+}
+
+func TestPagerAllStopsEarly(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	registerPagedAssistants(server, 10, &queries)
+
+	pageSize := 2
+	var ids []string
+	client.ListAssistantsPager(
+		context.Background(),
+		openai.Pagination{Limit: &pageSize},
+		openai.PagerOptions{},
+	).All()(func(assistant openai.Assistant, err error) bool {
+		checks.NoError(t, err, "All error")
+		ids = append(ids, assistant.ID)
+		return assistant.ID != "asst_2"
+	})
+	if want := []string{"asst_0", "asst_1", "asst_2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 page requests, got %d", len(queries))
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintln(w, `{"error":{"message":"boom","type":"server_error"}}`)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"batch_1"}],"last_id":"batch_1","has_more":true}`)
+	})
+
+	var (
+		ids     []string
+		lastErr error
+	)
+	client.ListBatchPager(context.Background(), openai.Pagination{}, openai.PagerOptions{}).All()(
+		func(batch openai.Batch, err error) bool {
+			if err != nil {
+				lastErr = err
+				return false
+			}
+			ids = append(ids, batch.ID)
+			return true
+		})
+	if !reflect.DeepEqual(ids, []string{"batch_1"}) {
+		t.Fatalf("unexpected items %v", ids)
+	}
+	apiErr := &openai.APIError{}
+	if !errors.As(lastErr, &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the APIError of the second page, got %v", lastErr)
+	}
+}
+
+func TestPagerFallsBackToLastItemID(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var afters []string
+	server.RegisterHandler("/v1/fine_tuning/jobs/ftjob-1/events", func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("after")
+		afters = append(afters, after)
+		if after == "" {
+			fmt.Fprintln(w, `{"object":"list","data":[{"id":"ev_1"},{"id":"ev_2"}],"has_more":true}`)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"ev_3"}],"has_more":false}`)
+	})
+
+	events, err := client.ListFineTuningJobEventsPager(
+		context.Background(),
+		"ftjob-1",
+		openai.Pagination{},
+		openai.PagerOptions{},
+	).Collect()
+	checks.NoError(t, err, "Collect error")
+	if len(events) != 3 || events[2].ID != "ev_3" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if !reflect.DeepEqual(afters, []string{"", "ev_2"}) {
+		t.Fatalf("unexpected after cursors %v", afters)
+	}
+}
+
+func TestListMessagePagerKeepsRunID(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	server.RegisterHandler("/v1/threads/thread_1/messages", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprintln(w, `{"object":"list","data":[{"id":"msg_1"}],"last_id":"msg_1","has_more":true}`)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"msg_2"}],"last_id":"msg_2","has_more":false}`)
+	})
+
+	runID := "run_1"
+	messages, err := client.ListMessagePager(
+		context.Background(),
+		"thread_1",
+		openai.ListMessageOptions{RunID: &runID},
+		openai.PagerOptions{},
+	).Collect()
+	checks.NoError(t, err, "Collect error")
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if want := []string{"run_id=run_1", "after=msg_1&run_id=run_1"}; !reflect.DeepEqual(queries, want) {
+		t.Fatalf("expected queries %v, got %v", want, queries)
+	}
+}
+
+func TestListAssistantFilesPager(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var queries []string
+	server.RegisterHandler("/v1/assistants/asst_1/files", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprint(w, `{"data":[{"id":"file_1"},{"id":"file_2"}],"last_id":"file_2","has_more":true}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"file_3"}],"last_id":"file_3","has_more":false}`)
+	})
+
+	pageSize := 2
+	files, err := client.ListAssistantFilesPager(context.Background(), "asst_1",
+		openai.Pagination{Limit: &pageSize}, openai.PagerOptions{}).Collect()
+	checks.NoError(t, err, "Collect error")
+	if len(files) != 3 || files[2].ID != "file_3" {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+	if want := []string{"limit=2", "after=file_2&limit=2"}; !reflect.DeepEqual(queries, want) {
+		t.Fatalf("expected queries %v, got %v", want, queries)
+	}
+}
@@ -0,0 +1,408 @@
+package openai
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCacheHeader is the response header reporting how the response
+// cache handled a request. Read it with ResponseCacheStatus.
+const ResponseCacheHeader = "X-Response-Cache"
+
+const defaultMemoryResponseCacheEntries = 1000
+
+// responseCacheKeyHeaders are the request headers which identify the caller,
+// so responses are never shared between API keys, organizations or projects.
+var responseCacheKeyHeaders = []string{
+	"Authorization",
+	AzureAPIKeyHeader,
+	AnthropicAPIKeyHeader,
+	"OpenAI-Organization",
+	"OpenAI-Project",
+}
+
+type ResponseCacheStatus string
+
+const (
+	// ResponseCacheHit responses were replayed from the cache.
+	ResponseCacheHit ResponseCacheStatus = "hit"
+	// ResponseCacheMiss responses were sent by the API and stored.
+	ResponseCacheMiss ResponseCacheStatus = "miss"
+	// ResponseCacheBypass responses were requested with WithoutResponseCache.
+	ResponseCacheBypass ResponseCacheStatus = "bypass"
+)
+
+// ResponseCacheStatus returns how the response cache handled the request,
+// or "" when it didn't go through one.
+func (h *httpHeader) ResponseCacheStatus() ResponseCacheStatus {
+	return ResponseCacheStatus(h.Header().Get(ResponseCacheHeader))
+}
+
+// CachedResponse is a successful chat completion or completion response.
+type CachedResponse struct {
+	Header http.Header `json:"header"`
+	// Body is the body of a response which wasn't streamed.
+	Body json.RawMessage `json:"body,omitempty"`
+	// ChatChunks and CompletionChunks are the events of a streamed chat
+	// completion or completion, replayed one by one.
+	ChatChunks       []ChatCompletionStreamResponse `json:"chat_chunks,omitempty"`
+	CompletionChunks []CompletionResponse           `json:"completion_chunks,omitempty"`
+}
+
+// ResponseCache stores responses for NewCachingHTTPClient. Implementations
+// must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the unexpired response stored for key, and whether there is one.
+	Get(key string) (CachedResponse, bool, error)
+	// Set stores response for ttl, or without expiry when ttl is 0.
+	Set(key string, response CachedResponse, ttl time.Duration) error
+}
+
+// ResponseCacheOptions configures NewCachingHTTPClient.
+type ResponseCacheOptions struct {
+	// TTL is how long responses are kept. Zero keeps them until evicted.
+	TTL time.Duration
+}
+
+type responseCacheBypassKey struct{}
+
+// WithoutResponseCache sends the request to the API even when an identical
+// one is cached, and doesn't store its response.
+func WithoutResponseCache() RequestOption {
+	return func(args *requestOptions) {
+		args.bypassResponseCache = true
+	}
+}
+
+// withResponseCacheBypass marks ctx for cachingHTTPClient to pass the request through.
+func withResponseCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, responseCacheBypassKey{}, true)
+}
+
+// cachingHTTPClient is the HTTPDoer returned by NewCachingHTTPClient.
+type cachingHTTPClient struct {
+	next    HTTPDoer
+	cache   ResponseCache
+	options ResponseCacheOptions
+}
+
+// NewCachingHTTPClient returns an HTTPDoer for ClientConfig.HTTPClient which
+// answers chat completion and completion requests from cache, sending them
+// with next when they aren't cached. Requests are identified by their URL and
+// a hash of their canonical JSON body, so it suits deterministic requests,
+// e.g. with a Seed and a Temperature of 0, sent again and again by evals.
+// The key also hashes the credential, organization and project headers, so
+// a response is only replayed to callers sending the same ones; responses
+// cached with a token, e.g. of a TokenProvider, aren't replayed once it is
+// renewed. Streamed responses are cached once complete and replayed chunk by
+// chunk. Responses report a hit or a miss through ResponseCacheStatus.
+func NewCachingHTTPClient(next HTTPDoer, cache ResponseCache, options ResponseCacheOptions) HTTPDoer {
+	return &cachingHTTPClient{next: next, cache: cache, options: options}
+}
+
+func (c *cachingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	kind := responseCacheKind(req)
+	if kind == "" || req.Body == nil {
+		return c.next.Do(req)
+	}
+	if bypass, _ := req.Context().Value(responseCacheBypassKey{}).(bool); bypass {
+		resp, err := c.next.Do(req)
+		if err == nil {
+			resp.Header.Set(ResponseCacheHeader, string(ResponseCacheBypass))
+		}
+		return resp, err
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	key, stream, err := responseCacheKey(req, body)
+	if err != nil {
+		// Bodies which aren't JSON are the API's to reject.
+		return c.next.Do(req)
+	}
+
+	cached, hit, err := c.cache.Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("response cache: %w", err)
+	}
+	if hit {
+		return replayCachedResponse(req, cached)
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil || isFailureStatusCode(resp) {
+		return resp, err
+	}
+	resp.Header.Set(ResponseCacheHeader, string(ResponseCacheMiss))
+	if stream {
+		resp.Body = &streamRecorder{
+			ReadCloser: resp.Body,
+			kind:       kind,
+			store: func(response CachedResponse) error {
+				response.Header = resp.Header.Clone()
+				return c.cache.Set(key, response, c.options.TTL)
+			},
+		}
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+	response := CachedResponse{Header: resp.Header.Clone(), Body: responseBody}
+	if err = c.cache.Set(key, response, c.options.TTL); err != nil {
+		return nil, fmt.Errorf("response cache: %w", err)
+	}
+	return resp, nil
+}
+
+// responseCacheKind returns the endpoint of cacheable requests, or "".
+func responseCacheKind(req *http.Request) string {
+	if req.Method != http.MethodPost {
+		return ""
+	}
+	switch {
+	case strings.HasSuffix(req.URL.Path, chatCompletionsSuffix):
+		return chatCompletionsSuffix
+	case strings.HasSuffix(req.URL.Path, "/completions"):
+		return "/completions"
+	}
+	return ""
+}
+
+// responseCacheKey hashes the URL, the responseCacheKeyHeaders and the
+// canonical form of the JSON body of req, in which object keys are sorted,
+// and reports whether it is streamed.
+func responseCacheKey(req *http.Request, body []byte) (key string, stream bool, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var fields map[string]any
+	if err = decoder.Decode(&fields); err != nil {
+		return "", false, err
+	}
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", false, err
+	}
+	stream, _ = fields["stream"].(bool)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.String())
+	for _, name := range responseCacheKeyHeaders {
+		fmt.Fprintf(hash, "%q: %q\n", name, req.Header.Values(name))
+	}
+	hash.Write(canonical)
+	return hex.EncodeToString(hash.Sum(nil)), stream, nil
+}
+
+// replayCachedResponse answers req with cached, rebuilding the event stream
+// of a streamed response.
+func replayCachedResponse(req *http.Request, cached CachedResponse) (*http.Response, error) {
+	body := []byte(cached.Body)
+	if cached.Body == nil {
+		var events bytes.Buffer
+		writeEvent := func(chunk any) error {
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&events, "data: %s\n\n", data)
+			return nil
+		}
+		for _, chunk := range cached.ChatChunks {
+			if err := writeEvent(chunk); err != nil {
+				return nil, err
+			}
+		}
+		for _, chunk := range cached.CompletionChunks {
+			if err := writeEvent(chunk); err != nil {
+				return nil, err
+			}
+		}
+		events.WriteString("data: [DONE]\n\n")
+		body = events.Bytes()
+	}
+
+	header := cached.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(ResponseCacheHeader, string(ResponseCacheHit))
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// streamRecorder collects the chunks of a streamed response as it is read and
+// stores them once the [DONE] event arrives. Streams which fail, or are closed
+// early, aren't stored. Close returns the error of storing the stream.
+type streamRecorder struct {
+	io.ReadCloser
+	kind  string
+	store func(CachedResponse) error
+
+	line     []byte
+	response CachedResponse
+	finished bool
+	failed   bool
+	storeErr error
+}
+
+func (r *streamRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	data := p[:n]
+	for len(data) > 0 && !r.finished && !r.failed {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			break
+		}
+		r.line = append(r.line, data[:i]...)
+		r.record(bytes.TrimSpace(r.line))
+		r.line = r.line[:0]
+		data = data[i+1:]
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		r.failed = true
+	}
+	return n, err
+}
+
+// record adds an event line to the response.
+func (r *streamRecorder) record(line []byte) {
+	if !headerData.Match(line) {
+		return
+	}
+	if errorPrefix.Match(line) {
+		r.failed = true
+		return
+	}
+	data := headerData.ReplaceAll(line, nil)
+	if string(data) == "[DONE]" {
+		r.finished = true
+		r.storeErr = r.store(r.response)
+		return
+	}
+
+	var err error
+	if r.kind == chatCompletionsSuffix {
+		var chunk ChatCompletionStreamResponse
+		err = json.Unmarshal(data, &chunk)
+		r.response.ChatChunks = append(r.response.ChatChunks, chunk)
+	} else {
+		var chunk CompletionResponse
+		err = json.Unmarshal(data, &chunk)
+		r.response.CompletionChunks = append(r.response.CompletionChunks, chunk)
+	}
+	if err != nil {
+		r.failed = true
+	}
+}
+
+func (r *streamRecorder) Close() error {
+	err := r.ReadCloser.Close()
+	if r.storeErr != nil {
+		return fmt.Errorf("response cache: %w", r.storeErr)
+	}
+	return err
+}
+
+// MemoryResponseCache is an in-memory ResponseCache holding up to a fixed
+// number of responses, evicting the least recently used.
+type MemoryResponseCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryResponseEntry struct {
+	key      string
+	response CachedResponse
+	expires  time.Time
+}
+
+// NewMemoryResponseCache returns a cache holding up to capacity responses. A
+// capacity of 0 or less uses the default of 1000 responses.
+func NewMemoryResponseCache(capacity int) *MemoryResponseCache {
+	if capacity <= 0 {
+		capacity = defaultMemoryResponseCacheEntries
+	}
+	return &MemoryResponseCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (c *MemoryResponseCache) Get(key string) (CachedResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return CachedResponse{}, false, nil
+	}
+	entry := element.Value.(*memoryResponseEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return CachedResponse{}, false, nil
+	}
+	c.order.MoveToFront(element)
+	return entry.response, true, nil
+}
+
+func (c *MemoryResponseCache) Set(key string, response CachedResponse, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &memoryResponseEntry{key: key, response: response}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryResponseEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of responses in the cache, including expired ones
+// not yet evicted.
+func (c *MemoryResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,222 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func setupCachingTestServer(
+	options openai.ResponseCacheOptions,
+) (client *openai.Client, server *test.ServerTest, teardown func()) {
+	server = test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	teardown = ts.Close
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = openai.NewCachingHTTPClient(&http.Client{}, openai.NewMemoryResponseCache(10), options)
+	client = openai.NewClientWithConfig(config)
+	return
+}
+
+func cachedChatRequest() openai.ChatCompletionRequest {
+	seed := 7
+	return openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Seed:     &seed,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hello!"}},
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	client, server, teardown := setupCachingTestServer(openai.ResponseCacheOptions{})
+	defer teardown()
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"id":"%d","choices":[{"message":{"role":"assistant","content":"Hi"}}]}`, calls)
+	})
+
+	ctx := context.Background()
+	for i, expected := range []openai.ResponseCacheStatus{openai.ResponseCacheMiss, openai.ResponseCacheHit} {
+		response, err := client.CreateChatCompletion(ctx, cachedChatRequest())
+		checks.NoError(t, err, "CreateChatCompletion error")
+		if response.ResponseCacheStatus() != expected || response.ID != "1" {
+			t.Fatalf("call %d: expected a %s of response 1, got %s of %s",
+				i, expected, response.ResponseCacheStatus(), response.ID)
+		}
+	}
+
+	response, err := client.CreateChatCompletion(ctx, cachedChatRequest(), openai.WithoutResponseCache())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ResponseCacheStatus() != openai.ResponseCacheBypass || response.ID != "2" {
+		t.Fatalf("expected the bypass to reach the API, got %s of %s", response.ResponseCacheStatus(), response.ID)
+	}
+
+	request := cachedChatRequest()
+	request.Messages[0].Content = "Hello again!"
+	response, err = client.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ResponseCacheStatus() != openai.ResponseCacheMiss || calls != 3 {
+		t.Fatalf("expected another request to miss, got %s after %d calls", response.ResponseCacheStatus(), calls)
+	}
+
+	// Reordered JSON fields are the same request.
+	response, err = client.CreateChatCompletion(ctx, cachedChatRequest(), openai.WithExtraBody(map[string]any{}))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ResponseCacheStatus() != openai.ResponseCacheHit {
+		t.Fatalf("expected the canonical request to hit, got %s", response.ResponseCacheStatus())
+	}
+}
+
+func TestResponseCacheTTLAndErrors(t *testing.T) {
+	client, server, teardown := setupCachingTestServer(openai.ResponseCacheOptions{TTL: 20 * time.Millisecond})
+	defer teardown()
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"message":"server error"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1"}`)
+	})
+
+	ctx := context.Background()
+	_, err := client.CreateChatCompletion(ctx, cachedChatRequest())
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+	for _, expected := range []openai.ResponseCacheStatus{openai.ResponseCacheMiss, openai.ResponseCacheHit} {
+		response, callErr := client.CreateChatCompletion(ctx, cachedChatRequest())
+		checks.NoError(t, callErr, "CreateChatCompletion error")
+		if response.ResponseCacheStatus() != expected {
+			t.Fatalf("expected a %s, got %s", expected, response.ResponseCacheStatus())
+		}
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	response, err := client.CreateChatCompletion(ctx, cachedChatRequest())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ResponseCacheStatus() != openai.ResponseCacheMiss || calls != 3 {
+		t.Fatalf("expected the expired response to miss, got %s after %d calls",
+			response.ResponseCacheStatus(), calls)
+	}
+}
+
+func TestResponseCacheStream(t *testing.T) {
+	client, server, teardown := setupCachingTestServer(openai.ResponseCacheOptions{})
+	defer teardown()
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Hel", "lo", "!"} {
+			fmt.Fprintf(w, "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+		}
+		if calls > 1 {
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}
+	})
+
+	receive := func() (string, openai.ResponseCacheStatus) {
+		stream, err := client.CreateChatCompletionStream(context.Background(), cachedChatRequest())
+		checks.NoError(t, err, "CreateChatCompletionStream error")
+		defer stream.Close()
+		var content string
+		for {
+			chunk, recvErr := stream.Recv()
+			if errors.Is(recvErr, io.EOF) {
+				break
+			}
+			checks.NoError(t, recvErr, "Recv error")
+			content += chunk.Choices[0].Delta.Content
+		}
+		return content, stream.ResponseCacheStatus()
+	}
+
+	// A stream ending without [DONE] isn't cached.
+	if content, status := receive(); content != "Hello!" || status != openai.ResponseCacheMiss {
+		t.Fatalf("unexpected %s of %q", status, content)
+	}
+	if content, status := receive(); content != "Hello!" || status != openai.ResponseCacheMiss {
+		t.Fatalf("unexpected %s of %q", status, content)
+	}
+	if content, status := receive(); content != "Hello!" || status != openai.ResponseCacheHit {
+		t.Fatalf("unexpected %s of %q", status, content)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the complete stream to be replayed, got %d calls", calls)
+	}
+
+	// Streaming and non-streaming requests are cached apart.
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"id":"2"}`)
+	})
+	response, err := client.CreateChatCompletion(context.Background(), cachedChatRequest())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ID != "2" {
+		t.Fatalf("expected the non-streaming request to miss, got %+v", response)
+	}
+}
+
+func TestMemoryResponseCacheDefaultCapacity(t *testing.T) {
+	// A capacity of 0 uses the default instead of evicting every response.
+	cache := openai.NewMemoryResponseCache(0)
+	checks.NoError(t, cache.Set("key", openai.CachedResponse{Body: []byte(`{}`)}, 0), "Set error")
+	if _, ok, _ := cache.Get("key"); !ok || cache.Len() != 1 {
+		t.Fatalf("expected the response to be cached, %d cached", cache.Len())
+	}
+}
+
+func TestResponseCacheSeparatesCredentials(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"id":"%d"}`, calls)
+	})
+	httpClient := openai.NewCachingHTTPClient(&http.Client{}, openai.NewMemoryResponseCache(10),
+		openai.ResponseCacheOptions{})
+	newClient := func(orgID string) *openai.Client {
+		config := openai.DefaultConfig(test.GetTestToken())
+		config.OrgID = orgID
+		config.BaseURL = ts.URL + "/v1"
+		config.HTTPClient = httpClient
+		return openai.NewClientWithConfig(config)
+	}
+
+	ctx := context.Background()
+	requests := []struct {
+		client *openai.Client
+		opts   []openai.RequestOption
+		status openai.ResponseCacheStatus
+	}{
+		{newClient(""), nil, openai.ResponseCacheMiss},
+		{newClient(""), nil, openai.ResponseCacheHit},
+		{newClient("org-other"), nil, openai.ResponseCacheMiss},
+		{newClient(""), []openai.RequestOption{openai.WithHeader("OpenAI-Project", "p")},
+			openai.ResponseCacheMiss},
+	}
+	for i, request := range requests {
+		response, err := request.client.CreateChatCompletion(ctx, cachedChatRequest(), request.opts...)
+		checks.NoError(t, err, "CreateChatCompletion error")
+		if response.ResponseCacheStatus() != request.status {
+			t.Fatalf("call %d: expected a %s, got %s", i, request.status, response.ResponseCacheStatus())
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRouterFailureThreshold = 3
+	defaultRouterCooldown         = 30 * time.Second
+	// routerLatencySmoothing is the weight of the newest sample in the latency moving average.
+	routerLatencySmoothing = 0.3
+)
+
+var (
+	ErrRouterNoBackends      = errors.New("router has no backends configured")
+	ErrRouterUnknownBackend  = errors.New("route rule references an unknown backend")
+	ErrRouterDuplicateName   = errors.New("router backend names must be unique")
+	ErrRouterNoHealthyTarget = errors.New("no healthy backend available for request")
+)
+
+// RoutingStrategy selects the order in which a Router tries its backends.
+type RoutingStrategy string
+
+const (
+	// RoutingStrategyWeightedRoundRobin spreads requests across backends proportionally to their Weight.
+	RoutingStrategyWeightedRoundRobin RoutingStrategy = "weighted_round_robin"
+	// RoutingStrategyLeastLatency prefers the backend with the lowest observed latency.
+	RoutingStrategyLeastLatency RoutingStrategy = "least_latency"
+	// RoutingStrategyPriority always prefers the backend with the lowest Priority
+	// and only falls back to the others when it fails.
+	RoutingStrategyPriority RoutingStrategy = "priority"
+)
+
+// RouterBackend is a single Client a Router can dispatch to.
+// Each backend keeps its own ClientConfig, so Azure deployments are resolved
+// with that backend's AzureModelMapperFunc.
+type RouterBackend struct {
+	Name   string
+	Client *Client
+	// Weight is used by RoutingStrategyWeightedRoundRobin. Defaults to 1.
+	Weight int
+	// Priority is used by RoutingStrategyPriority; lower values are tried first.
+	Priority int
+}
+
+// RouteRule restricts the backends used for matching models.
+type RouteRule struct {
+	// Model is compared to the request model. A trailing "*" matches any suffix,
+	// e.g. "gpt-4o*". An empty Model matches calls which don't carry a model:
+	// listing models and engines, and the calls on stateful resources such as
+	// files, assistants or batches, which its backend with the lowest Priority serves.
+	Model string
+	// Backends lists the names of the backends allowed to serve the model.
+	Backends []string
+}
+
+func (r RouteRule) matches(model string) bool {
+	if strings.HasSuffix(r.Model, "*") {
+		return strings.HasPrefix(model, strings.TrimSuffix(r.Model, "*"))
+	}
+	return r.Model == model
+}
+
+// RouterConfig is the configuration of a Router.
+type RouterConfig struct {
+	Backends []RouterBackend
+	Strategy RoutingStrategy
+	// Rules are evaluated in order and the first match wins. Requests which
+	// don't match any rule can be served by every backend.
+	Rules []RouteRule
+	// FailureThreshold is the number of consecutive retryable failures
+	// (5xx, 429 or transport errors) after which a backend's circuit opens.
+	FailureThreshold int
+	// Cooldown is how long an open circuit stays open before the backend is tried again.
+	Cooldown time.Duration
+}
+
+type backendState struct {
+	RouterBackend
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+	latency             time.Duration
+	currentWeight       int
+}
+
+func (b *backendState) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.openUntil)
+}
+
+func (b *backendState) observedLatency() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.latency
+}
+
+// Router dispatches requests across several Clients and implements the same
+// method set as Client. Backends which keep failing with retryable errors are
+// taken out of rotation for RouterConfig.Cooldown.
+//
+// Only calls routed by model, and reads of models and engines, fail over to
+// other backends. Calls on stateful resources, such as files, assistants,
+// threads, runs, vector stores, batches, uploads and fine-tuning jobs, are all
+// sent to one resource backend, so a resource is used where it was created
+// and creating it is never sent twice. The resource backend is the backend
+// with the lowest Priority among those allowed for the empty model.
+type Router struct {
+	config   RouterConfig
+	backends []*backendState
+	byName   map[string]*backendState
+	// resources is the backend serving the calls on stateful resources.
+	resources *backendState
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewRouter creates a Router for the given configuration.
+func NewRouter(config RouterConfig) (*Router, error) {
+	if len(config.Backends) == 0 {
+		return nil, ErrRouterNoBackends
+	}
+	if config.Strategy == "" {
+		config.Strategy = RoutingStrategyPriority
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaultRouterFailureThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultRouterCooldown
+	}
+
+	r := &Router{
+		config: config,
+		byName: make(map[string]*backendState, len(config.Backends)),
+		now:    time.Now,
+	}
+	for _, backend := range config.Backends {
+		if backend.Weight <= 0 {
+			backend.Weight = 1
+		}
+		if _, ok := r.byName[backend.Name]; ok {
+			return nil, ErrRouterDuplicateName
+		}
+		state := &backendState{RouterBackend: backend}
+		r.backends = append(r.backends, state)
+		r.byName[backend.Name] = state
+	}
+	for _, rule := range config.Rules {
+		for _, name := range rule.Backends {
+			if _, ok := r.byName[name]; !ok {
+				return nil, ErrRouterUnknownBackend
+			}
+		}
+	}
+	for _, b := range r.allowed("") {
+		if r.resources == nil || b.Priority < r.resources.Priority {
+			r.resources = b
+		}
+	}
+	return r, nil
+}
+
+// BackendHealth describes the state a Router keeps for one backend.
+type BackendHealth struct {
+	Name                string
+	Available           bool
+	ConsecutiveFailures int
+	Latency             time.Duration
+}
+
+// Health returns the current health of every backend in configuration order.
+func (r *Router) Health() []BackendHealth {
+	now := r.now()
+	health := make([]BackendHealth, 0, len(r.backends))
+	for _, b := range r.backends {
+		b.mu.Lock()
+		health = append(health, BackendHealth{
+			Name:                b.Name,
+			Available:           !now.Before(b.openUntil),
+			ConsecutiveFailures: b.consecutiveFailures,
+			Latency:             b.latency,
+		})
+		b.mu.Unlock()
+	}
+	return health
+}
+
+// allowed returns the backends the first rule matching model allows, or every backend.
+func (r *Router) allowed(model string) []*backendState {
+	for _, rule := range r.config.Rules {
+		if !rule.matches(model) {
+			continue
+		}
+		allowed := make([]*backendState, 0, len(rule.Backends))
+		for _, name := range rule.Backends {
+			allowed = append(allowed, r.byName[name])
+		}
+		return allowed
+	}
+	return r.backends
+}
+
+// candidates returns the backends allowed for model, in the order they should be tried.
+func (r *Router) candidates(model string) []*backendState {
+	allowed := r.allowed(model)
+	now := r.now()
+	healthy := make([]*backendState, 0, len(allowed))
+	for _, b := range allowed {
+		if b.available(now) {
+			healthy = append(healthy, b)
+		}
+	}
+
+	switch r.config.Strategy {
+	case RoutingStrategyWeightedRoundRobin:
+		return r.weightedOrder(healthy)
+	case RoutingStrategyLeastLatency:
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].observedLatency() < healthy[j].observedLatency()
+		})
+	case RoutingStrategyPriority:
+		fallthrough
+	default:
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].Priority < healthy[j].Priority
+		})
+	}
+	return healthy
+}
+
+// weightedOrder picks the first backend with smooth weighted round-robin
+// and keeps the remaining ones, by descending weight, as fallbacks.
+func (r *Router) weightedOrder(backends []*backendState) []*backendState {
+	if len(backends) == 0 {
+		return backends
+	}
+	r.mu.Lock()
+	total := 0
+	var best *backendState
+	for _, b := range backends {
+		b.currentWeight += b.Weight
+		total += b.Weight
+		if best == nil || b.currentWeight > best.currentWeight {
+			best = b
+		}
+	}
+	best.currentWeight -= total
+	r.mu.Unlock()
+
+	ordered := make([]*backendState, 0, len(backends))
+	ordered = append(ordered, best)
+	rest := make([]*backendState, 0, len(backends)-1)
+	for _, b := range backends {
+		if b != best {
+			rest = append(rest, b)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Weight > rest[j].Weight
+	})
+	return append(ordered, rest...)
+}
+
+func (r *Router) recordSuccess(b *backendState, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.openUntil = time.Time{}
+	if b.latency == 0 {
+		b.latency = latency
+		return
+	}
+	b.latency = time.Duration(routerLatencySmoothing*float64(latency) + (1-routerLatencySmoothing)*float64(b.latency))
+}
+
+func (r *Router) recordFailure(b *backendState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures++
+	if b.consecutiveFailures >= r.config.FailureThreshold {
+		b.openUntil = r.now().Add(r.config.Cooldown)
+	}
+}
+
+// isRetryableError reports whether err should trip the circuit breaker and
+// make the Router try the next backend.
+func isRetryableError(err error) bool {
+	var (
+		apiErr *APIError
+		reqErr *RequestError
+		urlErr *url.Error
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	case errors.As(err, &reqErr):
+		return isRetryableStatus(reqErr.HTTPStatusCode)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &urlErr):
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// routeCall runs call against the backends allowed for model until one
+// succeeds or fails with a non-retryable error.
+func routeCall[T any](ctx context.Context, r *Router, model string, call func(*Client) (T, error)) (T, error) {
+	return tryBackends(ctx, r, r.candidates(model), call)
+}
+
+// routeBodyCall is routeCall for calls whose request body reads bodies, which
+// are rewound before every attempt. When one of bodies can't be rewound, the
+// body can only be sent once and call is not failed over.
+func routeBodyCall[T any](
+	ctx context.Context,
+	r *Router,
+	model string,
+	bodies []io.Reader,
+	call func(*Client) (T, error),
+) (T, error) {
+	candidates := r.candidates(model)
+	rewind := rewindReaders(bodies...)
+	if rewind == nil {
+		if len(candidates) > 1 {
+			candidates = candidates[:1]
+		}
+		return tryBackends(ctx, r, candidates, call)
+	}
+	return tryBackends(ctx, r, candidates, func(c *Client) (T, error) {
+		if err := rewind(); err != nil {
+			var zero T
+			return zero, err
+		}
+		return call(c)
+	})
+}
+
+// resourceCall runs call on the resource backend only. Its circuit is not
+// checked, since no other backend has the resource.
+func resourceCall[T any](ctx context.Context, r *Router, call func(*Client) (T, error)) (T, error) {
+	var backends []*backendState
+	if r.resources != nil {
+		backends = append(backends, r.resources)
+	}
+	return tryBackends(ctx, r, backends, call)
+}
+
+// tryBackends runs call against backends in order until one succeeds or
+// fails with a non-retryable error.
+func tryBackends[T any](
+	ctx context.Context,
+	r *Router,
+	backends []*backendState,
+	call func(*Client) (T, error),
+) (T, error) {
+	var (
+		zero    T
+		lastErr error
+	)
+	for _, backend := range backends {
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		start := r.now()
+		response, err := call(backend.Client)
+		if err == nil {
+			r.recordSuccess(backend, r.now().Sub(start))
+			return response, nil
+		}
+		if !isRetryableError(err) {
+			return response, err
+		}
+		r.recordFailure(backend)
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = ErrRouterNoHealthyTarget
+	}
+	return zero, lastErr
+}
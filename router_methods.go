@@ -0,0 +1,1231 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+)
+
+// This file mirrors every Client method on Router.
+
+func editsModel(request EditsRequest) string {
+	if request.Model == nil {
+		return ""
+	}
+	return *request.Model
+}
+
+// imageBodies returns the files an image request uploads.
+func imageBodies(files ...*os.File) []io.Reader {
+	bodies := make([]io.Reader, 0, len(files))
+	for _, file := range files {
+		if file != nil {
+			bodies = append(bodies, file)
+		}
+	}
+	return bodies
+}
+
+// audioBodies returns the reader an audio request uploads. A file at
+// FilePath is opened again for every attempt.
+func audioBodies(request AudioRequest) []io.Reader {
+	if request.Reader == nil {
+		return nil
+	}
+	return []io.Reader{request.Reader}
+}
+
+// CreateChatCompletion dispatches Client.CreateChatCompletion to the backends allowed for the request.
+func (r *Router) CreateChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (ChatCompletionResponse, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (ChatCompletionResponse, error) {
+		return c.CreateChatCompletion(ctx, request, opts...)
+	})
+}
+
+// CreateChatCompletionStream dispatches Client.CreateChatCompletionStream to the backends allowed for the request.
+func (r *Router) CreateChatCompletionStream(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...RequestOption,
+) (*ChatCompletionStream, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (*ChatCompletionStream, error) {
+		return c.CreateChatCompletionStream(ctx, request, opts...)
+	})
+}
+
+// CreateCompletion dispatches Client.CreateCompletion to the backends allowed for the request.
+func (r *Router) CreateCompletion(
+	ctx context.Context,
+	request CompletionRequest,
+	opts ...RequestOption,
+) (CompletionResponse, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (CompletionResponse, error) {
+		return c.CreateCompletion(ctx, request, opts...)
+	})
+}
+
+// CreateCompletionStream dispatches Client.CreateCompletionStream to the backends allowed for the request.
+func (r *Router) CreateCompletionStream(
+	ctx context.Context,
+	request CompletionRequest,
+	opts ...RequestOption,
+) (*CompletionStream, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (*CompletionStream, error) {
+		return c.CreateCompletionStream(ctx, request, opts...)
+	})
+}
+
+// CreateEmbeddings dispatches Client.CreateEmbeddings to the backends allowed for the request.
+func (r *Router) CreateEmbeddings(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	opts ...RequestOption,
+) (EmbeddingResponse, error) {
+	return routeCall(ctx, r, string(conv.Convert().Model), func(c *Client) (EmbeddingResponse, error) {
+		return c.CreateEmbeddings(ctx, conv, opts...)
+	})
+}
+
+// Edits dispatches Client.Edits to the backends allowed for the request.
+func (r *Router) Edits(
+	ctx context.Context,
+	request EditsRequest,
+	opts ...RequestOption,
+) (EditsResponse, error) {
+	return routeCall(ctx, r, editsModel(request), func(c *Client) (EditsResponse, error) {
+		return c.Edits(ctx, request, opts...)
+	})
+}
+
+// Moderations dispatches Client.Moderations to the backends allowed for the request.
+func (r *Router) Moderations(
+	ctx context.Context,
+	request ModerationRequest,
+	opts ...RequestOption,
+) (ModerationResponse, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (ModerationResponse, error) {
+		return c.Moderations(ctx, request, opts...)
+	})
+}
+
+// CreateImage dispatches Client.CreateImage to the backends allowed for the request.
+func (r *Router) CreateImage(
+	ctx context.Context,
+	request ImageRequest,
+	opts ...RequestOption,
+) (ImageResponse, error) {
+	return routeCall(ctx, r, request.Model, func(c *Client) (ImageResponse, error) {
+		return c.CreateImage(ctx, request, opts...)
+	})
+}
+
+// CreateEditImage dispatches Client.CreateEditImage to the backends allowed for the request.
+func (r *Router) CreateEditImage(
+	ctx context.Context,
+	request ImageEditRequest,
+	opts ...RequestOption,
+) (ImageResponse, error) {
+	bodies := imageBodies(request.Image, request.Mask)
+	return routeBodyCall(ctx, r, request.Model, bodies, func(c *Client) (ImageResponse, error) {
+		return c.CreateEditImage(ctx, request, opts...)
+	})
+}
+
+// CreateVariImage dispatches Client.CreateVariImage to the backends allowed for the request.
+func (r *Router) CreateVariImage(
+	ctx context.Context,
+	request ImageVariRequest,
+	opts ...RequestOption,
+) (ImageResponse, error) {
+	return routeBodyCall(ctx, r, request.Model, imageBodies(request.Image), func(c *Client) (ImageResponse, error) {
+		return c.CreateVariImage(ctx, request, opts...)
+	})
+}
+
+// CreateTranscription dispatches Client.CreateTranscription to the backends allowed for the request.
+func (r *Router) CreateTranscription(
+	ctx context.Context,
+	request AudioRequest,
+	opts ...RequestOption,
+) (AudioResponse, error) {
+	return routeBodyCall(ctx, r, request.Model, audioBodies(request), func(c *Client) (AudioResponse, error) {
+		return c.CreateTranscription(ctx, request, opts...)
+	})
+}
+
+// CreateTranslation dispatches Client.CreateTranslation to the backends allowed for the request.
+func (r *Router) CreateTranslation(
+	ctx context.Context,
+	request AudioRequest,
+	opts ...RequestOption,
+) (AudioResponse, error) {
+	return routeBodyCall(ctx, r, request.Model, audioBodies(request), func(c *Client) (AudioResponse, error) {
+		return c.CreateTranslation(ctx, request, opts...)
+	})
+}
+
+// CreateSpeech dispatches Client.CreateSpeech to the backends allowed for the request.
+func (r *Router) CreateSpeech(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	opts ...RequestOption,
+) (RawResponse, error) {
+	return routeCall(ctx, r, string(request.Model), func(c *Client) (RawResponse, error) {
+		return c.CreateSpeech(ctx, request, opts...)
+	})
+}
+
+// ListModels dispatches Client.ListModels to the backends allowed for the request.
+func (r *Router) ListModels(
+	ctx context.Context,
+	opts ...RequestOption,
+) (ModelsList, error) {
+	return routeCall(ctx, r, "", func(c *Client) (ModelsList, error) {
+		return c.ListModels(ctx, opts...)
+	})
+}
+
+// GetModel dispatches Client.GetModel to the backends allowed for the request.
+func (r *Router) GetModel(
+	ctx context.Context,
+	modelID string,
+	opts ...RequestOption,
+) (Model, error) {
+	return routeCall(ctx, r, "", func(c *Client) (Model, error) {
+		return c.GetModel(ctx, modelID, opts...)
+	})
+}
+
+// DeleteFineTuneModel sends Client.DeleteFineTuneModel to the resource backend of the Router.
+func (r *Router) DeleteFineTuneModel(
+	ctx context.Context,
+	modelID string,
+	opts ...RequestOption,
+) (FineTuneModelDeleteResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuneModelDeleteResponse, error) {
+		return c.DeleteFineTuneModel(ctx, modelID, opts...)
+	})
+}
+
+// ListEngines dispatches Client.ListEngines to the backends allowed for the request.
+func (r *Router) ListEngines(
+	ctx context.Context,
+	opts ...RequestOption,
+) (EnginesList, error) {
+	return routeCall(ctx, r, "", func(c *Client) (EnginesList, error) {
+		return c.ListEngines(ctx, opts...)
+	})
+}
+
+// GetEngine dispatches Client.GetEngine to the backends allowed for the request.
+func (r *Router) GetEngine(
+	ctx context.Context,
+	engineID string,
+	opts ...RequestOption,
+) (Engine, error) {
+	return routeCall(ctx, r, "", func(c *Client) (Engine, error) {
+		return c.GetEngine(ctx, engineID, opts...)
+	})
+}
+
+// CreateFileBytes sends Client.CreateFileBytes to the resource backend of the Router.
+func (r *Router) CreateFileBytes(
+	ctx context.Context,
+	request FileBytesRequest,
+	opts ...RequestOption,
+) (File, error) {
+	return resourceCall(ctx, r, func(c *Client) (File, error) {
+		return c.CreateFileBytes(ctx, request, opts...)
+	})
+}
+
+// CreateFile sends Client.CreateFile to the resource backend of the Router.
+func (r *Router) CreateFile(
+	ctx context.Context,
+	request FileRequest,
+	opts ...RequestOption,
+) (File, error) {
+	return resourceCall(ctx, r, func(c *Client) (File, error) {
+		return c.CreateFile(ctx, request, opts...)
+	})
+}
+
+// DeleteFile sends Client.DeleteFile to the resource backend of the Router.
+func (r *Router) DeleteFile(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) error {
+	_, err := resourceCall(ctx, r, func(c *Client) (struct{}, error) {
+		return struct{}{}, c.DeleteFile(ctx, fileID, opts...)
+	})
+	return err
+}
+
+// ListFiles sends Client.ListFiles to the resource backend of the Router.
+func (r *Router) ListFiles(
+	ctx context.Context,
+	opts ...RequestOption,
+) (FilesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (FilesList, error) {
+		return c.ListFiles(ctx, opts...)
+	})
+}
+
+// GetFile sends Client.GetFile to the resource backend of the Router.
+func (r *Router) GetFile(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) (File, error) {
+	return resourceCall(ctx, r, func(c *Client) (File, error) {
+		return c.GetFile(ctx, fileID, opts...)
+	})
+}
+
+// GetFileContent sends Client.GetFileContent to the resource backend of the Router.
+func (r *Router) GetFileContent(
+	ctx context.Context,
+	fileID string,
+	opts ...RequestOption,
+) (RawResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (RawResponse, error) {
+		return c.GetFileContent(ctx, fileID, opts...)
+	})
+}
+
+// CreateFineTune sends Client.CreateFineTune to the resource backend of the Router.
+func (r *Router) CreateFineTune(
+	ctx context.Context,
+	request FineTuneRequest,
+	opts ...RequestOption,
+) (FineTune, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTune, error) {
+		return c.CreateFineTune(ctx, request, opts...)
+	})
+}
+
+// CancelFineTune sends Client.CancelFineTune to the resource backend of the Router.
+func (r *Router) CancelFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (FineTune, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTune, error) {
+		return c.CancelFineTune(ctx, fineTuneID, opts...)
+	})
+}
+
+// ListFineTunes sends Client.ListFineTunes to the resource backend of the Router.
+func (r *Router) ListFineTunes(
+	ctx context.Context,
+	opts ...RequestOption,
+) (FineTuneList, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuneList, error) {
+		return c.ListFineTunes(ctx, opts...)
+	})
+}
+
+// GetFineTune sends Client.GetFineTune to the resource backend of the Router.
+func (r *Router) GetFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (FineTune, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTune, error) {
+		return c.GetFineTune(ctx, fineTuneID, opts...)
+	})
+}
+
+// DeleteFineTune sends Client.DeleteFineTune to the resource backend of the Router.
+func (r *Router) DeleteFineTune(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (FineTuneDeleteResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuneDeleteResponse, error) {
+		return c.DeleteFineTune(ctx, fineTuneID, opts...)
+	})
+}
+
+// ListFineTuneEvents sends Client.ListFineTuneEvents to the resource backend of the Router.
+func (r *Router) ListFineTuneEvents(
+	ctx context.Context,
+	fineTuneID string,
+	opts ...RequestOption,
+) (FineTuneEventList, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuneEventList, error) {
+		return c.ListFineTuneEvents(ctx, fineTuneID, opts...)
+	})
+}
+
+// CreateFineTuningJob sends Client.CreateFineTuningJob to the resource backend of the Router.
+func (r *Router) CreateFineTuningJob(
+	ctx context.Context,
+	request FineTuningJobRequest,
+	opts ...RequestOption,
+) (FineTuningJob, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuningJob, error) {
+		return c.CreateFineTuningJob(ctx, request, opts...)
+	})
+}
+
+// CancelFineTuningJob sends Client.CancelFineTuningJob to the resource backend of the Router.
+func (r *Router) CancelFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (FineTuningJob, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuningJob, error) {
+		return c.CancelFineTuningJob(ctx, fineTuningJobID, opts...)
+	})
+}
+
+// RetrieveFineTuningJob sends Client.RetrieveFineTuningJob to the resource backend of the Router.
+func (r *Router) RetrieveFineTuningJob(
+	ctx context.Context,
+	fineTuningJobID string,
+	opts ...RequestOption,
+) (FineTuningJob, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuningJob, error) {
+		return c.RetrieveFineTuningJob(ctx, fineTuningJobID, opts...)
+	})
+}
+
+// ListFineTuningJobEvents sends Client.ListFineTuningJobEvents to the resource backend of the Router.
+func (r *Router) ListFineTuningJobEvents(
+	ctx context.Context,
+	fineTuningJobID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (FineTuningJobEventList, error) {
+	return resourceCall(ctx, r, func(c *Client) (FineTuningJobEventList, error) {
+		return c.ListFineTuningJobEvents(ctx, fineTuningJobID, pagination, opts...)
+	})
+}
+
+// CreateAssistant sends Client.CreateAssistant to the resource backend of the Router.
+func (r *Router) CreateAssistant(
+	ctx context.Context,
+	request AssistantRequest,
+	opts ...RequestOption,
+) (Assistant, error) {
+	return resourceCall(ctx, r, func(c *Client) (Assistant, error) {
+		return c.CreateAssistant(ctx, request, opts...)
+	})
+}
+
+// RetrieveAssistant sends Client.RetrieveAssistant to the resource backend of the Router.
+func (r *Router) RetrieveAssistant(
+	ctx context.Context,
+	assistantID string,
+	opts ...RequestOption,
+) (Assistant, error) {
+	return resourceCall(ctx, r, func(c *Client) (Assistant, error) {
+		return c.RetrieveAssistant(ctx, assistantID, opts...)
+	})
+}
+
+// ModifyAssistant sends Client.ModifyAssistant to the resource backend of the Router.
+func (r *Router) ModifyAssistant(
+	ctx context.Context,
+	assistantID string,
+	request AssistantRequest,
+	opts ...RequestOption,
+) (Assistant, error) {
+	return resourceCall(ctx, r, func(c *Client) (Assistant, error) {
+		return c.ModifyAssistant(ctx, assistantID, request, opts...)
+	})
+}
+
+// DeleteAssistant sends Client.DeleteAssistant to the resource backend of the Router.
+func (r *Router) DeleteAssistant(
+	ctx context.Context,
+	assistantID string,
+	opts ...RequestOption,
+) (AssistantDeleteResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (AssistantDeleteResponse, error) {
+		return c.DeleteAssistant(ctx, assistantID, opts...)
+	})
+}
+
+// ListAssistants sends Client.ListAssistants to the resource backend of the Router.
+func (r *Router) ListAssistants(
+	ctx context.Context,
+	pagination Pagination,
+	opts ...RequestOption,
+) (AssistantsList, error) {
+	return resourceCall(ctx, r, func(c *Client) (AssistantsList, error) {
+		return c.ListAssistants(ctx, pagination, opts...)
+	})
+}
+
+// CreateAssistantFile sends Client.CreateAssistantFile to the resource backend of the Router.
+func (r *Router) CreateAssistantFile(
+	ctx context.Context,
+	assistantID string,
+	request AssistantFileRequest,
+	opts ...RequestOption,
+) (AssistantFile, error) {
+	return resourceCall(ctx, r, func(c *Client) (AssistantFile, error) {
+		return c.CreateAssistantFile(ctx, assistantID, request, opts...)
+	})
+}
+
+// RetrieveAssistantFile sends Client.RetrieveAssistantFile to the resource backend of the Router.
+func (r *Router) RetrieveAssistantFile(
+	ctx context.Context,
+	assistantID string,
+	fileID string,
+	opts ...RequestOption,
+) (AssistantFile, error) {
+	return resourceCall(ctx, r, func(c *Client) (AssistantFile, error) {
+		return c.RetrieveAssistantFile(ctx, assistantID, fileID, opts...)
+	})
+}
+
+// DeleteAssistantFile sends Client.DeleteAssistantFile to the resource backend of the Router.
+func (r *Router) DeleteAssistantFile(
+	ctx context.Context,
+	assistantID string,
+	fileID string,
+	opts ...RequestOption,
+) error {
+	_, err := resourceCall(ctx, r, func(c *Client) (struct{}, error) {
+		return struct{}{}, c.DeleteAssistantFile(ctx, assistantID, fileID, opts...)
+	})
+	return err
+}
+
+// ListAssistantFiles sends Client.ListAssistantFiles to the resource backend of the Router.
+func (r *Router) ListAssistantFiles(
+	ctx context.Context,
+	assistantID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (AssistantFilesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (AssistantFilesList, error) {
+		return c.ListAssistantFiles(ctx, assistantID, pagination, opts...)
+	})
+}
+
+// CreateThread sends Client.CreateThread to the resource backend of the Router.
+func (r *Router) CreateThread(
+	ctx context.Context,
+	request ThreadRequest,
+	opts ...RequestOption,
+) (Thread, error) {
+	return resourceCall(ctx, r, func(c *Client) (Thread, error) {
+		return c.CreateThread(ctx, request, opts...)
+	})
+}
+
+// RetrieveThread sends Client.RetrieveThread to the resource backend of the Router.
+func (r *Router) RetrieveThread(
+	ctx context.Context,
+	threadID string,
+	opts ...RequestOption,
+) (Thread, error) {
+	return resourceCall(ctx, r, func(c *Client) (Thread, error) {
+		return c.RetrieveThread(ctx, threadID, opts...)
+	})
+}
+
+// ModifyThread sends Client.ModifyThread to the resource backend of the Router.
+func (r *Router) ModifyThread(
+	ctx context.Context,
+	threadID string,
+	request ModifyThreadRequest,
+	opts ...RequestOption,
+) (Thread, error) {
+	return resourceCall(ctx, r, func(c *Client) (Thread, error) {
+		return c.ModifyThread(ctx, threadID, request, opts...)
+	})
+}
+
+// DeleteThread sends Client.DeleteThread to the resource backend of the Router.
+func (r *Router) DeleteThread(
+	ctx context.Context,
+	threadID string,
+	opts ...RequestOption,
+) (ThreadDeleteResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (ThreadDeleteResponse, error) {
+		return c.DeleteThread(ctx, threadID, opts...)
+	})
+}
+
+// CreateMessage sends Client.CreateMessage to the resource backend of the Router.
+func (r *Router) CreateMessage(
+	ctx context.Context,
+	threadID string,
+	request MessageRequest,
+	opts ...RequestOption,
+) (Message, error) {
+	return resourceCall(ctx, r, func(c *Client) (Message, error) {
+		return c.CreateMessage(ctx, threadID, request, opts...)
+	})
+}
+
+// ListMessage sends Client.ListMessage to the resource backend of the Router.
+func (r *Router) ListMessage(
+	ctx context.Context,
+	threadID string,
+	options ListMessageOptions,
+	opts ...RequestOption,
+) (MessagesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (MessagesList, error) {
+		return c.ListMessage(ctx, threadID, options, opts...)
+	})
+}
+
+// RetrieveMessage sends Client.RetrieveMessage to the resource backend of the Router.
+func (r *Router) RetrieveMessage(
+	ctx context.Context,
+	threadID string,
+	messageID string,
+	opts ...RequestOption,
+) (Message, error) {
+	return resourceCall(ctx, r, func(c *Client) (Message, error) {
+		return c.RetrieveMessage(ctx, threadID, messageID, opts...)
+	})
+}
+
+// ModifyMessage sends Client.ModifyMessage to the resource backend of the Router.
+func (r *Router) ModifyMessage(
+	ctx context.Context,
+	threadID string,
+	messageID string,
+	metadata map[string]string,
+	opts ...RequestOption,
+) (Message, error) {
+	return resourceCall(ctx, r, func(c *Client) (Message, error) {
+		return c.ModifyMessage(ctx, threadID, messageID, metadata, opts...)
+	})
+}
+
+// RetrieveMessageFile sends Client.RetrieveMessageFile to the resource backend of the Router.
+func (r *Router) RetrieveMessageFile(
+	ctx context.Context,
+	threadID string,
+	messageID string,
+	fileID string,
+	opts ...RequestOption,
+) (MessageFile, error) {
+	return resourceCall(ctx, r, func(c *Client) (MessageFile, error) {
+		return c.RetrieveMessageFile(ctx, threadID, messageID, fileID, opts...)
+	})
+}
+
+// ListMessageFiles sends Client.ListMessageFiles to the resource backend of the Router.
+func (r *Router) ListMessageFiles(
+	ctx context.Context,
+	threadID string,
+	messageID string,
+	opts ...RequestOption,
+) (MessageFilesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (MessageFilesList, error) {
+		return c.ListMessageFiles(ctx, threadID, messageID, opts...)
+	})
+}
+
+// DeleteMessage sends Client.DeleteMessage to the resource backend of the Router.
+func (r *Router) DeleteMessage(
+	ctx context.Context,
+	threadID string,
+	messageID string,
+	opts ...RequestOption,
+) (MessageDeletionStatus, error) {
+	return resourceCall(ctx, r, func(c *Client) (MessageDeletionStatus, error) {
+		return c.DeleteMessage(ctx, threadID, messageID, opts...)
+	})
+}
+
+// CreateRun sends Client.CreateRun to the resource backend of the Router.
+func (r *Router) CreateRun(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.CreateRun(ctx, threadID, request, opts...)
+	})
+}
+
+// RetrieveRun sends Client.RetrieveRun to the resource backend of the Router.
+func (r *Router) RetrieveRun(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.RetrieveRun(ctx, threadID, runID, opts...)
+	})
+}
+
+// ModifyRun sends Client.ModifyRun to the resource backend of the Router.
+func (r *Router) ModifyRun(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	request RunModifyRequest,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.ModifyRun(ctx, threadID, runID, request, opts...)
+	})
+}
+
+// ListRuns sends Client.ListRuns to the resource backend of the Router.
+func (r *Router) ListRuns(
+	ctx context.Context,
+	threadID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (RunList, error) {
+	return resourceCall(ctx, r, func(c *Client) (RunList, error) {
+		return c.ListRuns(ctx, threadID, pagination, opts...)
+	})
+}
+
+// SubmitToolOutputs sends Client.SubmitToolOutputs to the resource backend of the Router.
+func (r *Router) SubmitToolOutputs(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	request SubmitToolOutputsRequest,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.SubmitToolOutputs(ctx, threadID, runID, request, opts...)
+	})
+}
+
+// CancelRun sends Client.CancelRun to the resource backend of the Router.
+func (r *Router) CancelRun(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.CancelRun(ctx, threadID, runID, opts...)
+	})
+}
+
+// CreateThreadAndRun sends Client.CreateThreadAndRun to the resource backend of the Router.
+func (r *Router) CreateThreadAndRun(
+	ctx context.Context,
+	request CreateThreadAndRunRequest,
+	opts ...RequestOption,
+) (Run, error) {
+	return resourceCall(ctx, r, func(c *Client) (Run, error) {
+		return c.CreateThreadAndRun(ctx, request, opts...)
+	})
+}
+
+// RetrieveRunStep sends Client.RetrieveRunStep to the resource backend of the Router.
+func (r *Router) RetrieveRunStep(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	stepID string,
+	opts ...RequestOption,
+) (RunStep, error) {
+	return resourceCall(ctx, r, func(c *Client) (RunStep, error) {
+		return c.RetrieveRunStep(ctx, threadID, runID, stepID, opts...)
+	})
+}
+
+// ListRunSteps sends Client.ListRunSteps to the resource backend of the Router.
+func (r *Router) ListRunSteps(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (RunStepList, error) {
+	return resourceCall(ctx, r, func(c *Client) (RunStepList, error) {
+		return c.ListRunSteps(ctx, threadID, runID, pagination, opts...)
+	})
+}
+
+// CreateVectorStore sends Client.CreateVectorStore to the resource backend of the Router.
+func (r *Router) CreateVectorStore(
+	ctx context.Context,
+	request VectorStoreRequest,
+	opts ...RequestOption,
+) (VectorStore, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStore, error) {
+		return c.CreateVectorStore(ctx, request, opts...)
+	})
+}
+
+// RetrieveVectorStore sends Client.RetrieveVectorStore to the resource backend of the Router.
+func (r *Router) RetrieveVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	opts ...RequestOption,
+) (VectorStore, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStore, error) {
+		return c.RetrieveVectorStore(ctx, vectorStoreID, opts...)
+	})
+}
+
+// ModifyVectorStore sends Client.ModifyVectorStore to the resource backend of the Router.
+func (r *Router) ModifyVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreRequest,
+	opts ...RequestOption,
+) (VectorStore, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStore, error) {
+		return c.ModifyVectorStore(ctx, vectorStoreID, request, opts...)
+	})
+}
+
+// DeleteVectorStore sends Client.DeleteVectorStore to the resource backend of the Router.
+func (r *Router) DeleteVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	opts ...RequestOption,
+) (VectorStoreDeleteResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreDeleteResponse, error) {
+		return c.DeleteVectorStore(ctx, vectorStoreID, opts...)
+	})
+}
+
+// ListVectorStores sends Client.ListVectorStores to the resource backend of the Router.
+func (r *Router) ListVectorStores(
+	ctx context.Context,
+	pagination Pagination,
+	opts ...RequestOption,
+) (VectorStoresList, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoresList, error) {
+		return c.ListVectorStores(ctx, pagination, opts...)
+	})
+}
+
+// CreateVectorStoreFile sends Client.CreateVectorStoreFile to the resource backend of the Router.
+func (r *Router) CreateVectorStoreFile(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreFileRequest,
+	opts ...RequestOption,
+) (VectorStoreFile, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFile, error) {
+		return c.CreateVectorStoreFile(ctx, vectorStoreID, request, opts...)
+	})
+}
+
+// RetrieveVectorStoreFile sends Client.RetrieveVectorStoreFile to the resource backend of the Router.
+func (r *Router) RetrieveVectorStoreFile(
+	ctx context.Context,
+	vectorStoreID string,
+	fileID string,
+	opts ...RequestOption,
+) (VectorStoreFile, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFile, error) {
+		return c.RetrieveVectorStoreFile(ctx, vectorStoreID, fileID, opts...)
+	})
+}
+
+// DeleteVectorStoreFile sends Client.DeleteVectorStoreFile to the resource backend of the Router.
+func (r *Router) DeleteVectorStoreFile(
+	ctx context.Context,
+	vectorStoreID string,
+	fileID string,
+	opts ...RequestOption,
+) error {
+	_, err := resourceCall(ctx, r, func(c *Client) (struct{}, error) {
+		return struct{}{}, c.DeleteVectorStoreFile(ctx, vectorStoreID, fileID, opts...)
+	})
+	return err
+}
+
+// ListVectorStoreFiles sends Client.ListVectorStoreFiles to the resource backend of the Router.
+func (r *Router) ListVectorStoreFiles(
+	ctx context.Context,
+	vectorStoreID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (VectorStoreFilesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFilesList, error) {
+		return c.ListVectorStoreFiles(ctx, vectorStoreID, pagination, opts...)
+	})
+}
+
+// CreateVectorStoreFileBatch sends Client.CreateVectorStoreFileBatch to the resource backend of the Router.
+func (r *Router) CreateVectorStoreFileBatch(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreFileBatchRequest,
+	opts ...RequestOption,
+) (VectorStoreFileBatch, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFileBatch, error) {
+		return c.CreateVectorStoreFileBatch(ctx, vectorStoreID, request, opts...)
+	})
+}
+
+// RetrieveVectorStoreFileBatch sends Client.RetrieveVectorStoreFileBatch to the resource backend of the Router.
+func (r *Router) RetrieveVectorStoreFileBatch(
+	ctx context.Context,
+	vectorStoreID string,
+	batchID string,
+	opts ...RequestOption,
+) (VectorStoreFileBatch, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFileBatch, error) {
+		return c.RetrieveVectorStoreFileBatch(ctx, vectorStoreID, batchID, opts...)
+	})
+}
+
+// CancelVectorStoreFileBatch sends Client.CancelVectorStoreFileBatch to the resource backend of the Router.
+func (r *Router) CancelVectorStoreFileBatch(
+	ctx context.Context,
+	vectorStoreID string,
+	batchID string,
+	opts ...RequestOption,
+) (VectorStoreFileBatch, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFileBatch, error) {
+		return c.CancelVectorStoreFileBatch(ctx, vectorStoreID, batchID, opts...)
+	})
+}
+
+// ListVectorStoreFilesInBatch sends Client.ListVectorStoreFilesInBatch to the resource backend of the Router.
+func (r *Router) ListVectorStoreFilesInBatch(
+	ctx context.Context,
+	vectorStoreID string,
+	batchID string,
+	pagination Pagination,
+	opts ...RequestOption,
+) (VectorStoreFilesList, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreFilesList, error) {
+		return c.ListVectorStoreFilesInBatch(ctx, vectorStoreID, batchID, pagination, opts...)
+	})
+}
+
+// CreateBatch sends Client.CreateBatch to the resource backend of the Router.
+func (r *Router) CreateBatch(
+	ctx context.Context,
+	request CreateBatchRequest,
+	opts ...RequestOption,
+) (BatchResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (BatchResponse, error) {
+		return c.CreateBatch(ctx, request, opts...)
+	})
+}
+
+// UploadBatchFile sends Client.UploadBatchFile to the resource backend of the Router.
+func (r *Router) UploadBatchFile(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	opts ...RequestOption,
+) (File, error) {
+	return resourceCall(ctx, r, func(c *Client) (File, error) {
+		return c.UploadBatchFile(ctx, request, opts...)
+	})
+}
+
+// CreateBatchWithUploadFile sends Client.CreateBatchWithUploadFile to the resource backend of the Router.
+func (r *Router) CreateBatchWithUploadFile(
+	ctx context.Context,
+	request CreateBatchWithUploadFileRequest,
+	opts ...RequestOption,
+) (BatchResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (BatchResponse, error) {
+		return c.CreateBatchWithUploadFile(ctx, request, opts...)
+	})
+}
+
+// RetrieveBatch sends Client.RetrieveBatch to the resource backend of the Router.
+func (r *Router) RetrieveBatch(
+	ctx context.Context,
+	batchID string,
+	opts ...RequestOption,
+) (BatchResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (BatchResponse, error) {
+		return c.RetrieveBatch(ctx, batchID, opts...)
+	})
+}
+
+// CancelBatch sends Client.CancelBatch to the resource backend of the Router.
+func (r *Router) CancelBatch(
+	ctx context.Context,
+	batchID string,
+	opts ...RequestOption,
+) (BatchResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (BatchResponse, error) {
+		return c.CancelBatch(ctx, batchID, opts...)
+	})
+}
+
+// ListBatch sends Client.ListBatch to the resource backend of the Router.
+func (r *Router) ListBatch(
+	ctx context.Context,
+	pagination Pagination,
+	opts ...RequestOption,
+) (ListBatchResponse, error) {
+	return resourceCall(ctx, r, func(c *Client) (ListBatchResponse, error) {
+		return c.ListBatch(ctx, pagination, opts...)
+	})
+}
+
+// ListAssistantsPager returns a Pager which fetches every page through Router.ListAssistants.
+func (r *Router) ListAssistantsPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Assistant] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (AssistantsList, error) {
+		return r.ListAssistants(ctx, pagination, opts...)
+	}, AssistantsList.page, pagination, options)
+}
+
+// ListAssistantFilesPager returns a Pager which fetches every page through Router.ListAssistantFiles.
+func (r *Router) ListAssistantFilesPager(
+	ctx context.Context,
+	assistantID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[AssistantFile] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (AssistantFilesList, error) {
+		return r.ListAssistantFiles(ctx, assistantID, pagination, opts...)
+	}, AssistantFilesList.page, pagination, options)
+}
+
+// ListMessagePager returns a Pager which fetches every page through Router.ListMessage.
+func (r *Router) ListMessagePager(
+	ctx context.Context,
+	threadID string,
+	listOptions ListMessageOptions,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Message] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (MessagesList, error) {
+		listOptions.Pagination = pagination
+		return r.ListMessage(ctx, threadID, listOptions, opts...)
+	}, MessagesList.page, listOptions.Pagination, options)
+}
+
+// ListRunsPager returns a Pager which fetches every page through Router.ListRuns.
+func (r *Router) ListRunsPager(
+	ctx context.Context,
+	threadID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Run] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (RunList, error) {
+		return r.ListRuns(ctx, threadID, pagination, opts...)
+	}, RunList.page, pagination, options)
+}
+
+// ListRunStepsPager returns a Pager which fetches every page through Router.ListRunSteps.
+func (r *Router) ListRunStepsPager(
+	ctx context.Context,
+	threadID string,
+	runID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[RunStep] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (RunStepList, error) {
+		return r.ListRunSteps(ctx, threadID, runID, pagination, opts...)
+	}, RunStepList.page, pagination, options)
+}
+
+// ListVectorStoresPager returns a Pager which fetches every page through Router.ListVectorStores.
+func (r *Router) ListVectorStoresPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[VectorStore] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (VectorStoresList, error) {
+		return r.ListVectorStores(ctx, pagination, opts...)
+	}, VectorStoresList.page, pagination, options)
+}
+
+// ListVectorStoreFilesPager returns a Pager which fetches every page through Router.ListVectorStoreFiles.
+func (r *Router) ListVectorStoreFilesPager(
+	ctx context.Context,
+	vectorStoreID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[VectorStoreFile] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (VectorStoreFilesList, error) {
+		return r.ListVectorStoreFiles(ctx, vectorStoreID, pagination, opts...)
+	}, VectorStoreFilesList.page, pagination, options)
+}
+
+// ListBatchPager returns a Pager which fetches every page through Router.ListBatch.
+func (r *Router) ListBatchPager(
+	ctx context.Context,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[Batch] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (ListBatchResponse, error) {
+		return r.ListBatch(ctx, pagination, opts...)
+	}, ListBatchResponse.page, pagination, options)
+}
+
+// ListFineTuningJobEventsPager returns a Pager which fetches every page through Router.ListFineTuningJobEvents.
+func (r *Router) ListFineTuningJobEventsPager(
+	ctx context.Context,
+	fineTuningJobID string,
+	pagination Pagination,
+	options PagerOptions,
+	opts ...RequestOption,
+) *Pager[FineTuningJobEvent] {
+	return newListPager(ctx, func(ctx context.Context, pagination Pagination) (FineTuningJobEventList, error) {
+		return r.ListFineTuningJobEvents(ctx, fineTuningJobID, pagination, opts...)
+	}, FineTuningJobEventList.page, pagination, options)
+}
+
+// RunAndWait drives Client.RunAndWait through the Router, routing every call it makes.
+func (r *Router) RunAndWait(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+	handler RunToolHandler,
+	poll RunPollOptions,
+	opts ...RequestOption,
+) (RunResult, error) {
+	return runAndWait(ctx, r, threadID, request, handler, poll, opts)
+}
+
+// SearchVectorStore sends Client.SearchVectorStore to the resource backend of the Router.
+func (r *Router) SearchVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreSearchRequest,
+	opts ...RequestOption,
+) (VectorStoreSearchResults, error) {
+	return resourceCall(ctx, r, func(c *Client) (VectorStoreSearchResults, error) {
+		return c.SearchVectorStore(ctx, vectorStoreID, request, opts...)
+	})
+}
+
+// UploadFilesAndPoll drives Client.UploadFilesAndPoll through the Router, routing every call it makes.
+func (r *Router) UploadFilesAndPoll(
+	ctx context.Context,
+	vectorStoreID string,
+	paths []string,
+	chunkingStrategy *ChunkingStrategy,
+	opts ...RequestOption,
+) (VectorStoreFileBatch, error) {
+	return uploadFilesAndPoll(ctx, r, vectorStoreID, paths, chunkingStrategy, opts)
+}
+
+// CreateUpload sends Client.CreateUpload to the resource backend of the Router.
+func (r *Router) CreateUpload(
+	ctx context.Context,
+	request UploadRequest,
+	opts ...RequestOption,
+) (Upload, error) {
+	return resourceCall(ctx, r, func(c *Client) (Upload, error) {
+		return c.CreateUpload(ctx, request, opts...)
+	})
+}
+
+// AddUploadPart sends Client.AddUploadPart to the resource backend of the Router.
+func (r *Router) AddUploadPart(
+	ctx context.Context,
+	uploadID string,
+	data io.Reader,
+	opts ...RequestOption,
+) (UploadPart, error) {
+	return resourceCall(ctx, r, func(c *Client) (UploadPart, error) {
+		return c.AddUploadPart(ctx, uploadID, data, opts...)
+	})
+}
+
+// CompleteUpload sends Client.CompleteUpload to the resource backend of the Router.
+func (r *Router) CompleteUpload(
+	ctx context.Context,
+	uploadID string,
+	request CompleteUploadRequest,
+	opts ...RequestOption,
+) (Upload, error) {
+	return resourceCall(ctx, r, func(c *Client) (Upload, error) {
+		return c.CompleteUpload(ctx, uploadID, request, opts...)
+	})
+}
+
+// CancelUpload sends Client.CancelUpload to the resource backend of the Router.
+func (r *Router) CancelUpload(
+	ctx context.Context,
+	uploadID string,
+	opts ...RequestOption,
+) (Upload, error) {
+	return resourceCall(ctx, r, func(c *Client) (Upload, error) {
+		return c.CancelUpload(ctx, uploadID, opts...)
+	})
+}
+
+// UploadLargeFile drives Client.UploadLargeFile through the Router, routing every call it makes.
+func (r *Router) UploadLargeFile(
+	ctx context.Context,
+	reader io.ReaderAt,
+	size int64,
+	purpose PurposeType,
+	options UploadLargeFileOptions,
+	opts ...RequestOption,
+) (Upload, error) {
+	return uploadLargeFile(ctx, r, reader, size, purpose, options, opts)
+}
+
+// ReadBatchResults returns a Client.ReadBatchResults reader downloading the files through the Router.
+func (r *Router) ReadBatchResults(ctx context.Context, batch Batch, opts ...RequestOption) *BatchResultReader {
+	return newBatchResultReader(ctx, r, batch, opts)
+}
+
+// NewBatchRunner returns a BatchRunner making every call through the Router.
+func (r *Router) NewBatchRunner(options BatchRunnerOptions, opts ...RequestOption) *BatchRunner {
+	return &BatchRunner{api: r, options: options.withDefaults(), opts: opts}
+}
+
+// RunLocalBatch runs the lines of request like Client.RunLocalBatch, routing every request.
+func (r *Router) RunLocalBatch(
+	ctx context.Context,
+	request UploadBatchFileRequest,
+	options LocalBatchOptions,
+	opts ...RequestOption,
+) (*LocalBatch, error) {
+	return runLocalBatch(ctx, r, bytes.NewReader(request.MarshalJSONL()), options, opts)
+}
+
+// RunLocalBatchFile runs a batch input file like Client.RunLocalBatchFile, routing every request.
+func (r *Router) RunLocalBatchFile(
+	ctx context.Context,
+	jsonl io.Reader,
+	options LocalBatchOptions,
+	opts ...RequestOption,
+) (*LocalBatch, error) {
+	return runLocalBatch(ctx, r, jsonl, options, opts)
+}
+
+// CreateEmbeddingsBatched splits the inputs like Client.CreateEmbeddingsBatched, routing every request.
+func (r *Router) CreateEmbeddingsBatched(
+	ctx context.Context,
+	conv EmbeddingRequestConverter,
+	options EmbeddingBatchOptions,
+	opts ...RequestOption,
+) (EmbeddingResponse, error) {
+	return createEmbeddingsBatched(ctx, r, conv, options, opts)
+}
+
+// NewCachedEmbedder returns a CachedEmbedder creating embeddings through the Router.
+func (r *Router) NewCachedEmbedder(cache EmbeddingCache) *CachedEmbedder {
+	return newCachedEmbedder(r, cache)
+}
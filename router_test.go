@@ -0,0 +1,376 @@
+package openai //nolint:testpackage // testing private fields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type routerTestBackend struct {
+	server *httptest.Server
+	hits   int32
+	status int32
+	delay  time.Duration
+	path   atomic.Value
+	// bodySize is the size of the last request body.
+	bodySize int64
+}
+
+func newRouterTestBackend(t *testing.T) *routerTestBackend {
+	t.Helper()
+	b := &routerTestBackend{status: http.StatusOK}
+	b.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&b.hits, 1)
+		b.path.Store(r.URL.Path)
+		size, _ := io.Copy(io.Discard, r.Body)
+		atomic.StoreInt64(&b.bodySize, size)
+		time.Sleep(b.delay)
+		status := int(atomic.LoadInt32(&b.status))
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"message":"status %d","type":"server_error"}}`, status)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"chatcmpl-%s","model":"gpt-4o"}`, b.server.URL)
+	}))
+	t.Cleanup(b.server.Close)
+	return b
+}
+
+func (b *routerTestBackend) client() *Client {
+	config := DefaultConfig("token")
+	config.BaseURL = b.server.URL + "/v1"
+	return NewClientWithConfig(config)
+}
+
+func (b *routerTestBackend) hitCount() int {
+	return int(atomic.LoadInt32(&b.hits))
+}
+
+var routerTestRequest = ChatCompletionRequest{
+	Model:    GPT4o,
+	Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "hello"}},
+}
+
+func TestRouterImplementsClientMethodSet(t *testing.T) {
+	clientType := reflect.TypeOf(&Client{})
+	routerType := reflect.TypeOf(&Router{})
+	for i := 0; i < clientType.NumMethod(); i++ {
+		method := clientType.Method(i)
+		routerMethod, ok := routerType.MethodByName(method.Name)
+		if !ok {
+			t.Errorf("Router is missing method %s", method.Name)
+			continue
+		}
+		// Compare signatures without the receiver.
+		want, got := method.Type, routerMethod.Type
+		if want.NumIn() != got.NumIn() || want.NumOut() != got.NumOut() || want.IsVariadic() != got.IsVariadic() {
+			t.Errorf("Router.%s has signature %v, want %v", method.Name, got, want)
+			continue
+		}
+		for j := 1; j < want.NumIn(); j++ {
+			if want.In(j) != got.In(j) {
+				t.Errorf("Router.%s argument %d is %v, want %v", method.Name, j, got.In(j), want.In(j))
+			}
+		}
+		for j := 0; j < want.NumOut(); j++ {
+			if want.Out(j) != got.Out(j) {
+				t.Errorf("Router.%s result %d is %v, want %v", method.Name, j, got.Out(j), want.Out(j))
+			}
+		}
+	}
+}
+
+func TestNewRouterValidation(t *testing.T) {
+	_, err := NewRouter(RouterConfig{})
+	if !errors.Is(err, ErrRouterNoBackends) {
+		t.Fatalf("expected ErrRouterNoBackends, got %v", err)
+	}
+
+	client := NewClient("token")
+	_, err = NewRouter(RouterConfig{Backends: []RouterBackend{{Name: "a", Client: client}, {Name: "a", Client: client}}})
+	if !errors.Is(err, ErrRouterDuplicateName) {
+		t.Fatalf("expected ErrRouterDuplicateName, got %v", err)
+	}
+
+	_, err = NewRouter(RouterConfig{
+		Backends: []RouterBackend{{Name: "a", Client: client}},
+		Rules:    []RouteRule{{Model: "gpt-4o", Backends: []string{"b"}}},
+	})
+	if !errors.Is(err, ErrRouterUnknownBackend) {
+		t.Fatalf("expected ErrRouterUnknownBackend, got %v", err)
+	}
+}
+
+func TestRouterPriorityFailoverAndCircuitBreaker(t *testing.T) {
+	primary := newRouterTestBackend(t)
+	secondary := newRouterTestBackend(t)
+	atomic.StoreInt32(&primary.status, http.StatusInternalServerError)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "secondary", Client: secondary.client(), Priority: 1},
+			{Name: "primary", Client: primary.client(), Priority: 0},
+		},
+		Strategy:         RoutingStrategyPriority,
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+	})
+	checks.NoError(t, err, "NewRouter error")
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err = router.CreateChatCompletion(ctx, routerTestRequest)
+		checks.NoError(t, err, "CreateChatCompletion should fail over to the secondary backend")
+	}
+	if primary.hitCount() != 2 {
+		t.Fatalf("primary should be skipped once its circuit opens, got %d hits", primary.hitCount())
+	}
+	if secondary.hitCount() != 3 {
+		t.Fatalf("secondary should serve every request, got %d hits", secondary.hitCount())
+	}
+	if health := router.Health(); health[1].Available || health[1].ConsecutiveFailures != 2 {
+		t.Fatalf("unexpected primary health: %+v", health[1])
+	}
+
+	// After the cooldown the primary is tried again and recovers.
+	atomic.StoreInt32(&primary.status, http.StatusOK)
+	now = now.Add(2 * time.Minute)
+	_, err = router.CreateChatCompletion(ctx, routerTestRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if primary.hitCount() != 3 {
+		t.Fatalf("primary should be retried after cooldown, got %d hits", primary.hitCount())
+	}
+	if health := router.Health(); !health[1].Available || health[1].ConsecutiveFailures != 0 {
+		t.Fatalf("primary should be healthy again: %+v", health[1])
+	}
+}
+
+func TestRouterDoesNotFailOverOnClientErrors(t *testing.T) {
+	primary := newRouterTestBackend(t)
+	secondary := newRouterTestBackend(t)
+	atomic.StoreInt32(&primary.status, http.StatusBadRequest)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "primary", Client: primary.client()},
+			{Name: "secondary", Client: secondary.client(), Priority: 1},
+		},
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	_, err = router.CreateChatCompletion(context.Background(), routerTestRequest)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the 400 APIError to be returned, got %v", err)
+	}
+	if secondary.hitCount() != 0 {
+		t.Fatalf("a 400 must not be retried on another backend")
+	}
+}
+
+func TestRouterRateLimitFailsOver(t *testing.T) {
+	primary := newRouterTestBackend(t)
+	secondary := newRouterTestBackend(t)
+	atomic.StoreInt32(&primary.status, http.StatusTooManyRequests)
+	atomic.StoreInt32(&secondary.status, http.StatusTooManyRequests)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "primary", Client: primary.client()},
+			{Name: "secondary", Client: secondary.client(), Priority: 1},
+		},
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	_, err = router.CreateChatCompletion(context.Background(), routerTestRequest)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the last 429 to be returned, got %v", err)
+	}
+	if primary.hitCount() != 1 || secondary.hitCount() != 1 {
+		t.Fatalf("expected both backends to be tried once, got %d and %d", primary.hitCount(), secondary.hitCount())
+	}
+}
+
+func TestRouterWeightedRoundRobin(t *testing.T) {
+	heavy := newRouterTestBackend(t)
+	light := newRouterTestBackend(t)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "heavy", Client: heavy.client(), Weight: 3},
+			{Name: "light", Client: light.client(), Weight: 1},
+		},
+		Strategy: RoutingStrategyWeightedRoundRobin,
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	for i := 0; i < 8; i++ {
+		_, err = router.CreateChatCompletion(context.Background(), routerTestRequest)
+		checks.NoError(t, err, "CreateChatCompletion error")
+	}
+	if heavy.hitCount() != 6 || light.hitCount() != 2 {
+		t.Fatalf("expected a 6/2 split, got %d/%d", heavy.hitCount(), light.hitCount())
+	}
+}
+
+func TestRouterLeastLatency(t *testing.T) {
+	slow := newRouterTestBackend(t)
+	slow.delay = 30 * time.Millisecond
+	fast := newRouterTestBackend(t)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "slow", Client: slow.client()},
+			{Name: "fast", Client: fast.client()},
+		},
+		Strategy: RoutingStrategyLeastLatency,
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	for i := 0; i < 5; i++ {
+		_, err = router.CreateChatCompletion(context.Background(), routerTestRequest)
+		checks.NoError(t, err, "CreateChatCompletion error")
+	}
+	if slow.hitCount() != 1 {
+		t.Fatalf("slow backend should only be used until its latency is known, got %d hits", slow.hitCount())
+	}
+	if fast.hitCount() != 4 {
+		t.Fatalf("fast backend should serve the remaining requests, got %d hits", fast.hitCount())
+	}
+}
+
+func TestRouterRulesUseBackendAzureMapping(t *testing.T) {
+	openaiBackend := newRouterTestBackend(t)
+	azureBackend := newRouterTestBackend(t)
+
+	azureConfig := DefaultAzureConfig("token", azureBackend.server.URL)
+	azureConfig.AzureModelMapperFunc = func(model string) string {
+		return map[string]string{GPT4o: "prod-gpt4o"}[model]
+	}
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "openai", Client: openaiBackend.client()},
+			{Name: "azure", Client: NewClientWithConfig(azureConfig)},
+		},
+		Rules: []RouteRule{
+			{Model: "gpt-4o*", Backends: []string{"azure"}},
+			{Model: "", Backends: []string{"openai"}},
+		},
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	ctx := context.Background()
+	_, err = router.CreateChatCompletion(ctx, routerTestRequest)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if got := azureBackend.path.Load(); got != "/openai/deployments/prod-gpt4o/chat/completions" {
+		t.Fatalf("unexpected azure path %v", got)
+	}
+
+	_, err = router.ListFiles(ctx)
+	checks.NoError(t, err, "ListFiles error")
+	if openaiBackend.hitCount() != 1 || azureBackend.hitCount() != 1 {
+		t.Fatalf("calls without a model should follow the empty-model rule")
+	}
+}
+
+func TestRouterPinsResourceCalls(t *testing.T) {
+	resources := newRouterTestBackend(t)
+	other := newRouterTestBackend(t)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "other", Client: other.client(), Priority: 1},
+			{Name: "resources", Client: resources.client()},
+		},
+		Strategy: RoutingStrategyWeightedRoundRobin,
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	// Resources are used on the backend which created them, whatever the strategy.
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err = router.RetrieveThread(ctx, "thread_abc123")
+		checks.NoError(t, err, "RetrieveThread error")
+	}
+	if resources.hitCount() != 4 || other.hitCount() != 0 {
+		t.Fatalf("expected every call to the resource backend, got %d and %d", resources.hitCount(), other.hitCount())
+	}
+
+	// Creates are not sent again to another backend.
+	atomic.StoreInt32(&resources.status, http.StatusInternalServerError)
+	_, err = router.CreateBatch(ctx, CreateBatchRequest{
+		InputFileID: "file-abc123",
+		Endpoint:    BatchEndpointChatCompletions,
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the 500 APIError to be returned, got %v", err)
+	}
+	if resources.hitCount() != 5 || other.hitCount() != 0 {
+		t.Fatalf("a failed create must not be retried on another backend")
+	}
+
+	// Calls routed by model still fail over.
+	_, err = router.CreateChatCompletion(ctx, routerTestRequest)
+	checks.NoError(t, err, "CreateChatCompletion should fail over to the other backend")
+	if other.hitCount() != 1 {
+		t.Fatalf("expected the chat completion to fail over, got %d hits", other.hitCount())
+	}
+}
+
+func TestRouterRewindsRequestBodies(t *testing.T) {
+	primary := newRouterTestBackend(t)
+	secondary := newRouterTestBackend(t)
+	atomic.StoreInt32(&primary.status, http.StatusInternalServerError)
+
+	router, err := NewRouter(RouterConfig{
+		Backends: []RouterBackend{
+			{Name: "primary", Client: primary.client()},
+			{Name: "secondary", Client: secondary.client(), Priority: 1},
+		},
+	})
+	checks.NoError(t, err, "NewRouter error")
+
+	ctx := context.Background()
+	audio := strings.Repeat("audio", 100)
+	_, err = router.CreateTranscription(ctx, AudioRequest{
+		Model:    Whisper1,
+		FilePath: "audio.mp3",
+		Reader:   strings.NewReader(audio),
+	})
+	checks.NoError(t, err, "CreateTranscription should fail over to the secondary backend")
+	sent, resent := atomic.LoadInt64(&primary.bodySize), atomic.LoadInt64(&secondary.bodySize)
+	if sent <= int64(len(audio)) || resent != sent {
+		t.Fatalf("expected the whole body to be sent again, got %d and %d bytes", sent, resent)
+	}
+
+	// A body which can't be rewound is only sent once.
+	_, err = router.CreateTranscription(ctx, AudioRequest{
+		Model:    Whisper1,
+		FilePath: "audio.mp3",
+		Reader:   io.MultiReader(strings.NewReader(audio)),
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected the 500 APIError to be returned, got %v", err)
+	}
+	if primary.hitCount() != 2 || secondary.hitCount() != 1 {
+		t.Fatalf("a body which can't be rewound must not fail over, got %d and %d hits",
+			primary.hitCount(), secondary.hitCount())
+	}
+}
@@ -37,6 +37,8 @@ type Run struct {
 	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
 	// ThreadTruncationStrategy defines the truncation strategy to use for the thread.
 	TruncationStrategy *ThreadTruncationStrategy `json:"truncation_strategy,omitempty"`
+	// IncompleteDetails explains why a run ended with RunStatusIncomplete.
+	IncompleteDetails *RunIncompleteDetails `json:"incomplete_details,omitempty"`
 
 	httpHeader
 }
@@ -75,6 +77,10 @@ type RunLastError struct {
 	Message string   `json:"message"`
 }
 
+type RunIncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
 type RunError string
 
 const (
@@ -149,6 +155,10 @@ type RunModifyRequest struct {
 type RunList struct {
 	Runs []Run `json:"data"`
 
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+
 	httpHeader
 }
 
@@ -206,13 +216,104 @@ const (
 type StepDetails struct {
 	Type            RunStepType                 `json:"type"`
 	MessageCreation *StepDetailsMessageCreation `json:"message_creation,omitempty"`
-	ToolCalls       []ToolCall                  `json:"tool_calls,omitempty"`
+	ToolCalls       []RunStepToolCall           `json:"tool_calls,omitempty"`
 }
 
 type StepDetailsMessageCreation struct {
 	MessageID string `json:"message_id"`
 }
 
+type RunStepToolCallType string
+
+const (
+	RunStepToolCallTypeFunction        RunStepToolCallType = "function"
+	RunStepToolCallTypeCodeInterpreter RunStepToolCallType = "code_interpreter"
+	RunStepToolCallTypeFileSearch      RunStepToolCallType = "file_search"
+)
+
+// RunStepToolCall is a tool call made in a tool_calls run step. Only the
+// field matching Type is set.
+type RunStepToolCall struct {
+	ID              string                  `json:"id"`
+	Type            RunStepToolCallType     `json:"type"`
+	Function        *RunStepFunctionCall    `json:"function,omitempty"`
+	CodeInterpreter *RunStepCodeInterpreter `json:"code_interpreter,omitempty"`
+	FileSearch      *RunStepFileSearch      `json:"file_search,omitempty"`
+}
+
+type RunStepFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	// Output is nil until the tool outputs have been submitted.
+	Output *string `json:"output"`
+}
+
+type RunStepCodeInterpreter struct {
+	// Input is the code the model ran.
+	Input   string                         `json:"input"`
+	Outputs []RunStepCodeInterpreterOutput `json:"outputs"`
+}
+
+type RunStepCodeInterpreterOutputType string
+
+const (
+	RunStepCodeInterpreterOutputTypeLogs  RunStepCodeInterpreterOutputType = "logs"
+	RunStepCodeInterpreterOutputTypeImage RunStepCodeInterpreterOutputType = "image"
+)
+
+// RunStepCodeInterpreterOutput is either the text output of the code or an
+// image file it produced, depending on Type.
+type RunStepCodeInterpreterOutput struct {
+	Type  RunStepCodeInterpreterOutputType   `json:"type"`
+	Logs  string                             `json:"logs,omitempty"`
+	Image *RunStepCodeInterpreterOutputImage `json:"image,omitempty"`
+}
+
+type RunStepCodeInterpreterOutputImage struct {
+	FileID string `json:"file_id"`
+}
+
+type RunStepFileSearch struct {
+	RankingOptions *RunStepFileSearchRankingOptions `json:"ranking_options,omitempty"`
+	Results        []RunStepFileSearchResult        `json:"results,omitempty"`
+}
+
+type RunStepFileSearchRankingOptions struct {
+	Ranker         string  `json:"ranker"`
+	ScoreThreshold float64 `json:"score_threshold"`
+}
+
+type RunStepFileSearchResult struct {
+	FileID   string  `json:"file_id"`
+	FileName string  `json:"file_name"`
+	Score    float64 `json:"score"`
+	// Content is only returned when requested with RunStepIncludeFileSearchResultContent.
+	Content []RunStepFileSearchResultContent `json:"content,omitempty"`
+}
+
+type RunStepFileSearchResultContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// RunStepInclude names additional run step fields to return.
+type RunStepInclude string
+
+const (
+	// RunStepIncludeFileSearchResultContent returns the content of the chunks retrieved by file search.
+	RunStepIncludeFileSearchResultContent RunStepInclude = "step_details.tool_calls[*].file_search.results[*].content"
+)
+
+// WithRunStepInclude requests additional run step fields from CreateRun,
+// RetrieveRunStep and ListRunSteps.
+func WithRunStepInclude(include ...RunStepInclude) RequestOption {
+	return func(args *requestOptions) {
+		for _, field := range include {
+			WithQuery("include[]", string(field))(args)
+		}
+	}
+}
+
 // RunStepList is a list of steps.
 type RunStepList struct {
 	RunSteps []RunStep `json:"data"`
@@ -224,6 +325,7 @@ type RunStepList struct {
 	httpHeader
 }
 
+// Pagination selects a page of a cursor-paginated list. Unset fields use the API defaults.
 type Pagination struct {
 	Limit  *int
 	Order  *string
@@ -236,14 +338,16 @@ func (c *Client) CreateRun(
 	ctx context.Context,
 	threadID string,
 	request RunRequest,
+	opts ...RequestOption,
 ) (response Run, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs", threadID)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs", url.PathEscape(threadID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -257,13 +361,15 @@ func (c *Client) RetrieveRun(
 	ctx context.Context,
 	threadID string,
 	runID string,
+	opts ...RequestOption,
 ) (response Run, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", threadID, runID)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", url.PathEscape(threadID), url.PathEscape(runID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -278,14 +384,16 @@ func (c *Client) ModifyRun(
 	threadID string,
 	runID string,
 	request RunModifyRequest,
+	opts ...RequestOption,
 ) (response Run, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", threadID, runID)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s", url.PathEscape(threadID), url.PathEscape(runID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -299,32 +407,15 @@ func (c *Client) ListRuns(
 	ctx context.Context,
 	threadID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response RunList, err error) {
-	urlValues := url.Values{}
-	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
-	}
-	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
-	}
-	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
-	}
-	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
-	}
-
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
-
-	urlSuffix := fmt.Sprintf("/threads/%s/runs%s", threadID, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs%s", url.PathEscape(threadID), encodeQuery(pagination.values()))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -338,14 +429,15 @@ func (c *Client) SubmitToolOutputs(
 	ctx context.Context,
 	threadID string,
 	runID string,
-	request SubmitToolOutputsRequest) (response Run, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", threadID, runID)
+	request SubmitToolOutputsRequest, opts ...RequestOption) (response Run, err error) {
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", url.PathEscape(threadID), url.PathEscape(runID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -358,13 +450,14 @@ func (c *Client) SubmitToolOutputs(
 func (c *Client) CancelRun(
 	ctx context.Context,
 	threadID string,
-	runID string) (response Run, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/cancel", threadID, runID)
+	runID string, opts ...RequestOption) (response Run, err error) {
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/cancel", url.PathEscape(threadID), url.PathEscape(runID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -376,14 +469,15 @@ func (c *Client) CancelRun(
 // CreateThreadAndRun submits tool outputs.
 func (c *Client) CreateThreadAndRun(
 	ctx context.Context,
-	request CreateThreadAndRunRequest) (response Run, err error) {
+	request CreateThreadAndRunRequest, opts ...RequestOption) (response Run, err error) {
 	urlSuffix := "/threads/runs"
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -398,13 +492,16 @@ func (c *Client) RetrieveRunStep(
 	threadID string,
 	runID string,
 	stepID string,
+	opts ...RequestOption,
 ) (response RunStep, err error) {
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps/%s", threadID, runID, stepID)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps/%s",
+		url.PathEscape(threadID), url.PathEscape(runID), url.PathEscape(stepID))
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -419,6 +516,7 @@ func (c *Client) ListRunSteps(
 	threadID string,
 	runID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response RunStepList, err error) {
 	urlValues := url.Values{}
 	if pagination.Limit != nil {
@@ -439,12 +537,14 @@ func (c *Client) ListRunSteps(
 		encodedValues = "?" + urlValues.Encode()
 	}
 
-	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps%s", threadID, runID, encodedValues)
+	urlSuffix := fmt.Sprintf("/threads/%s/runs/%s/steps%s",
+		url.PathEscape(threadID), url.PathEscape(runID), encodedValues)
 	req, err := c.newRequest(
 		ctx,
 		http.MethodGet,
 		c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
 	if err != nil {
 		return
 	}
@@ -235,3 +235,84 @@ func TestRun(t *testing.T) {
 	)
 	checks.NoError(t, err, "ListRunSteps error")
 }
+
+func TestRunStepToolCallDetails(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	const stepJSON = `{
+		"id": "step_1",
+		"object": "thread.run.step",
+		"type": "tool_calls",
+		"status": "completed",
+		"step_details": {
+			"type": "tool_calls",
+			"tool_calls": [
+				{"id": "call_fn", "type": "function",
+					"function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}", "output": "sunny"}},
+				{"id": "call_ci", "type": "code_interpreter", "code_interpreter": {
+					"input": "print(1 + 1)",
+					"outputs": [{"type": "logs", "logs": "2\n"}, {"type": "image", "image": {"file_id": "file_png"}}]
+				}},
+				{"id": "call_fs", "type": "file_search", "file_search": {
+					"ranking_options": {"ranker": "default_2024_08_21", "score_threshold": 0.5},
+					"results": [{"file_id": "file_doc", "file_name": "doc.md", "score": 0.9,
+						"content": [{"type": "text", "text": "retrieved chunk"}]}]
+				}}
+			]
+		}
+	}`
+	var includes [][]string
+	recordInclude := func(r *http.Request) {
+		includes = append(includes, r.URL.Query()["include[]"])
+	}
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1/steps/step_1", func(w http.ResponseWriter, r *http.Request) {
+		recordInclude(r)
+		fmt.Fprintln(w, stepJSON)
+	})
+	server.RegisterHandler("/v1/threads/thread_1/runs/run_1/steps", func(w http.ResponseWriter, r *http.Request) {
+		recordInclude(r)
+		if r.URL.Query().Get("limit") != "1" {
+			t.Errorf("expected the pagination to be kept, got %q", r.URL.RawQuery)
+		}
+		fmt.Fprintf(w, `{"object":"list","data":[%s],"has_more":false}`, stepJSON)
+	})
+
+	ctx := context.Background()
+	step, err := client.RetrieveRunStep(ctx, "thread_1", "run_1", "step_1",
+		openai.WithRunStepInclude(openai.RunStepIncludeFileSearchResultContent))
+	checks.NoError(t, err, "RetrieveRunStep error")
+	limit := 1
+	steps, err := client.ListRunSteps(ctx, "thread_1", "run_1", openai.Pagination{Limit: &limit},
+		openai.WithRunStepInclude(openai.RunStepIncludeFileSearchResultContent))
+	checks.NoError(t, err, "ListRunSteps error")
+	if len(steps.RunSteps) != 1 {
+		t.Fatalf("expected one step, got %d", len(steps.RunSteps))
+	}
+
+	for _, include := range includes {
+		if len(include) != 1 || include[0] != string(openai.RunStepIncludeFileSearchResultContent) {
+			t.Fatalf("unexpected include[] %v", include)
+		}
+	}
+
+	calls := step.StepDetails.ToolCalls
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 tool calls, got %d", len(calls))
+	}
+	if fn := calls[0].Function; calls[0].Type != openai.RunStepToolCallTypeFunction ||
+		fn == nil || fn.Name != "get_weather" || fn.Output == nil || *fn.Output != "sunny" {
+		t.Fatalf("unexpected function call %+v", calls[0])
+	}
+	code := calls[1].CodeInterpreter
+	if code == nil || code.Input != "print(1 + 1)" || len(code.Outputs) != 2 ||
+		code.Outputs[0].Logs != "2\n" || code.Outputs[1].Image == nil || code.Outputs[1].Image.FileID != "file_png" {
+		t.Fatalf("unexpected code interpreter call %+v", code)
+	}
+	search := calls[2].FileSearch
+	if search == nil || search.RankingOptions == nil || search.RankingOptions.ScoreThreshold != 0.5 ||
+		len(search.Results) != 1 || search.Results[0].FileName != "doc.md" ||
+		len(search.Results[0].Content) != 1 || search.Results[0].Content[0].Text != "retrieved chunk" {
+		t.Fatalf("unexpected file search call %+v", search)
+	}
+}
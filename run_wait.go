@@ -0,0 +1,247 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRunPollInterval    = 500 * time.Millisecond
+	defaultRunPollMaxInterval = 5 * time.Second
+	defaultRunPollMultiplier  = 1.5
+	// runCancelTimeout bounds the CancelRun call made after the caller's context is done.
+	runCancelTimeout = 10 * time.Second
+)
+
+var (
+	ErrRunFailed             = errors.New("run failed")
+	ErrRunExpired            = errors.New("run expired")
+	ErrRunIncomplete         = errors.New("run incomplete")
+	ErrRunCancelled          = errors.New("run cancelled")
+	ErrRunToolHandlerMissing = errors.New("run requires tool outputs but no tool handler was given")
+	ErrRunUnexpectedStatus   = errors.New("unexpected run status")
+)
+
+// RunToolHandler returns the output of a tool call the run is waiting on.
+type RunToolHandler func(ctx context.Context, call ToolCall) (output string, err error)
+
+// RunPollOptions configures how often RunAndWait retrieves the run. Zero
+// values use the defaults.
+type RunPollOptions struct {
+	// Interval is the delay before the first poll. Defaults to 500ms.
+	Interval time.Duration
+	// MaxInterval caps the delay between polls. Defaults to 5s.
+	MaxInterval time.Duration
+	// Multiplier grows the delay after every poll. Defaults to 1.5.
+	Multiplier float64
+}
+
+func (o RunPollOptions) withDefaults() RunPollOptions {
+	if o.Interval <= 0 {
+		o.Interval = defaultRunPollInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaultRunPollMaxInterval
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = o.Interval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = defaultRunPollMultiplier
+	}
+	return o
+}
+
+func (o RunPollOptions) next(interval time.Duration) time.Duration {
+	interval = time.Duration(float64(interval) * o.Multiplier)
+	if interval > o.MaxInterval {
+		return o.MaxInterval
+	}
+	return interval
+}
+
+// RunResult is the outcome of a completed run.
+type RunResult struct {
+	Run Run
+	// Messages are the messages created by the run, oldest first.
+	Messages []Message
+}
+
+// RunStatusError is returned by RunAndWait when a run ends as failed,
+// expired, incomplete or cancelled. It matches ErrRunFailed, ErrRunExpired,
+// ErrRunIncomplete or ErrRunCancelled with errors.Is.
+type RunStatusError struct {
+	Run Run
+}
+
+func (e *RunStatusError) Error() string {
+	msg := fmt.Sprintf("run %s %s", e.Run.ID, e.Run.Status)
+	if e.Run.LastError != nil {
+		msg += fmt.Sprintf(": %s: %s", e.Run.LastError.Code, e.Run.LastError.Message)
+	}
+	if e.Run.IncompleteDetails != nil {
+		msg += ": " + e.Run.IncompleteDetails.Reason
+	}
+	return msg
+}
+
+func (e *RunStatusError) Unwrap() error {
+	switch e.Run.Status {
+	case RunStatusFailed:
+		return ErrRunFailed
+	case RunStatusExpired:
+		return ErrRunExpired
+	case RunStatusIncomplete:
+		return ErrRunIncomplete
+	case RunStatusCancelled:
+		return ErrRunCancelled
+	default:
+		return nil
+	}
+}
+
+// LastError returns the error the API reported for a failed run, if any.
+func (e *RunStatusError) LastError() *RunLastError {
+	return e.Run.LastError
+}
+
+// runAPI is the part of Client and Router RunAndWait drives.
+type runAPI interface {
+	CreateRun(ctx context.Context, threadID string, request RunRequest, opts ...RequestOption) (Run, error)
+	RetrieveRun(ctx context.Context, threadID, runID string, opts ...RequestOption) (Run, error)
+	SubmitToolOutputs(
+		ctx context.Context,
+		threadID, runID string,
+		request SubmitToolOutputsRequest,
+		opts ...RequestOption,
+	) (Run, error)
+	CancelRun(ctx context.Context, threadID, runID string, opts ...RequestOption) (Run, error)
+	ListMessagePager(
+		ctx context.Context,
+		threadID string,
+		listOptions ListMessageOptions,
+		options PagerOptions,
+		opts ...RequestOption,
+	) *Pager[Message]
+}
+
+// RunAndWait creates a run and polls it until it reaches a terminal status.
+// While the run requires action, handler is called for every tool call and
+// the outputs are submitted. If ctx is done or handler fails, the run is
+// cancelled. Runs ending as failed, expired, incomplete or cancelled return a
+// *RunStatusError along with the final Run.
+func (c *Client) RunAndWait(
+	ctx context.Context,
+	threadID string,
+	request RunRequest,
+	handler RunToolHandler,
+	poll RunPollOptions,
+	opts ...RequestOption,
+) (RunResult, error) {
+	return runAndWait(ctx, c, threadID, request, handler, poll, opts)
+}
+
+func runAndWait(
+	ctx context.Context,
+	api runAPI,
+	threadID string,
+	request RunRequest,
+	handler RunToolHandler,
+	poll RunPollOptions,
+	opts []RequestOption,
+) (RunResult, error) {
+	run, err := api.CreateRun(ctx, threadID, request, opts...)
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	poll = poll.withDefaults()
+	interval := poll.Interval
+	for {
+		switch run.Status {
+		case RunStatusCompleted:
+			return runResult(ctx, api, threadID, run, opts)
+		case RunStatusFailed, RunStatusExpired, RunStatusIncomplete, RunStatusCancelled:
+			return RunResult{Run: run}, &RunStatusError{Run: run}
+		case RunStatusRequiresAction:
+			var submitted Run
+			submitted, err = submitRunToolOutputs(ctx, api, threadID, run, handler, opts)
+			if err != nil {
+				return RunResult{Run: cancelRun(api, threadID, run, opts)}, err
+			}
+			run, interval = submitted, poll.Interval
+			continue
+		case RunStatusQueued, RunStatusInProgress, RunStatusCancelling:
+		default:
+			return RunResult{Run: run}, fmt.Errorf("%w %q", ErrRunUnexpectedStatus, run.Status)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return RunResult{Run: cancelRun(api, threadID, run, opts)}, ctx.Err()
+		case <-timer.C:
+		}
+
+		var retrieved Run
+		retrieved, err = api.RetrieveRun(ctx, threadID, run.ID, opts...)
+		if err != nil {
+			if ctx.Err() != nil {
+				run = cancelRun(api, threadID, run, opts)
+			}
+			return RunResult{Run: run}, err
+		}
+		run, interval = retrieved, poll.next(interval)
+	}
+}
+
+func submitRunToolOutputs(
+	ctx context.Context,
+	api runAPI,
+	threadID string,
+	run Run,
+	handler RunToolHandler,
+	opts []RequestOption,
+) (Run, error) {
+	if run.RequiredAction == nil || run.RequiredAction.SubmitToolOutputs == nil {
+		return run, fmt.Errorf("%w: %s without tool calls", ErrRunUnexpectedStatus, run.Status)
+	}
+	if handler == nil {
+		return run, ErrRunToolHandlerMissing
+	}
+
+	calls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+	outputs := make([]ToolOutput, 0, len(calls))
+	for _, call := range calls {
+		output, err := handler(ctx, call)
+		if err != nil {
+			return run, fmt.Errorf("tool call %s (%s): %w", call.ID, call.Function.Name, err)
+		}
+		outputs = append(outputs, ToolOutput{ToolCallID: call.ID, Output: output})
+	}
+	return api.SubmitToolOutputs(ctx, threadID, run.ID, SubmitToolOutputsRequest{ToolOutputs: outputs}, opts...)
+}
+
+// cancelRun cancels run on a fresh context, so it also works after the
+// caller's context is done, and returns the cancelling run when it succeeds.
+func cancelRun(api runAPI, threadID string, run Run, opts []RequestOption) Run {
+	ctx, cancel := context.WithTimeout(context.Background(), runCancelTimeout)
+	defer cancel()
+	cancelled, err := api.CancelRun(ctx, threadID, run.ID, opts...)
+	if err != nil {
+		return run
+	}
+	return cancelled
+}
+
+func runResult(ctx context.Context, api runAPI, threadID string, run Run, opts []RequestOption) (RunResult, error) {
+	order := "asc"
+	messages, err := api.ListMessagePager(ctx, threadID, ListMessageOptions{
+		Pagination: Pagination{Order: &order},
+		RunID:      &run.ID,
+	}, PagerOptions{}, opts...).Collect()
+	return RunResult{Run: run, Messages: messages}, err
+}
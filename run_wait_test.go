@@ -0,0 +1,207 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const (
+	runWaitThreadID = "thread_wait"
+	runWaitRunID    = "run_wait"
+)
+
+var fastRunPolling = openai.RunPollOptions{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond}
+
+// runWaitServer serves a run moving through statuses, one per RetrieveRun.
+type runWaitServer struct {
+	mu        sync.Mutex
+	statuses  []openai.Run
+	submitted []openai.ToolOutput
+	cancelled bool
+}
+
+func (s *runWaitServer) register(server *test.ServerTest) {
+	runsPath := "/v1/threads/" + runWaitThreadID + "/runs"
+	runPath := runsPath + "/" + runWaitRunID
+	writeRun := func(w http.ResponseWriter, run openai.Run) {
+		run.ID = runWaitRunID
+		run.ThreadID = runWaitThreadID
+		resBytes, _ := json.Marshal(run)
+		fmt.Fprintln(w, string(resBytes))
+	}
+	server.RegisterHandler(runsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeRun(w, openai.Run{Status: openai.RunStatusQueued})
+	})
+	server.RegisterHandler(runPath, func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		run := s.statuses[0]
+		if len(s.statuses) > 1 {
+			s.statuses = s.statuses[1:]
+		}
+		writeRun(w, run)
+	})
+	server.RegisterHandler(runPath+"/submit_tool_outputs", func(w http.ResponseWriter, r *http.Request) {
+		var request openai.SubmitToolOutputsRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		s.mu.Lock()
+		s.submitted = append(s.submitted, request.ToolOutputs...)
+		s.mu.Unlock()
+		writeRun(w, openai.Run{Status: openai.RunStatusQueued})
+	})
+	server.RegisterHandler(runPath+"/cancel", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		s.cancelled = true
+		s.mu.Unlock()
+		writeRun(w, openai.Run{Status: openai.RunStatusCancelling})
+	})
+	server.RegisterHandler("/v1/threads/"+runWaitThreadID+"/messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("run_id") != runWaitRunID || r.URL.Query().Get("order") != "asc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, `{"object":"list","data":[{"id":"msg_1","run_id":"run_wait"}],"has_more":false}`)
+	})
+}
+
+func requiresWeather() openai.Run {
+	return openai.Run{
+		Status: openai.RunStatusRequiresAction,
+		RequiredAction: &openai.RunRequiredAction{
+			Type: openai.RequiredActionTypeSubmitToolOutputs,
+			SubmitToolOutputs: &openai.SubmitToolOutputs{ToolCalls: []openai.ToolCall{{
+				ID:       "call_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}}},
+		},
+	}
+}
+
+func TestRunAndWaitHandlesToolCalls(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	runs := &runWaitServer{statuses: []openai.Run{
+		{Status: openai.RunStatusInProgress},
+		requiresWeather(),
+		{Status: openai.RunStatusCompleted},
+	}}
+	runs.register(server)
+
+	var handled []string
+	result, err := client.RunAndWait(
+		context.Background(),
+		runWaitThreadID,
+		openai.RunRequest{AssistantID: "asst_1"},
+		func(_ context.Context, call openai.ToolCall) (string, error) {
+			handled = append(handled, call.Function.Name)
+			return "sunny", nil
+		},
+		fastRunPolling,
+	)
+	checks.NoError(t, err, "RunAndWait error")
+
+	if result.Run.Status != openai.RunStatusCompleted {
+		t.Fatalf("expected a completed run, got %s", result.Run.Status)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].ID != "msg_1" {
+		t.Fatalf("unexpected messages %+v", result.Messages)
+	}
+	if len(handled) != 1 || handled[0] != "get_weather" {
+		t.Fatalf("unexpected handled tool calls %v", handled)
+	}
+	if len(runs.submitted) != 1 || runs.submitted[0].ToolCallID != "call_1" || runs.submitted[0].Output != "sunny" {
+		t.Fatalf("unexpected submitted outputs %+v", runs.submitted)
+	}
+}
+
+func TestRunAndWaitStatusErrors(t *testing.T) {
+	tests := []struct {
+		run  openai.Run
+		want error
+	}{
+		{openai.Run{
+			Status:    openai.RunStatusFailed,
+			LastError: &openai.RunLastError{Code: openai.RunErrorRateLimitExceeded, Message: "slow down"},
+		}, openai.ErrRunFailed},
+		{openai.Run{Status: openai.RunStatusExpired}, openai.ErrRunExpired},
+		{openai.Run{
+			Status:            openai.RunStatusIncomplete,
+			IncompleteDetails: &openai.RunIncompleteDetails{Reason: "max_completion_tokens"},
+		}, openai.ErrRunIncomplete},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.run.Status), func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			(&runWaitServer{statuses: []openai.Run{tc.run}}).register(server)
+
+			result, err := client.RunAndWait(context.Background(), runWaitThreadID, openai.RunRequest{}, nil, fastRunPolling)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			var statusErr *openai.RunStatusError
+			if !errors.As(err, &statusErr) || statusErr.Run.Status != tc.run.Status {
+				t.Fatalf("expected a RunStatusError, got %v", err)
+			}
+			if tc.run.LastError != nil && statusErr.LastError().Code != openai.RunErrorRateLimitExceeded {
+				t.Fatalf("expected the last error, got %+v", statusErr.LastError())
+			}
+			if result.Run.Status != tc.run.Status {
+				t.Fatalf("expected the final run, got %+v", result.Run)
+			}
+		})
+	}
+}
+
+func TestRunAndWaitCancelsOnContextDone(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	runs := &runWaitServer{statuses: []openai.Run{{Status: openai.RunStatusInProgress}}}
+	runs.register(server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result, err := client.RunAndWait(ctx, runWaitThreadID, openai.RunRequest{}, nil, fastRunPolling)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !runs.cancelled || result.Run.Status != openai.RunStatusCancelling {
+		t.Fatalf("expected the run to be cancelled, got %+v", result.Run)
+	}
+}
+
+func TestRunAndWaitCancelsOnToolHandlerError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	runs := &runWaitServer{statuses: []openai.Run{requiresWeather()}}
+	runs.register(server)
+
+	_, err := client.RunAndWait(context.Background(), runWaitThreadID, openai.RunRequest{}, nil, fastRunPolling)
+	if !errors.Is(err, openai.ErrRunToolHandlerMissing) {
+		t.Fatalf("expected ErrRunToolHandlerMissing, got %v", err)
+	}
+	if !runs.cancelled {
+		t.Fatal("expected the run to be cancelled")
+	}
+
+	errTool := errors.New("tool broke")
+	runs.statuses, runs.cancelled = []openai.Run{requiresWeather()}, false
+	failingHandler := func(context.Context, openai.ToolCall) (string, error) {
+		return "", errTool
+	}
+	_, err = client.RunAndWait(context.Background(), runWaitThreadID, openai.RunRequest{}, failingHandler, fastRunPolling)
+	if !errors.Is(err, errTool) || !runs.cancelled {
+		t.Fatalf("expected the handler error and a cancelled run, got %v, cancelled %v", err, runs.cancelled)
+	}
+}
@@ -49,13 +49,18 @@ type CreateSpeechRequest struct {
 	Speed          float64              `json:"speed,omitempty"`           // Optional, default to 1.0
 }
 
-func (c *Client) CreateSpeech(ctx context.Context, request CreateSpeechRequest) (response RawResponse, err error) {
+func (c *Client) CreateSpeech(
+	ctx context.Context,
+	request CreateSpeechRequest,
+	opts ...RequestOption,
+) (response RawResponse, err error) {
 	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL("/audio/speech", withModel(string(request.Model))),
 		withBody(request),
 		withContentType("application/json"),
+		withOptions(opts...),
 	)
 	if err != nil {
 		return
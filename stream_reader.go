@@ -28,6 +28,8 @@ type streamReader[T streamable] struct {
 	response       *http.Response
 	errAccumulator utils.ErrorAccumulator
 	unmarshaler    utils.Unmarshaler
+	// watchdog enforces the StreamTimeouts of the stream, if any.
+	watchdog *streamWatchdog
 
 	httpHeader
 }
@@ -49,8 +51,25 @@ func (stream *streamReader[T]) RecvRaw() ([]byte, error) {
 	if stream.isFinished {
 		return nil, io.EOF
 	}
+	if stream.watchdog == nil {
+		return stream.processLines()
+	}
 
-	return stream.processLines()
+	if err := stream.watchdog.timeoutErr(); err != nil {
+		return nil, err
+	}
+	stream.watchdog.reading()
+	line, err := stream.processLines()
+	if err == nil {
+		stream.watchdog.received()
+		return line, nil
+	}
+	// A timeout closes the body, failing the read with a less telling error.
+	if timeoutErr := stream.watchdog.timeoutErr(); timeoutErr != nil {
+		return nil, timeoutErr
+	}
+	stream.watchdog.stop()
+	return nil, err
 }
 
 //nolint:gocognit
@@ -115,5 +134,8 @@ func (stream *streamReader[T]) unmarshalError() (errResp *ErrorResponse) {
 }
 
 func (stream *streamReader[T]) Close() error {
+	if stream.watchdog != nil {
+		defer stream.watchdog.close()
+	}
 	return stream.response.Body.Close()
 }
@@ -0,0 +1,212 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrStreamTimeout is wrapped by the StreamTimeoutError of a stream that timed out.
+var ErrStreamTimeout = errors.New("stream timed out")
+
+// StreamTimeouts bound how long a stream may stall. Zero durations don't
+// bound anything. Unlike the request context, they only end the stream:
+// when one expires, the response body is closed and Recv returns a
+// *StreamTimeoutError.
+type StreamTimeouts struct {
+	// FirstToken bounds the wait for the first chunk, from sending the request.
+	FirstToken time.Duration
+	// Idle bounds every wait of Recv for a chunk after the first one, and for
+	// the first chunk when FirstToken is zero. Time spent between calls of
+	// Recv, processing chunks, doesn't count. Keep-alive comments don't
+	// count as chunks.
+	Idle time.Duration
+	// Total bounds the whole stream, from sending the request to its end.
+	Total time.Duration
+}
+
+func (t StreamTimeouts) enabled() bool {
+	return t.FirstToken > 0 || t.Idle > 0 || t.Total > 0
+}
+
+// StreamTimeoutKind is which of the StreamTimeouts expired.
+type StreamTimeoutKind string
+
+const (
+	StreamTimeoutFirstToken StreamTimeoutKind = "first_token"
+	StreamTimeoutIdle       StreamTimeoutKind = "idle"
+	StreamTimeoutTotal      StreamTimeoutKind = "total"
+)
+
+// StreamTimeoutError is returned by streams which one of their
+// StreamTimeouts ended.
+type StreamTimeoutError struct {
+	Kind StreamTimeoutKind
+	// After is the timeout which expired.
+	After time.Duration
+}
+
+func (e *StreamTimeoutError) Error() string {
+	return fmt.Sprintf("%s: no %s within %s", ErrStreamTimeout, e.waitedFor(), e.After)
+}
+
+func (e *StreamTimeoutError) waitedFor() string {
+	switch e.Kind {
+	case StreamTimeoutFirstToken:
+		return "first chunk"
+	case StreamTimeoutIdle:
+		return "chunk"
+	default:
+		return "end of stream"
+	}
+}
+
+func (e *StreamTimeoutError) Unwrap() error {
+	return ErrStreamTimeout
+}
+
+// Timeout reports that the error is a timeout, like net.Error does.
+func (e *StreamTimeoutError) Timeout() bool {
+	return true
+}
+
+// WithStreamTimeouts bounds the stream of the call by timeouts, overriding
+// ClientConfig.StreamTimeouts. It has no effect on calls which don't stream.
+func WithStreamTimeouts(timeouts StreamTimeouts) RequestOption {
+	return func(args *requestOptions) {
+		args.streamTimeouts = &timeouts
+	}
+}
+
+type streamTimeoutsKey struct{}
+
+func withStreamTimeouts(ctx context.Context, timeouts StreamTimeouts) context.Context {
+	return context.WithValue(ctx, streamTimeoutsKey{}, timeouts)
+}
+
+// streamTimeouts returns the timeouts of a stream requested with ctx.
+func (c *Client) streamTimeouts(ctx context.Context) StreamTimeouts {
+	if timeouts, ok := ctx.Value(streamTimeoutsKey{}).(StreamTimeouts); ok {
+		return timeouts
+	}
+	return c.config.StreamTimeouts
+}
+
+// streamWatchdog enforces StreamTimeouts on a stream. The request of the
+// stream gets a context of its own, so it can be aborted without cancelling
+// the caller's context.
+type streamWatchdog struct {
+	timeouts StreamTimeouts
+	cancel   context.CancelFunc
+
+	mu   sync.Mutex
+	body io.Closer
+	// wait bounds the wait for the next chunk while it is read, total the
+	// whole stream.
+	wait    *time.Timer
+	total   *time.Timer
+	err     *StreamTimeoutError
+	stopped bool
+}
+
+// newStreamWatchdog starts the timeouts of a stream about to be requested
+// with ctx, returning the context to request it with.
+func newStreamWatchdog(ctx context.Context, timeouts StreamTimeouts) (*streamWatchdog, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &streamWatchdog{timeouts: timeouts, cancel: cancel}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if timeouts.Total > 0 {
+		w.total = time.AfterFunc(timeouts.Total, func() { w.expire(StreamTimeoutTotal, timeouts.Total) })
+	}
+	if timeouts.FirstToken > 0 {
+		w.wait = time.AfterFunc(timeouts.FirstToken, func() { w.expire(StreamTimeoutFirstToken, timeouts.FirstToken) })
+	} else {
+		w.startIdle()
+	}
+	return w, ctx
+}
+
+// startIdle starts the idle timeout. The caller must hold w.mu.
+func (w *streamWatchdog) startIdle() {
+	if w.timeouts.Idle > 0 {
+		idle := w.timeouts.Idle
+		w.wait = time.AfterFunc(idle, func() { w.expire(StreamTimeoutIdle, idle) })
+	}
+}
+
+// expire ends the stream with a timeout error.
+func (w *streamWatchdog) expire(kind StreamTimeoutKind, after time.Duration) {
+	w.mu.Lock()
+	if w.stopped || w.err != nil {
+		w.mu.Unlock()
+		return
+	}
+	w.err = &StreamTimeoutError{Kind: kind, After: after}
+	body := w.body
+	w.mu.Unlock()
+
+	w.cancel()
+	if body != nil {
+		body.Close()
+	}
+}
+
+// setBody makes expiring timeouts close body, the body of the stream.
+func (w *streamWatchdog) setBody(body io.Closer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.body = body
+}
+
+// reading starts the idle timeout when the next chunk is read, unless the
+// timeout of the first chunk is still running.
+func (w *streamWatchdog) reading() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.stopped && w.err == nil && w.wait == nil {
+		w.startIdle()
+	}
+}
+
+// received stops waiting once a chunk is returned, so the time the caller
+// takes to process it doesn't count as idle.
+func (w *streamWatchdog) received() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.wait != nil {
+		w.wait.Stop()
+		w.wait = nil
+	}
+}
+
+// timeoutErr returns the error of the expired timeout, or nil while none has.
+func (w *streamWatchdog) timeoutErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		return nil
+	}
+	return w.err
+}
+
+// stop stops the timeouts once the stream ended.
+func (w *streamWatchdog) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopped = true
+	for _, timer := range []*time.Timer{w.wait, w.total} {
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// close stops the timeouts and releases the context of the request.
+func (w *streamWatchdog) close() {
+	w.stop()
+	w.cancel()
+}
@@ -0,0 +1,188 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+	"github.com/sashabaranov/go-openai/openaitest"
+)
+
+// streamWithTimeouts streams fixture with config and opts and returns the
+// content received, how long the stream took and the error ending it.
+func streamWithTimeouts(
+	t *testing.T,
+	config openai.ClientConfig,
+	fixture *openaitest.StreamFixture,
+	opts ...openai.RequestOption,
+) (string, time.Duration, error) {
+	t.Helper()
+	server := openaitest.NewServer()
+	t.Cleanup(server.Close)
+	server.ScriptChat(openaitest.ChatReply{Stream: fixture})
+	base := server.ClientConfig()
+	config.BaseURL, config.HTTPClient = base.BaseURL, base.HTTPClient
+	client := openai.NewClientWithConfig(config)
+
+	ctx := context.Background()
+	start := time.Now()
+	stream, err := client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		Stream:   true,
+	}, opts...)
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	var content string
+	for {
+		chunk, recvErr := stream.Recv()
+		if recvErr != nil {
+			if ctx.Err() != nil {
+				t.Fatal("expected the context of the caller not to be cancelled")
+			}
+			if errors.Is(recvErr, io.EOF) {
+				recvErr = nil
+			}
+			return content, time.Since(start), recvErr
+		}
+		content += chunk.Choices[0].Delta.Content
+	}
+}
+
+func checkStreamTimeout(t *testing.T, err error, kind openai.StreamTimeoutKind, elapsed time.Duration) {
+	t.Helper()
+	timeoutErr := &openai.StreamTimeoutError{}
+	if !errors.As(err, &timeoutErr) || timeoutErr.Kind != kind || !errors.Is(err, openai.ErrStreamTimeout) {
+		t.Fatalf("expected a %s timeout, got %v", kind, err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("expected the timeout to end the stream promptly, took %v", elapsed)
+	}
+}
+
+func TestStreamTimeouts(t *testing.T) {
+	config := openai.DefaultConfig(openaitest.Token)
+
+	// Keep-alive comments don't count as chunks.
+	content, elapsed, err := streamWithTimeouts(t, config, openaitest.NewStreamFixture().
+		Content("a").
+		KeepAlive().Delay(40*time.Millisecond).
+		KeepAlive().Delay(40*time.Millisecond).
+		KeepAlive().Delay(5*time.Second).
+		Content("b").Done(),
+		openai.WithStreamTimeouts(openai.StreamTimeouts{Idle: 60 * time.Millisecond}))
+	checkStreamTimeout(t, err, openai.StreamTimeoutIdle, elapsed)
+	if content != "a" {
+		t.Fatalf("expected the chunk before the stall, got %q", content)
+	}
+
+	_, elapsed, err = streamWithTimeouts(t, config,
+		openaitest.NewStreamFixture().Delay(5*time.Second).Content("a").Done(),
+		openai.WithStreamTimeouts(openai.StreamTimeouts{FirstToken: 50 * time.Millisecond, Idle: 10 * time.Second}))
+	checkStreamTimeout(t, err, openai.StreamTimeoutFirstToken, elapsed)
+
+	content, elapsed, err = streamWithTimeouts(t, config,
+		openaitest.NewStreamFixture().Interval(20*time.Millisecond).
+			Content("a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p").Done(),
+		openai.WithStreamTimeouts(openai.StreamTimeouts{Idle: 200 * time.Millisecond, Total: 100 * time.Millisecond}))
+	checkStreamTimeout(t, err, openai.StreamTimeoutTotal, elapsed)
+	if content == "" || len(content) == 16 {
+		t.Fatalf("expected part of the stream before the timeout, got %q", content)
+	}
+
+	// Streams within their timeouts are not affected.
+	content, _, err = streamWithTimeouts(t, config,
+		openaitest.NewStreamFixture().Interval(10*time.Millisecond).Content("a", "b", "c").Done(),
+		openai.WithStreamTimeouts(openai.StreamTimeouts{
+			FirstToken: time.Second,
+			Idle:       time.Second,
+			Total:      time.Second,
+		}))
+	checks.NoError(t, err, "stream error")
+	if content != "abc" {
+		t.Fatalf("expected the whole stream, got %q", content)
+	}
+}
+
+func TestStreamTimeoutsSlowConsumer(t *testing.T) {
+	server := openaitest.NewServer()
+	defer server.Close()
+	server.ScriptChat(openaitest.ChatReply{Stream: openaitest.NewStreamFixture().Content("a", "b", "c").Done()})
+	client := openai.NewClientWithConfig(server.ClientConfig())
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+		Stream:   true,
+	}, openai.WithStreamTimeouts(openai.StreamTimeouts{Idle: 50 * time.Millisecond}))
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+
+	// Processing a chunk for longer than Idle isn't idling: the server sent
+	// the next chunk long before it is read.
+	var content string
+	for {
+		chunk, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "Recv error")
+		content += chunk.Choices[0].Delta.Content
+		time.Sleep(100 * time.Millisecond)
+	}
+	if content != "abc" {
+		t.Fatalf("expected the whole stream, got %q", content)
+	}
+}
+
+func TestStreamTimeoutsConfig(t *testing.T) {
+	config := openai.DefaultConfig(openaitest.Token)
+	config.StreamTimeouts = openai.StreamTimeouts{Idle: 50 * time.Millisecond}
+	fixture := openaitest.NewStreamFixture().Content("a").Delay(150 * time.Millisecond).Content("b").Done()
+
+	_, elapsed, err := streamWithTimeouts(t, config, fixture)
+	checkStreamTimeout(t, err, openai.StreamTimeoutIdle, elapsed)
+
+	// A call can lift the timeouts of the client.
+	content, _, err := streamWithTimeouts(t, config, fixture, openai.WithStreamTimeouts(openai.StreamTimeouts{}))
+	checks.NoError(t, err, "stream error")
+	if content != "ab" {
+		t.Fatalf("expected the whole stream, got %q", content)
+	}
+}
+
+func TestStreamTimeoutClosesConnection(t *testing.T) {
+	served := make(chan struct{})
+	fixture := openaitest.NewStreamFixture().Content("a").Delay(10 * time.Second).Done()
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		defer close(served)
+		fixture.ServeHTTP(w, r)
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+	}, openai.WithStreamTimeouts(openai.StreamTimeouts{Idle: 50 * time.Millisecond}))
+	checks.NoErrorF(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+	_, err = stream.Recv()
+	checkStreamTimeout(t, err, openai.StreamTimeoutIdle, 0)
+	// The timeout keeps being returned.
+	_, err = stream.Recv()
+	checkStreamTimeout(t, err, openai.StreamTimeoutIdle, 0)
+
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatal("expected the timeout to close the connection")
+	}
+}
@@ -0,0 +1,353 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AzureCognitiveServicesScope is the OAuth scope for Azure OpenAI.
+	AzureCognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+
+	azureAuthorityHost          = "https://login.microsoftonline.com"
+	azureClientAssertionType    = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	defaultTokenRefreshMargin   = 5 * time.Minute
+	defaultTokenLifetimeSeconds = 3600
+	tokenRefreshTimeout         = time.Minute
+	minTokenRefreshBackoff      = time.Second
+	maxTokenRefreshBackoff      = time.Minute
+)
+
+var (
+	ErrTokenProviderMissingTenant    = errors.New("token provider needs a tenant ID or a token URL")
+	ErrTokenProviderMissingTokenFile = errors.New("workload identity token provider needs a federated token file")
+	ErrTokenResponseMissingToken     = errors.New("token endpoint response has no access_token")
+)
+
+// TokenProvider supplies the credential sent with every request. When
+// ClientConfig.TokenProvider is set it is consulted per request instead of
+// the token the config was created with, so expiring tokens such as
+// Microsoft Entra ID access tokens are refreshed without rebuilding the Client.
+//
+// The token is sent the same way a static token would be for the APIType:
+// as the api-key header for APITypeAzure, x-api-key for APITypeAnthropic and
+// as a Bearer token otherwise.
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenProviderFunc adapts a function to the TokenProvider interface.
+type TokenProviderFunc func(ctx context.Context) (string, error)
+
+func (f TokenProviderFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// NewStaticTokenProvider returns a TokenProvider which always returns token.
+func NewStaticTokenProvider(token string) TokenProvider {
+	return TokenProviderFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// TokenError is returned when a token endpoint rejects a token request.
+type TokenError struct {
+	HTTPStatusCode   int
+	Code             string `json:"error"`
+	Description      string `json:"error_description"`
+	CorrelationID    string `json:"correlation_id,omitempty"`
+	ErrorCodes       []int  `json:"error_codes,omitempty"`
+	RawResponseBytes []byte `json:"-"`
+}
+
+func (e *TokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("token request failed with status %d: %s: %s", e.HTTPStatusCode, e.Code, e.Description)
+	}
+	return fmt.Sprintf("token request failed with status %d: %s", e.HTTPStatusCode, string(e.RawResponseBytes))
+}
+
+// ClientCredentialsConfig configures the OAuth 2.0 client credentials flow.
+type ClientCredentialsConfig struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// TokenURL overrides the Entra ID token endpoint derived from TenantID.
+	TokenURL string
+	// Scope defaults to AzureCognitiveServicesScope.
+	Scope string
+	// RefreshMargin is how long before expiry a token is refreshed. Defaults
+	// to 5 minutes, and is at most half the lifetime of a token.
+	RefreshMargin time.Duration
+	HTTPClient    HTTPDoer
+}
+
+// NewClientCredentialsTokenProvider returns a caching TokenProvider which
+// obtains tokens with the client credentials grant.
+func NewClientCredentialsTokenProvider(config ClientCredentialsConfig) (TokenProvider, error) {
+	endpoint, err := newOAuthTokenEndpoint(config.TokenURL, config.TenantID, config.Scope, config.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	fetch := func(ctx context.Context) (oauthToken, error) {
+		return endpoint.request(ctx, url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {config.ClientID},
+			"client_secret": {config.ClientSecret},
+		})
+	}
+	return newCachingTokenProvider(fetch, config.RefreshMargin), nil
+}
+
+// WorkloadIdentityConfig configures token exchange with a federated token file,
+// as mounted by Azure Workload Identity in Kubernetes. Empty fields are read
+// from the AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_FEDERATED_TOKEN_FILE and
+// AZURE_AUTHORITY_HOST environment variables.
+type WorkloadIdentityConfig struct {
+	TenantID      string
+	ClientID      string
+	TokenFilePath string
+	// TokenURL overrides the Entra ID token endpoint derived from TenantID.
+	TokenURL string
+	// Scope defaults to AzureCognitiveServicesScope.
+	Scope string
+	// RefreshMargin is how long before expiry a token is refreshed. Defaults
+	// to 5 minutes, and is at most half the lifetime of a token.
+	RefreshMargin time.Duration
+	HTTPClient    HTTPDoer
+}
+
+// NewWorkloadIdentityTokenProvider returns a caching TokenProvider which
+// exchanges the federated token file for an access token. The file is read
+// again on every refresh because it is rotated by the platform.
+func NewWorkloadIdentityTokenProvider(config WorkloadIdentityConfig) (TokenProvider, error) {
+	if config.TenantID == "" {
+		config.TenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+	if config.ClientID == "" {
+		config.ClientID = os.Getenv("AZURE_CLIENT_ID")
+	}
+	if config.TokenFilePath == "" {
+		config.TokenFilePath = os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	}
+	if config.TokenURL == "" && config.TenantID != "" {
+		if host := os.Getenv("AZURE_AUTHORITY_HOST"); host != "" {
+			config.TokenURL = azureTokenURL(host, config.TenantID)
+		}
+	}
+	if config.TokenFilePath == "" {
+		return nil, ErrTokenProviderMissingTokenFile
+	}
+
+	endpoint, err := newOAuthTokenEndpoint(config.TokenURL, config.TenantID, config.Scope, config.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	fetch := func(ctx context.Context) (oauthToken, error) {
+		assertion, readErr := os.ReadFile(config.TokenFilePath)
+		if readErr != nil {
+			return oauthToken{}, fmt.Errorf("reading federated token file: %w", readErr)
+		}
+		return endpoint.request(ctx, url.Values{
+			"grant_type":            {"client_credentials"},
+			"client_id":             {config.ClientID},
+			"client_assertion_type": {azureClientAssertionType},
+			"client_assertion":      {strings.TrimSpace(string(assertion))},
+		})
+	}
+	return newCachingTokenProvider(fetch, config.RefreshMargin), nil
+}
+
+func azureTokenURL(authorityHost, tenantID string) string {
+	return fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimRight(authorityHost, "/"), tenantID)
+}
+
+type oauthToken struct {
+	accessToken string
+	expiresIn   time.Duration
+}
+
+type oauthTokenEndpoint struct {
+	tokenURL   string
+	scope      string
+	httpClient HTTPDoer
+}
+
+func newOAuthTokenEndpoint(tokenURL, tenantID, scope string, httpClient HTTPDoer) (*oauthTokenEndpoint, error) {
+	if tokenURL == "" {
+		if tenantID == "" {
+			return nil, ErrTokenProviderMissingTenant
+		}
+		tokenURL = azureTokenURL(azureAuthorityHost, tenantID)
+	}
+	if scope == "" {
+		scope = AzureCognitiveServicesScope
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	return &oauthTokenEndpoint{tokenURL: tokenURL, scope: scope, httpClient: httpClient}, nil
+}
+
+// tokenResponse is the body returned by the token endpoint. Entra ID returns
+// expires_in as a number while some other issuers return a string.
+type tokenResponse struct {
+	AccessToken string          `json:"access_token"`
+	ExpiresIn   json.RawMessage `json:"expires_in"`
+}
+
+func (e *oauthTokenEndpoint) request(ctx context.Context, form url.Values) (oauthToken, error) {
+	form.Set("scope", e.scope)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return oauthToken{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		tokenErr := &TokenError{HTTPStatusCode: resp.StatusCode}
+		tokenErr.RawResponseBytes, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return oauthToken{}, err
+		}
+		_ = json.Unmarshal(tokenErr.RawResponseBytes, tokenErr)
+		return oauthToken{}, tokenErr
+	}
+
+	var tr tokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return oauthToken{}, err
+	}
+	if tr.AccessToken == "" {
+		return oauthToken{}, ErrTokenResponseMissingToken
+	}
+	expiresIn, err := strconv.Atoi(strings.Trim(string(tr.ExpiresIn), `"`))
+	if err != nil || expiresIn <= 0 {
+		expiresIn = defaultTokenLifetimeSeconds
+	}
+	return oauthToken{
+		accessToken: tr.AccessToken,
+		expiresIn:   time.Duration(expiresIn) * time.Second,
+	}, nil
+}
+
+// cachingTokenProvider caches a token until RefreshMargin before it expires,
+// or until half its lifetime for tokens which live less than twice as long.
+// While the cached token is still valid it is returned at once and renewed in
+// the background; callers only wait for a refresh once it has expired.
+// Concurrent callers share a single refresh, and failed refreshes are retried
+// with exponential backoff.
+type cachingTokenProvider struct {
+	fetch         func(ctx context.Context) (oauthToken, error)
+	refreshMargin time.Duration
+	now           func() time.Time
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+	// refreshAt is when the token is renewed, before expiresAt.
+	refreshAt time.Time
+	// refreshing is closed once the refresh in flight ends, nil without one.
+	refreshing chan struct{}
+	// err is the error of the last refresh, which is not retried before retryAt.
+	err      error
+	failures int
+	retryAt  time.Time
+}
+
+func newCachingTokenProvider(
+	fetch func(ctx context.Context) (oauthToken, error),
+	refreshMargin time.Duration,
+) *cachingTokenProvider {
+	if refreshMargin <= 0 {
+		refreshMargin = defaultTokenRefreshMargin
+	}
+	return &cachingTokenProvider{fetch: fetch, refreshMargin: refreshMargin, now: time.Now}
+}
+
+func (p *cachingTokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	now := p.now()
+	valid := p.accessToken != "" && now.Before(p.expiresAt)
+	due := !valid || !now.Before(p.refreshAt)
+	if due && p.refreshing == nil && !now.Before(p.retryAt) {
+		p.startRefresh(now)
+	}
+	if valid {
+		token := p.accessToken
+		p.mu.Unlock()
+		return token, nil
+	}
+	refreshing, err := p.refreshing, p.err
+	p.mu.Unlock()
+	if refreshing == nil {
+		// The last refresh failed and is backing off.
+		return "", err
+	}
+
+	select {
+	case <-refreshing:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.accessToken != "" && p.now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+	return "", p.err
+}
+
+// startRefresh fetches a token in the background. The refresh is not bound to
+// the context of the caller, which may end before the token is needed again.
+// The caller must hold p.mu.
+func (p *cachingTokenProvider) startRefresh(now time.Time) {
+	done := make(chan struct{})
+	p.refreshing = done
+	go func() {
+		started := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), tokenRefreshTimeout)
+		token, err := p.fetch(ctx)
+		cancel()
+		// The backoff starts when the refresh failed, not when it started.
+		failedAt := now.Add(time.Since(started))
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err != nil {
+			backoff := maxTokenRefreshBackoff
+			if p.failures < 16 && minTokenRefreshBackoff<<p.failures < backoff {
+				backoff = minTokenRefreshBackoff << p.failures
+			}
+			p.err, p.failures, p.retryAt = err, p.failures+1, failedAt.Add(backoff)
+		} else {
+			// A margin as long as the token lives would refresh it on every call.
+			margin := p.refreshMargin
+			if margin > token.expiresIn/2 {
+				margin = token.expiresIn / 2
+			}
+			p.accessToken, p.expiresAt = token.accessToken, now.Add(token.expiresIn)
+			p.refreshAt = p.expiresAt.Add(-margin)
+			p.err, p.failures, p.retryAt = nil, 0, time.Time{}
+		}
+		p.refreshing = nil
+		close(done)
+	}()
+}
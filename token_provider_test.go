@@ -0,0 +1,321 @@
+package openai //nolint:testpackage // testing private fields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type fakeTokenServer struct {
+	server    *httptest.Server
+	requests  int32
+	failing   int32
+	expiresIn int
+	lastForm  atomic.Value
+}
+
+func newFakeTokenServer(t *testing.T) *fakeTokenServer {
+	t.Helper()
+	ts := &fakeTokenServer{expiresIn: 3600}
+	ts.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&ts.requests, 1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("invalid token request: %v", err)
+		}
+		ts.lastForm.Store(r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.LoadInt32(&ts.failing) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret."}`)
+			return
+		}
+		fmt.Fprintf(w, `{"token_type":"Bearer","expires_in":%d,"access_token":"token-%d"}`, ts.expiresIn, n)
+	}))
+	t.Cleanup(ts.server.Close)
+	return ts
+}
+
+func (ts *fakeTokenServer) requestCount() int {
+	return int(atomic.LoadInt32(&ts.requests))
+}
+
+func (ts *fakeTokenServer) form(key string) string {
+	form, _ := ts.lastForm.Load().(url.Values)
+	return form.Get(key)
+}
+
+func TestClientCredentialsTokenProvider(t *testing.T) {
+	tokenServer := newFakeTokenServer(t)
+	provider, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{
+		ClientID:     "client-id",
+		ClientSecret: "secret",
+		TokenURL:     tokenServer.server.URL,
+	})
+	checks.NoError(t, err, "NewClientCredentialsTokenProvider error")
+
+	cache, _ := provider.(*cachingTokenProvider)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		token, tokenErr := provider.Token(ctx)
+		checks.NoError(t, tokenErr, "Token error")
+		if token != "token-1" {
+			t.Fatalf("expected the cached token, got %q", token)
+		}
+	}
+	if tokenServer.requestCount() != 1 {
+		t.Fatalf("expected one token request, got %d", tokenServer.requestCount())
+	}
+	if tokenServer.form("grant_type") != "client_credentials" || tokenServer.form("client_secret") != "secret" ||
+		tokenServer.form("scope") != AzureCognitiveServicesScope {
+		t.Fatalf("unexpected token request form: %v", tokenServer.lastForm.Load())
+	}
+
+	// Within the refresh margin the token is renewed in the background before it expires.
+	now = now.Add(56 * time.Minute)
+	token, err := provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	if token != "token-1" {
+		t.Fatalf("expected the still valid token during the refresh, got %q", token)
+	}
+	waitForTokenRefresh(t, cache)
+	token, err = provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	if token != "token-2" {
+		t.Fatalf("expected a refreshed token, got %q", token)
+	}
+
+	// A failed refresh keeps using the token while it is still valid.
+	atomic.StoreInt32(&tokenServer.failing, 1)
+	now = now.Add(56 * time.Minute)
+	token, err = provider.Token(ctx)
+	checks.NoError(t, err, "Token should fall back to the cached token")
+	if token != "token-2" {
+		t.Fatalf("expected the still valid token, got %q", token)
+	}
+
+	// Once it has expired the token endpoint error is returned.
+	now = now.Add(5 * time.Minute)
+	_, err = provider.Token(ctx)
+	var tokenErr *TokenError
+	if !errors.As(err, &tokenErr) || tokenErr.HTTPStatusCode != http.StatusUnauthorized ||
+		tokenErr.Code != "invalid_client" {
+		t.Fatalf("expected TokenError, got %v", err)
+	}
+}
+
+// waitForTokenRefresh waits for the refresh p runs in the background.
+func waitForTokenRefresh(t *testing.T, p *cachingTokenProvider) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mu.Lock()
+		refreshing := p.refreshing
+		p.mu.Unlock()
+		if refreshing == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("token refresh did not end")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCachingTokenProviderRefreshesInBackground(t *testing.T) {
+	type result struct {
+		token oauthToken
+		err   error
+	}
+	var fetches int32
+	results := make(chan result)
+	provider := newCachingTokenProvider(func(ctx context.Context) (oauthToken, error) {
+		atomic.AddInt32(&fetches, 1)
+		r := <-results
+		return r.token, r.err
+	}, 5*time.Minute)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+	ctx := context.Background()
+	fetched := func(want int32) {
+		t.Helper()
+		if got := atomic.LoadInt32(&fetches); got != want {
+			t.Fatalf("expected %d token requests, got %d", want, got)
+		}
+	}
+
+	// Without a token callers wait for it.
+	go func() { results <- result{token: oauthToken{accessToken: "a", expiresIn: 10 * time.Minute}} }()
+	token, err := provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	if token != "a" {
+		t.Fatalf("expected the fetched token, got %q", token)
+	}
+
+	// A hanging refresh doesn't hold back callers while the token is valid.
+	now = now.Add(6 * time.Minute)
+	for i := 0; i < 3; i++ {
+		token, err = provider.Token(ctx)
+		checks.NoError(t, err, "Token error")
+		if token != "a" {
+			t.Fatalf("expected the still valid token, got %q", token)
+		}
+	}
+	waitForFetch := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&fetches) < 2 && time.Now().Before(waitForFetch) {
+		time.Sleep(time.Millisecond)
+	}
+	fetched(2)
+
+	// A failed refresh is not retried before its backoff.
+	results <- result{err: errors.New("token endpoint unavailable")}
+	waitForTokenRefresh(t, provider)
+	_, err = provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	fetched(2)
+
+	// Once the token expired, callers wait for the refresh after the backoff.
+	now = now.Add(5 * time.Minute)
+	go func() { results <- result{token: oauthToken{accessToken: "b", expiresIn: 10 * time.Minute}} }()
+	token, err = provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	if token != "b" {
+		t.Fatalf("expected the refreshed token, got %q", token)
+	}
+	fetched(3)
+}
+
+func TestCachingTokenProviderShortLivedToken(t *testing.T) {
+	var fetches int32
+	provider := newCachingTokenProvider(func(ctx context.Context) (oauthToken, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return oauthToken{accessToken: fmt.Sprintf("token-%d", n), expiresIn: 2 * time.Minute}, nil
+	}, 5*time.Minute)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// A token living less than the margin is refreshed after half its lifetime,
+	// not on every call.
+	for i := 0; i < 3; i++ {
+		token, err := provider.Token(ctx)
+		checks.NoError(t, err, "Token error")
+		if token != "token-1" {
+			t.Fatalf("expected the cached token, got %q", token)
+		}
+		waitForTokenRefresh(t, provider)
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Fatalf("expected 1 token request, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	_, err := provider.Token(ctx)
+	checks.NoError(t, err, "Token error")
+	waitForTokenRefresh(t, provider)
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Fatalf("expected the token to be refreshed after half its lifetime, got %d requests", got)
+	}
+}
+
+func TestClientCredentialsTokenProviderValidation(t *testing.T) {
+	_, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{ClientID: "client-id"})
+	if !errors.Is(err, ErrTokenProviderMissingTenant) {
+		t.Fatalf("expected ErrTokenProviderMissingTenant, got %v", err)
+	}
+
+	provider, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{TenantID: "tenant"})
+	checks.NoError(t, err, "NewClientCredentialsTokenProvider error")
+	cache, _ := provider.(*cachingTokenProvider)
+	if cache == nil {
+		t.Fatalf("expected a caching token provider")
+	}
+}
+
+func TestWorkloadIdentityTokenProvider(t *testing.T) {
+	tokenServer := newFakeTokenServer(t)
+	tokenFile := filepath.Join(t.TempDir(), "azure-identity-token")
+	checks.NoError(t, os.WriteFile(tokenFile, []byte("federated-1\n"), 0o600), "WriteFile error")
+
+	t.Setenv("AZURE_CLIENT_ID", "workload-client")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	provider, err := NewWorkloadIdentityTokenProvider(WorkloadIdentityConfig{TokenURL: tokenServer.server.URL})
+	checks.NoError(t, err, "NewWorkloadIdentityTokenProvider error")
+
+	cache, _ := provider.(*cachingTokenProvider)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, err = provider.Token(context.Background())
+	checks.NoError(t, err, "Token error")
+	if tokenServer.form("client_id") != "workload-client" || tokenServer.form("client_assertion") != "federated-1" ||
+		tokenServer.form("client_assertion_type") != azureClientAssertionType {
+		t.Fatalf("unexpected token request form: %v", tokenServer.lastForm.Load())
+	}
+
+	// The rotated federated token is picked up on the next refresh.
+	checks.NoError(t, os.WriteFile(tokenFile, []byte("federated-2"), 0o600), "WriteFile error")
+	now = now.Add(time.Hour)
+	_, err = provider.Token(context.Background())
+	checks.NoError(t, err, "Token error")
+	if tokenServer.form("client_assertion") != "federated-2" {
+		t.Fatalf("expected the rotated assertion, got %q", tokenServer.form("client_assertion"))
+	}
+
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "")
+	_, err = NewWorkloadIdentityTokenProvider(WorkloadIdentityConfig{TokenURL: tokenServer.server.URL})
+	if !errors.Is(err, ErrTokenProviderMissingTokenFile) {
+		t.Fatalf("expected ErrTokenProviderMissingTokenFile, got %v", err)
+	}
+}
+
+func TestClientUsesTokenProvider(t *testing.T) {
+	tokenServer := newFakeTokenServer(t)
+	var authorization atomic.Value
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"object":"list","data":[]}`)
+	}))
+	defer api.Close()
+
+	provider, err := NewClientCredentialsTokenProvider(ClientCredentialsConfig{
+		ClientID: "client-id",
+		TokenURL: tokenServer.server.URL,
+	})
+	checks.NoError(t, err, "NewClientCredentialsTokenProvider error")
+	client := NewClientWithConfig(DefaultAzureADConfig(provider, api.URL))
+
+	_, err = client.ListModels(context.Background())
+	checks.NoError(t, err, "ListModels error")
+	if got := authorization.Load(); got != "Bearer token-1" {
+		t.Fatalf("unexpected Authorization header %v", got)
+	}
+
+	// Static keys keep their APIType specific header.
+	config := DefaultAzureConfig("", api.URL)
+	config.TokenProvider = NewStaticTokenProvider("static-key")
+	req, err := NewClientWithConfig(config).newRequest(context.Background(), http.MethodGet, api.URL)
+	checks.NoError(t, err, "newRequest error")
+	if req.Header.Get(AzureAPIKeyHeader) != "static-key" {
+		t.Fatalf("expected api-key header, got %v", req.Header)
+	}
+
+	// Errors from the provider fail the call before it is sent.
+	config.TokenProvider = TokenProviderFunc(func(context.Context) (string, error) {
+		return "", errors.New("no credentials")
+	})
+	_, err = NewClientWithConfig(config).ListModels(context.Background())
+	checks.HasError(t, err, "ListModels should fail when the token provider fails")
+}
@@ -0,0 +1,139 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+const uploadsSuffix = "/uploads"
+
+// UploadRequest starts an Upload, which receives the bytes of one file in
+// parts of up to 64MB and turns them into a File once completed.
+type UploadRequest struct {
+	Filename string      `json:"filename"`
+	Purpose  PurposeType `json:"purpose"`
+	// Bytes is the size of the whole file.
+	Bytes    int64  `json:"bytes"`
+	MimeType string `json:"mime_type"`
+}
+
+type UploadStatus string
+
+const (
+	UploadStatusPending   UploadStatus = "pending"
+	UploadStatusCompleted UploadStatus = "completed"
+	UploadStatusCancelled UploadStatus = "cancelled"
+	UploadStatusExpired   UploadStatus = "expired"
+)
+
+type Upload struct {
+	ID        string       `json:"id"`
+	Object    string       `json:"object"`
+	Bytes     int64        `json:"bytes"`
+	CreatedAt int64        `json:"created_at"`
+	Filename  string       `json:"filename"`
+	Purpose   string       `json:"purpose"`
+	Status    UploadStatus `json:"status"`
+	ExpiresAt int64        `json:"expires_at"`
+	// File is the file created from the parts, set once the upload is completed.
+	File *File `json:"file,omitempty"`
+
+	httpHeader
+}
+
+type UploadPart struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	CreatedAt int64  `json:"created_at"`
+	UploadID  string `json:"upload_id"`
+
+	httpHeader
+}
+
+// CompleteUploadRequest lists the parts making up the file, in order.
+type CompleteUploadRequest struct {
+	PartIDs []string `json:"part_ids"`
+	// MD5 is the hex encoded md5 checksum of the file, verified by the API when set.
+	MD5 string `json:"md5,omitempty"`
+}
+
+// CreateUpload creates an Upload to which parts can be added.
+func (c *Client) CreateUpload(
+	ctx context.Context,
+	request UploadRequest,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(uploadsSuffix),
+		withBody(request), withOptions(opts...))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// AddUploadPart adds a part of up to 64MB to an Upload. Parts may be added
+// concurrently; their order is set by CompleteUpload.
+func (c *Client) AddUploadPart(
+	ctx context.Context,
+	uploadID string,
+	data io.Reader,
+	opts ...RequestOption,
+) (response UploadPart, err error) {
+	form := c.newMultipartForm(func(builder utils.FormBuilder) error {
+		if formErr := builder.CreateFormFileReader("data", data, "part"); formErr != nil {
+			return formErr
+		}
+		return builder.Close()
+	}, rewindReaders(data))
+	defer form.close()
+
+	urlSuffix := fmt.Sprintf("%s/%s/parts", uploadsSuffix, url.PathEscape(uploadID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(form), withOptions(opts...))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CompleteUpload assembles the parts into a File. No parts can be added afterwards.
+func (c *Client) CompleteUpload(
+	ctx context.Context,
+	uploadID string,
+	request CompleteUploadRequest,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/complete", uploadsSuffix, url.PathEscape(uploadID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBody(request), withOptions(opts...))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// CancelUpload cancels an Upload. No parts can be added afterwards.
+func (c *Client) CancelUpload(
+	ctx context.Context,
+	uploadID string,
+	opts ...RequestOption,
+) (response Upload, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/cancel", uploadsSuffix, url.PathEscape(uploadID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withOptions(opts...))
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
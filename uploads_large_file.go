@@ -0,0 +1,446 @@
+package openai
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // the Uploads API verifies files with md5
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxUploadPartSize is the largest part the Uploads API accepts.
+	maxUploadPartSize        = 64 << 20
+	defaultUploadConcurrency = 4
+	defaultUploadMaxRetries  = 3
+	defaultUploadRetryDelay  = time.Second
+	defaultUploadMimeType    = "application/octet-stream"
+	uploadCancelTimeout      = 30 * time.Second
+	stateFileMode            = 0o600
+)
+
+var (
+	ErrUploadFilenameRequired = errors.New("upload filename is required")
+	ErrUploadPartSizeInvalid  = errors.New("upload part size must be at most 64MB")
+	ErrUploadMissingID        = errors.New("upload was created without an ID")
+	ErrUploadEmptyFile        = errors.New("upload file is empty")
+)
+
+// UploadLargeFileOptions configures UploadLargeFile. Zero values use the defaults.
+type UploadLargeFileOptions struct {
+	// Filename is the name of the created file. It is required.
+	Filename string
+	// MimeType defaults to the type of the Filename extension, or application/octet-stream.
+	MimeType string
+	// PartSize is the size of every part but the last. Defaults to 64MB, the maximum.
+	PartSize int64
+	// Concurrency is the number of parts uploaded at once. Defaults to 4.
+	Concurrency int
+	// MaxRetries is how often a part failing with a retryable error is
+	// uploaded again. Defaults to 3; a negative value disables retries.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry of a part, doubled for
+	// every further retry. Defaults to 1s.
+	RetryDelay time.Duration
+	// ProgressPath, if set, is a JSON file recording the upload and its
+	// uploaded parts. When it describes an unexpired upload of the same file,
+	// with the same md5 checksum, UploadLargeFile resumes that upload instead
+	// of starting over. A resumed upload the API rejects, e.g. because it was
+	// cancelled or completed in the meantime, is cancelled and started over.
+	// The file is removed once the upload is completed. Without it, a failed
+	// upload is cancelled, since it can't be resumed.
+	ProgressPath string
+}
+
+func (o UploadLargeFileOptions) withDefaults() UploadLargeFileOptions {
+	if o.MimeType == "" {
+		o.MimeType, _, _ = strings.Cut(mime.TypeByExtension(filepath.Ext(o.Filename)), ";")
+		if o.MimeType == "" {
+			o.MimeType = defaultUploadMimeType
+		}
+	}
+	if o.PartSize <= 0 {
+		o.PartSize = maxUploadPartSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultUploadConcurrency
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = defaultUploadMaxRetries
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = defaultUploadRetryDelay
+	}
+	return o
+}
+
+// UploadProgress is the state UploadLargeFile persists to ProgressPath.
+type UploadProgress struct {
+	UploadID  string      `json:"upload_id"`
+	ExpiresAt int64       `json:"expires_at"`
+	Filename  string      `json:"filename"`
+	Purpose   PurposeType `json:"purpose"`
+	Size      int64       `json:"size"`
+	PartSize  int64       `json:"part_size"`
+	// MD5 is the hex md5 checksum of the file.
+	MD5 string `json:"md5"`
+	// PartIDs holds the ID of every part by index, empty for parts not uploaded yet.
+	PartIDs []string `json:"part_ids"`
+}
+
+// resumes reports whether p is an unexpired upload of the file described by the arguments.
+func (p UploadProgress) resumes(
+	filename string,
+	purpose PurposeType,
+	size, partSize int64,
+	checksum string,
+	now time.Time,
+) bool {
+	return p.UploadID != "" &&
+		p.Filename == filename && p.Purpose == purpose && p.Size == size && p.PartSize == partSize &&
+		p.MD5 == checksum && len(p.PartIDs) == uploadPartCount(size, partSize) &&
+		(p.ExpiresAt == 0 || now.Unix() < p.ExpiresAt)
+}
+
+func uploadPartCount(size, partSize int64) int {
+	return int((size + partSize - 1) / partSize)
+}
+
+// loadUploadProgress reads the progress at path. A missing file is no progress.
+func loadUploadProgress(path string) (progress UploadProgress, err error) {
+	if path == "" {
+		return
+	}
+	err = readJSONFile(path, &progress)
+	return
+}
+
+// readJSONFile decodes the file at path into v, leaving v untouched if the file doesn't exist.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeUploadProgress removes the progress at path, if set.
+func removeUploadProgress(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// saveUploadProgress writes progress to path, if set.
+func saveUploadProgress(path string, progress UploadProgress) error {
+	if path == "" {
+		return nil
+	}
+	return writeJSONFile(path, progress)
+}
+
+// writeJSONFile replaces the file at path with the JSON of v through a
+// temporary file, so an interrupted write never leaves a truncated file.
+func writeJSONFile(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, stateFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// uploadAPI is the part of Client and Router UploadLargeFile drives.
+type uploadAPI interface {
+	CreateUpload(ctx context.Context, request UploadRequest, opts ...RequestOption) (Upload, error)
+	AddUploadPart(ctx context.Context, uploadID string, data io.Reader, opts ...RequestOption) (UploadPart, error)
+	CompleteUpload(
+		ctx context.Context,
+		uploadID string,
+		request CompleteUploadRequest,
+		opts ...RequestOption,
+	) (Upload, error)
+	CancelUpload(ctx context.Context, uploadID string, opts ...RequestOption) (Upload, error)
+}
+
+// UploadLargeFile uploads the size bytes of r with the Uploads API. The file
+// is split into parts which are uploaded concurrently, retrying parts which
+// fail with retryable errors, and completed with its md5 checksum so the API
+// verifies the assembled file. The created file is Upload.File.
+//
+// With options.ProgressPath set, an interrupted call can be repeated with the
+// same arguments to upload only the missing parts.
+func (c *Client) UploadLargeFile(
+	ctx context.Context,
+	r io.ReaderAt,
+	size int64,
+	purpose PurposeType,
+	options UploadLargeFileOptions,
+	opts ...RequestOption,
+) (Upload, error) {
+	return uploadLargeFile(ctx, c, r, size, purpose, options, opts)
+}
+
+func uploadLargeFile(
+	ctx context.Context,
+	api uploadAPI,
+	r io.ReaderAt,
+	size int64,
+	purpose PurposeType,
+	options UploadLargeFileOptions,
+	opts []RequestOption,
+) (Upload, error) {
+	if options.Filename == "" {
+		return Upload{}, ErrUploadFilenameRequired
+	}
+	if size <= 0 {
+		return Upload{}, ErrUploadEmptyFile
+	}
+	options = options.withDefaults()
+	if options.PartSize > maxUploadPartSize {
+		return Upload{}, ErrUploadPartSizeInvalid
+	}
+
+	hash := md5.New() //nolint:gosec // the Uploads API verifies files with md5
+	if _, err := io.Copy(hash, io.NewSectionReader(r, 0, size)); err != nil {
+		return Upload{}, fmt.Errorf("computing upload checksum: %w", err)
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	progress, err := loadUploadProgress(options.ProgressPath)
+	if err != nil {
+		return Upload{}, err
+	}
+	resumed := progress.resumes(options.Filename, purpose, size, options.PartSize, checksum, time.Now())
+	if !resumed {
+		progress, err = startUpload(ctx, api, size, purpose, checksum, options, opts)
+		if err != nil {
+			return Upload{}, err
+		}
+	}
+
+	upload, err := completeUpload(ctx, api, r, &progress, options, opts)
+	if err != nil && resumed && isRejectedUploadError(err) {
+		// The saved upload can't be resumed, so it is replaced by a new one.
+		cancelUpload(api, progress.UploadID, opts)
+		progress, err = startUpload(ctx, api, size, purpose, checksum, options, opts)
+		if err != nil {
+			return Upload{}, err
+		}
+		upload, err = completeUpload(ctx, api, r, &progress, options, opts)
+	}
+	if err != nil {
+		if options.ProgressPath == "" {
+			cancelUpload(api, progress.UploadID, opts)
+		}
+		return upload, err
+	}
+	return upload, removeUploadProgress(options.ProgressPath)
+}
+
+// completeUpload uploads the missing parts of progress and completes the upload.
+func completeUpload(
+	ctx context.Context,
+	api uploadAPI,
+	r io.ReaderAt,
+	progress *UploadProgress,
+	options UploadLargeFileOptions,
+	opts []RequestOption,
+) (Upload, error) {
+	if err := uploadParts(ctx, api, r, progress, options, opts); err != nil {
+		return Upload{}, err
+	}
+	return api.CompleteUpload(ctx, progress.UploadID, CompleteUploadRequest{
+		PartIDs: progress.PartIDs,
+		MD5:     progress.MD5,
+	}, opts...)
+}
+
+// isRejectedUploadError reports whether err is a client error other than
+// rate limiting, which retrying the same upload won't fix.
+func isRejectedUploadError(err error) bool {
+	var (
+		apiErr *APIError
+		reqErr *RequestError
+		status int
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+	return status >= http.StatusBadRequest && status < http.StatusInternalServerError &&
+		status != http.StatusTooManyRequests
+}
+
+// cancelUpload cancels a failed upload, so it doesn't stay pending until it
+// expires. It runs after ctx may have ended, and the error of the failed upload
+// is the one returned, so it has a context and ignores errors of its own.
+func cancelUpload(api uploadAPI, uploadID string, opts []RequestOption) {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadCancelTimeout)
+	defer cancel()
+	_, _ = api.CancelUpload(ctx, uploadID, opts...)
+}
+
+func startUpload(
+	ctx context.Context,
+	api uploadAPI,
+	size int64,
+	purpose PurposeType,
+	checksum string,
+	options UploadLargeFileOptions,
+	opts []RequestOption,
+) (UploadProgress, error) {
+	upload, err := api.CreateUpload(ctx, UploadRequest{
+		Filename: options.Filename,
+		Purpose:  purpose,
+		Bytes:    size,
+		MimeType: options.MimeType,
+	}, opts...)
+	if err != nil {
+		return UploadProgress{}, err
+	}
+	if upload.ID == "" {
+		return UploadProgress{}, ErrUploadMissingID
+	}
+
+	progress := UploadProgress{
+		UploadID:  upload.ID,
+		ExpiresAt: upload.ExpiresAt,
+		Filename:  options.Filename,
+		Purpose:   purpose,
+		Size:      size,
+		PartSize:  options.PartSize,
+		MD5:       checksum,
+		PartIDs:   make([]string, uploadPartCount(size, options.PartSize)),
+	}
+	if err = saveUploadProgress(options.ProgressPath, progress); err != nil {
+		cancelUpload(api, upload.ID, opts)
+		return UploadProgress{}, err
+	}
+	return progress, nil
+}
+
+// uploadParts uploads the parts progress is missing, saving progress after
+// every part. The first error stops the remaining parts.
+func uploadParts(
+	ctx context.Context,
+	api uploadAPI,
+	r io.ReaderAt,
+	progress *UploadProgress,
+	options UploadLargeFileOptions,
+	opts []RequestOption,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+	sem := make(chan struct{}, options.Concurrency)
+	for i, partID := range progress.PartIDs {
+		if partID != "" {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			offset := int64(i) * progress.PartSize
+			part, uploadErr := addUploadPartWithRetry(ctx, api, progress.UploadID, r, offset,
+				partLength(progress.Size, progress.PartSize, offset), options, opts)
+			if uploadErr != nil {
+				fail(fmt.Errorf("uploading part %d: %w", i+1, uploadErr))
+				return
+			}
+			mu.Lock()
+			progress.PartIDs[i] = part.ID
+			saveErr := saveUploadProgress(options.ProgressPath, *progress)
+			mu.Unlock()
+			if saveErr != nil {
+				fail(saveErr)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func partLength(size, partSize, offset int64) int64 {
+	if offset+partSize > size {
+		return size - offset
+	}
+	return partSize
+}
+
+func addUploadPartWithRetry(
+	ctx context.Context,
+	api uploadAPI,
+	uploadID string,
+	r io.ReaderAt,
+	offset, length int64,
+	options UploadLargeFileOptions,
+	opts []RequestOption,
+) (UploadPart, error) {
+	delay := options.RetryDelay
+	for attempt := 0; ; attempt++ {
+		part, err := api.AddUploadPart(ctx, uploadID, io.NewSectionReader(r, offset, length), opts...)
+		if err == nil || attempt >= options.MaxRetries || !isRetryableError(err) {
+			return part, err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return part, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
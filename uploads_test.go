@@ -0,0 +1,270 @@
+package openai_test
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // the Uploads API verifies files with md5
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+const uploadContent = "0123456789abcdefghij"
+
+// uploadServer serves one upload and assembles its parts on completion.
+type uploadServer struct {
+	mu        sync.Mutex
+	created   int
+	cancelled int
+	parts     map[string]string
+	// failures maps part contents to the statuses returned before accepting them.
+	failures map[string][]int
+	request  openai.UploadRequest
+	complete openai.CompleteUploadRequest
+}
+
+func (s *uploadServer) register(t *testing.T, server *test.ServerTest) {
+	t.Helper()
+	server.RegisterHandler("/v1/uploads", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.created++
+		_ = json.NewDecoder(r.Body).Decode(&s.request)
+		fmt.Fprint(w, `{"id":"upload_1","status":"pending","expires_at":4102444800}`)
+	})
+	server.RegisterHandler("/v1/uploads/upload_1/parts", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		file, _, err := r.FormFile("data")
+		checks.NoError(t, err, "FormFile error")
+		data, _ := io.ReadAll(file)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if statuses := s.failures[string(data)]; len(statuses) > 0 {
+			s.failures[string(data)] = statuses[1:]
+			w.WriteHeader(statuses[0])
+			fmt.Fprint(w, `{"error":{"message":"part failed"}}`)
+			return
+		}
+		id := fmt.Sprintf("part_%s", data)
+		s.parts[id] = string(data)
+		fmt.Fprintf(w, `{"id":%q,"upload_id":"upload_1"}`, id)
+	})
+	server.RegisterHandler("/v1/uploads/upload_1/complete", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewDecoder(r.Body).Decode(&s.complete)
+		var assembled strings.Builder
+		for _, id := range s.complete.PartIDs {
+			assembled.WriteString(s.parts[id])
+		}
+		sum := md5.Sum([]byte(assembled.String())) //nolint:gosec // the Uploads API verifies files with md5
+		if hex.EncodeToString(sum[:]) != s.complete.MD5 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"md5 mismatch"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"upload_1","status":"completed","file":{"id":"file_1","bytes":%d}}`, assembled.Len())
+	})
+	server.RegisterHandler("/v1/uploads/upload_1/cancel", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.cancelled++
+		fmt.Fprint(w, `{"id":"upload_1","status":"cancelled"}`)
+	})
+}
+
+func uploadOptions(t *testing.T) openai.UploadLargeFileOptions {
+	t.Helper()
+	return openai.UploadLargeFileOptions{
+		Filename:     "train.jsonl",
+		PartSize:     8,
+		Concurrency:  2,
+		RetryDelay:   time.Millisecond,
+		ProgressPath: filepath.Join(t.TempDir(), "upload.json"),
+	}
+}
+
+func TestUploadLargeFile(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := &uploadServer{
+		parts:    map[string]string{},
+		failures: map[string][]int{"89abcdef": {http.StatusInternalServerError, http.StatusTooManyRequests}},
+	}
+	uploads.register(t, server)
+
+	options := uploadOptions(t)
+	upload, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent),
+		int64(len(uploadContent)), openai.PurposeBatch, options)
+	checks.NoError(t, err, "UploadLargeFile error")
+
+	if upload.Status != openai.UploadStatusCompleted || upload.File == nil || upload.File.Bytes != len(uploadContent) {
+		t.Fatalf("unexpected upload %+v", upload)
+	}
+	if uploads.request.Bytes != int64(len(uploadContent)) || uploads.request.MimeType != "application/octet-stream" {
+		t.Fatalf("unexpected upload request %+v", uploads.request)
+	}
+	want := []string{"part_01234567", "part_89abcdef", "part_ghij"}
+	if strings.Join(uploads.complete.PartIDs, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected parts %v, got %v", want, uploads.complete.PartIDs)
+	}
+	if _, err = os.Stat(options.ProgressPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the progress file to be removed, got %v", err)
+	}
+}
+
+func TestUploadLargeFileResumes(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := &uploadServer{
+		parts:    map[string]string{},
+		failures: map[string][]int{"ghij": {http.StatusBadRequest}},
+	}
+	uploads.register(t, server)
+
+	options := uploadOptions(t)
+	options.Concurrency = 1
+	size := int64(len(uploadContent))
+	_, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent), size,
+		openai.PurposeBatch, options)
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the part to fail without retries, got %v", err)
+	}
+
+	data, err := os.ReadFile(options.ProgressPath)
+	checks.NoError(t, err, "ReadFile error")
+	var progress openai.UploadProgress
+	checks.NoError(t, json.Unmarshal(data, &progress), "Unmarshal error")
+	if progress.UploadID != "upload_1" || strings.Join(progress.PartIDs, ",") != "part_01234567,part_89abcdef," {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+
+	// Resuming sends the failed part only, to the same upload.
+	uploaded := len(uploads.parts)
+	upload, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent), size,
+		openai.PurposeBatch, options)
+	checks.NoError(t, err, "UploadLargeFile error")
+	if upload.Status != openai.UploadStatusCompleted || uploads.created != 1 || len(uploads.parts) != uploaded+1 {
+		t.Fatalf("expected the upload to resume, got %+v after %d creates", upload, uploads.created)
+	}
+	if uploads.cancelled != 0 {
+		t.Fatalf("expected the resumable upload to be kept, got %d cancels", uploads.cancelled)
+	}
+}
+
+func TestUploadLargeFileCancelsFailedUpload(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := &uploadServer{
+		parts:    map[string]string{},
+		failures: map[string][]int{"ghij": {http.StatusBadRequest}},
+	}
+	uploads.register(t, server)
+
+	options := uploadOptions(t)
+	options.ProgressPath = ""
+	_, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent),
+		int64(len(uploadContent)), openai.PurposeBatch, options)
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the part error, got %v", err)
+	}
+	if uploads.cancelled != 1 {
+		t.Fatalf("expected the failed upload to be cancelled once, got %d cancels", uploads.cancelled)
+	}
+}
+
+func TestUploadLargeFileStartsOver(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	uploads := &uploadServer{
+		parts:    map[string]string{},
+		failures: map[string][]int{"ghij": {http.StatusBadRequest, http.StatusBadRequest}},
+	}
+	uploads.register(t, server)
+
+	options := uploadOptions(t)
+	options.Concurrency = 1
+	size := int64(len(uploadContent))
+	_, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent), size,
+		openai.PurposeBatch, options)
+	if err == nil {
+		t.Fatal("expected the part to fail")
+	}
+
+	// The resumed upload is rejected again, as if it had been cancelled, so
+	// it is cancelled and replaced by a new upload.
+	upload, err := client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent), size,
+		openai.PurposeBatch, options)
+	checks.NoError(t, err, "UploadLargeFile error")
+	if upload.Status != openai.UploadStatusCompleted || uploads.created != 2 || uploads.cancelled != 1 {
+		t.Fatalf("expected a new upload, got %+v after %d creates and %d cancels",
+			upload, uploads.created, uploads.cancelled)
+	}
+
+	// A file of the same size but other contents isn't resumed.
+	uploads.failures = map[string][]int{"GHIJ": {http.StatusBadRequest}}
+	changed := strings.ToUpper(uploadContent)
+	_, err = client.UploadLargeFile(context.Background(), strings.NewReader(changed), size,
+		openai.PurposeBatch, options)
+	if err == nil {
+		t.Fatal("expected the part to fail")
+	}
+	_, err = client.UploadLargeFile(context.Background(), strings.NewReader(uploadContent), size,
+		openai.PurposeBatch, options)
+	checks.NoError(t, err, "UploadLargeFile error")
+	if uploads.created != 4 {
+		t.Fatalf("expected the changed file to start a new upload, got %d creates", uploads.created)
+	}
+}
+
+func TestUploadLargeFileRejectsEmptyFile(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	_, err := client.UploadLargeFile(context.Background(), strings.NewReader(""), 0, openai.PurposeBatch,
+		openai.UploadLargeFileOptions{Filename: "empty.jsonl"})
+	if !errors.Is(err, openai.ErrUploadEmptyFile) {
+		t.Fatalf("expected ErrUploadEmptyFile, got %v", err)
+	}
+}
+
+func TestUploadLargeFileRequiresFilename(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	_, err := client.UploadLargeFile(context.Background(), strings.NewReader(""), 0, openai.PurposeBatch,
+		openai.UploadLargeFileOptions{})
+	if !errors.Is(err, openai.ErrUploadFilenameRequired) {
+		t.Fatalf("expected ErrUploadFilenameRequired, got %v", err)
+	}
+}
+
+func TestCancelUpload(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/uploads/upload_1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprint(w, `{"id":"upload_1","status":"cancelled"}`)
+	})
+	upload, err := client.CancelUpload(context.Background(), "upload_1")
+	checks.NoError(t, err, "CancelUpload error")
+	if upload.Status != openai.UploadStatusCancelled {
+		t.Fatalf("unexpected upload %+v", upload)
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -73,12 +74,18 @@ type VectorStoreFile struct {
 	VectorStoreID string `json:"vector_store_id"`
 	UsageBytes    int    `json:"usage_bytes"`
 	Status        string `json:"status"`
+	// Attributes are the key-value pairs search filters match against.
+	Attributes map[string]any `json:"attributes,omitempty"`
 
 	httpHeader
 }
 
 type VectorStoreFileRequest struct {
-	FileID string `json:"file_id"`
+	FileID           string            `json:"file_id"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+	// Attributes are up to 16 string, number or boolean values which
+	// VectorStoreSearchRequest.Filters and file_search filters can match.
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 type VectorStoreFilesList struct {
@@ -102,18 +109,118 @@ type VectorStoreFileBatch struct {
 }
 
 type VectorStoreFileBatchRequest struct {
-	FileIDs []string `json:"file_ids"`
+	FileIDs          []string          `json:"file_ids"`
+	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
+	// Attributes are applied to every file of the batch.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+type VectorStoreFilterType string
+
+const (
+	VectorStoreFilterEq  VectorStoreFilterType = "eq"
+	VectorStoreFilterNe  VectorStoreFilterType = "ne"
+	VectorStoreFilterGt  VectorStoreFilterType = "gt"
+	VectorStoreFilterGte VectorStoreFilterType = "gte"
+	VectorStoreFilterLt  VectorStoreFilterType = "lt"
+	VectorStoreFilterLte VectorStoreFilterType = "lte"
+	VectorStoreFilterAnd VectorStoreFilterType = "and"
+	VectorStoreFilterOr  VectorStoreFilterType = "or"
+)
+
+// VectorStoreFilter is either a comparison of the file attribute Key with
+// Value, or an "and"/"or" of Filters.
+type VectorStoreFilter struct {
+	Type    VectorStoreFilterType `json:"type"`
+	Key     string                `json:"key,omitempty"`
+	Value   any                   `json:"value,omitempty"`
+	Filters []VectorStoreFilter   `json:"filters,omitempty"`
+}
+
+// NewVectorStoreComparisonFilter compares the file attribute key with value.
+func NewVectorStoreComparisonFilter(filterType VectorStoreFilterType, key string, value any) VectorStoreFilter {
+	return VectorStoreFilter{Type: filterType, Key: key, Value: value}
+}
+
+// NewVectorStoreCompoundFilter combines filters with VectorStoreFilterAnd or VectorStoreFilterOr.
+func NewVectorStoreCompoundFilter(filterType VectorStoreFilterType, filters ...VectorStoreFilter) VectorStoreFilter {
+	return VectorStoreFilter{Type: filterType, Filters: filters}
+}
+
+type VectorStoreSearchRankingOptions struct {
+	// Ranker is "auto" or a dated ranker such as "default-2024-11-15".
+	Ranker         string  `json:"ranker,omitempty"`
+	ScoreThreshold float64 `json:"score_threshold,omitempty"`
+}
+
+type VectorStoreSearchRequest struct {
+	// Query is a string or a []string.
+	Query          any                              `json:"query"`
+	Filters        *VectorStoreFilter               `json:"filters,omitempty"`
+	MaxNumResults  int                              `json:"max_num_results,omitempty"`
+	RankingOptions *VectorStoreSearchRankingOptions `json:"ranking_options,omitempty"`
+	// RewriteQuery lets the model rewrite the query for vector search.
+	RewriteQuery bool `json:"rewrite_query,omitempty"`
+}
+
+// VectorStoreSearchQuery is the query the search ran, which the API returns
+// either as a string or as a list of strings.
+type VectorStoreSearchQuery []string
+
+func (q *VectorStoreSearchQuery) UnmarshalJSON(data []byte) error {
+	var query string
+	if err := json.Unmarshal(data, &query); err == nil {
+		*q = VectorStoreSearchQuery{query}
+		return nil
+	}
+	var queries []string
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return err
+	}
+	*q = queries
+	return nil
+}
+
+type VectorStoreSearchResultContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type VectorStoreSearchResult struct {
+	FileID     string                           `json:"file_id"`
+	Filename   string                           `json:"filename"`
+	Score      float64                          `json:"score"`
+	Attributes map[string]any                   `json:"attributes"`
+	Content    []VectorStoreSearchResultContent `json:"content"`
+}
+
+type VectorStoreSearchResults struct {
+	Object      string                    `json:"object"`
+	SearchQuery VectorStoreSearchQuery    `json:"search_query"`
+	Data        []VectorStoreSearchResult `json:"data"`
+	HasMore     bool                      `json:"has_more"`
+	NextPage    *string                   `json:"next_page"`
+
+	httpHeader
 }
 
 // CreateVectorStore creates a new vector store.
-func (c *Client) CreateVectorStore(ctx context.Context, request VectorStoreRequest) (response VectorStore, err error) {
-	req, _ := c.newRequest(
+func (c *Client) CreateVectorStore(
+	ctx context.Context,
+	request VectorStoreRequest,
+	opts ...RequestOption,
+) (response VectorStore, err error) {
+	req, err := c.newRequest(
 		ctx,
 		http.MethodPost,
 		c.fullURL(vectorStoresSuffix),
 		withBody(request),
 		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...),
 	)
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -123,10 +230,15 @@ func (c *Client) CreateVectorStore(ctx context.Context, request VectorStoreReque
 func (c *Client) RetrieveVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
+	opts ...RequestOption,
 ) (response VectorStore, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, url.PathEscape(vectorStoreID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -137,10 +249,15 @@ func (c *Client) ModifyVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreRequest,
+	opts ...RequestOption,
 ) (response VectorStore, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, url.PathEscape(vectorStoreID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix), withBody(request),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -150,10 +267,15 @@ func (c *Client) ModifyVectorStore(
 func (c *Client) DeleteVectorStore(
 	ctx context.Context,
 	vectorStoreID string,
+	opts ...RequestOption,
 ) (response VectorStoreDeleteResponse, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, vectorStoreID)
-	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s", vectorStoresSuffix, url.PathEscape(vectorStoreID))
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -163,6 +285,7 @@ func (c *Client) DeleteVectorStore(
 func (c *Client) ListVectorStores(
 	ctx context.Context,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoresList, err error) {
 	urlValues := url.Values{}
 
@@ -185,8 +308,12 @@ func (c *Client) ListVectorStores(
 	}
 
 	urlSuffix := fmt.Sprintf("%s%s", vectorStoresSuffix, encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -197,11 +324,16 @@ func (c *Client) CreateVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreFileRequest,
+	opts ...RequestOption,
 ) (response VectorStoreFile, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, url.PathEscape(vectorStoreID), vectorStoresFilesSuffix)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -212,10 +344,16 @@ func (c *Client) RetrieveVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	fileID string,
+	opts ...RequestOption,
 ) (response VectorStoreFile, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix,
+		url.PathEscape(vectorStoreID), vectorStoresFilesSuffix, url.PathEscape(fileID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -226,10 +364,16 @@ func (c *Client) DeleteVectorStoreFile(
 	ctx context.Context,
 	vectorStoreID string,
 	fileID string,
+	opts ...RequestOption,
 ) (err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, fileID)
-	req, _ := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix,
+		url.PathEscape(vectorStoreID), vectorStoresFilesSuffix, url.PathEscape(fileID))
+	req, err := c.newRequest(ctx, http.MethodDelete, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, nil)
 	return
@@ -240,6 +384,7 @@ func (c *Client) ListVectorStoreFiles(
 	ctx context.Context,
 	vectorStoreID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoreFilesList, err error) {
 	urlValues := url.Values{}
 	if pagination.After != nil {
@@ -260,9 +405,14 @@ func (c *Client) ListVectorStoreFiles(
 		encodedValues = "?" + urlValues.Encode()
 	}
 
-	urlSuffix := fmt.Sprintf("%s/%s%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFilesSuffix, encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s%s%s", vectorStoresSuffix,
+		url.PathEscape(vectorStoreID), vectorStoresFilesSuffix, encodedValues)
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -273,11 +423,17 @@ func (c *Client) CreateVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	request VectorStoreFileBatchRequest,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix)
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+	urlSuffix := fmt.Sprintf("%s/%s%s", vectorStoresSuffix,
+		url.PathEscape(vectorStoreID), vectorStoresFileBatchesSuffix)
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
 		withBody(request),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -288,10 +444,16 @@ func (c *Client) RetrieveVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix, vectorStoreID, vectorStoresFileBatchesSuffix, batchID)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s", vectorStoresSuffix,
+		url.PathEscape(vectorStoreID), vectorStoresFileBatchesSuffix, url.PathEscape(batchID))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
@@ -302,46 +464,57 @@ func (c *Client) CancelVectorStoreFileBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
+	opts ...RequestOption,
 ) (response VectorStoreFileBatch, err error) {
 	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s", vectorStoresSuffix,
-		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/cancel")
-	req, _ := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+		url.PathEscape(vectorStoreID), vectorStoresFileBatchesSuffix, url.PathEscape(batchID), "/cancel")
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
 }
 
-// ListVectorStoreFiles Lists the currently available files for a vector store.
+// ListVectorStoreFilesInBatch Lists the files of a vector store file batch.
 func (c *Client) ListVectorStoreFilesInBatch(
 	ctx context.Context,
 	vectorStoreID string,
 	batchID string,
 	pagination Pagination,
+	opts ...RequestOption,
 ) (response VectorStoreFilesList, err error) {
-	urlValues := url.Values{}
-	if pagination.After != nil {
-		urlValues.Add("after", *pagination.After)
-	}
-	if pagination.Limit != nil {
-		urlValues.Add("limit", fmt.Sprintf("%d", *pagination.Limit))
-	}
-	if pagination.Before != nil {
-		urlValues.Add("before", *pagination.Before)
-	}
-	if pagination.Order != nil {
-		urlValues.Add("order", *pagination.Order)
+	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s%s", vectorStoresSuffix, url.PathEscape(vectorStoreID),
+		vectorStoresFileBatchesSuffix, url.PathEscape(batchID), "/files", encodeQuery(pagination.values()))
+	req, err := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
 	}
 
-	encodedValues := ""
-	if len(urlValues) > 0 {
-		encodedValues = "?" + urlValues.Encode()
-	}
+	err = c.sendRequest(req, &response)
+	return
+}
 
-	urlSuffix := fmt.Sprintf("%s/%s%s/%s%s%s", vectorStoresSuffix,
-		vectorStoreID, vectorStoresFileBatchesSuffix, batchID, "/files", encodedValues)
-	req, _ := c.newRequest(ctx, http.MethodGet, c.fullURL(urlSuffix),
-		withBetaAssistantVersion(c.config.AssistantVersion))
+// SearchVectorStore searches the chunks of a vector store.
+func (c *Client) SearchVectorStore(
+	ctx context.Context,
+	vectorStoreID string,
+	request VectorStoreSearchRequest,
+	opts ...RequestOption,
+) (response VectorStoreSearchResults, err error) {
+	urlSuffix := fmt.Sprintf("%s/%s/search", vectorStoresSuffix, url.PathEscape(vectorStoreID))
+	req, err := c.newRequest(ctx, http.MethodPost, c.fullURL(urlSuffix),
+		withBody(request),
+		withBetaAssistantVersion(c.config.AssistantVersion),
+		withOptions(opts...))
+	if err != nil {
+		return
+	}
 
 	err = c.sendRequest(req, &response)
 	return
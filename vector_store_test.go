@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -347,3 +348,62 @@ func TestVectorStore(t *testing.T) {
 		checks.NoError(t, err, "CancelVectorStoreFileBatch error")
 	})
 }
+
+func TestSearchVectorStore(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var body map[string]any
+	server.RegisterHandler("/v1/vector_stores/vs_1/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{
+			"object": "vector_store.search_results.page",
+			"search_query": "return policy",
+			"data": [{
+				"file_id": "file_1",
+				"filename": "policy.md",
+				"score": 0.87,
+				"attributes": {"region": "eu"},
+				"content": [{"type": "text", "text": "Returns are accepted for 30 days."}]
+			}],
+			"has_more": false,
+			"next_page": null
+		}`)
+	})
+
+	results, err := client.SearchVectorStore(context.Background(), "vs_1", openai.VectorStoreSearchRequest{
+		Query: "What is the return policy?",
+		Filters: &openai.VectorStoreFilter{Type: openai.VectorStoreFilterAnd, Filters: []openai.VectorStoreFilter{
+			openai.NewVectorStoreComparisonFilter(openai.VectorStoreFilterEq, "region", "eu"),
+			openai.NewVectorStoreComparisonFilter(openai.VectorStoreFilterGte, "year", 2024),
+		}},
+		MaxNumResults:  5,
+		RankingOptions: &openai.VectorStoreSearchRankingOptions{Ranker: "auto", ScoreThreshold: 0.5},
+		RewriteQuery:   true,
+	})
+	checks.NoError(t, err, "SearchVectorStore error")
+
+	wantBody := map[string]any{
+		"query": "What is the return policy?",
+		"filters": map[string]any{"type": "and", "filters": []any{
+			map[string]any{"type": "eq", "key": "region", "value": "eu"},
+			map[string]any{"type": "gte", "key": "year", "value": float64(2024)},
+		}},
+		"max_num_results": float64(5),
+		"ranking_options": map[string]any{"ranker": "auto", "score_threshold": 0.5},
+		"rewrite_query":   true,
+	}
+	if !reflect.DeepEqual(body, wantBody) {
+		t.Fatalf("unexpected request body %v", body)
+	}
+	if !reflect.DeepEqual([]string(results.SearchQuery), []string{"return policy"}) {
+		t.Fatalf("unexpected search query %v", results.SearchQuery)
+	}
+	if len(results.Data) != 1 || results.Data[0].Filename != "policy.md" ||
+		results.Data[0].Content[0].Text != "Returns are accepted for 30 days." {
+		t.Fatalf("unexpected results %+v", results.Data)
+	}
+}
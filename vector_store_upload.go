@@ -0,0 +1,176 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// vectorStoreUploadConcurrency bounds the CreateFile calls made at once by UploadFilesAndPoll.
+	vectorStoreUploadConcurrency   = 5
+	vectorStorePollInterval        = 500 * time.Millisecond
+	vectorStorePollMaxInterval     = 5 * time.Second
+	vectorStoreFileBatchInProgress = "in_progress"
+	vectorStoreFileDeleteTimeout   = 30 * time.Second
+)
+
+var ErrVectorStoreNoFiles = errors.New("no files to upload")
+
+// vectorStoreUploadAPI is the part of Client and Router UploadFilesAndPoll drives.
+type vectorStoreUploadAPI interface {
+	CreateFile(ctx context.Context, request FileRequest, opts ...RequestOption) (File, error)
+	DeleteFile(ctx context.Context, fileID string, opts ...RequestOption) error
+	CreateVectorStoreFileBatch(
+		ctx context.Context,
+		vectorStoreID string,
+		request VectorStoreFileBatchRequest,
+		opts ...RequestOption,
+	) (VectorStoreFileBatch, error)
+	RetrieveVectorStoreFileBatch(
+		ctx context.Context,
+		vectorStoreID string,
+		batchID string,
+		opts ...RequestOption,
+	) (VectorStoreFileBatch, error)
+}
+
+// UploadFilesAndPoll uploads the local files at paths, adds them to the
+// vector store as one file batch and waits until none of them is in
+// progress. A nil chunkingStrategy uses the API default. Check the
+// FileCounts of the returned batch for files which failed to be processed.
+// When an upload fails or the file batch can't be created, the files already
+// uploaded are deleted again.
+func (c *Client) UploadFilesAndPoll(
+	ctx context.Context,
+	vectorStoreID string,
+	paths []string,
+	chunkingStrategy *ChunkingStrategy,
+	opts ...RequestOption,
+) (VectorStoreFileBatch, error) {
+	return uploadFilesAndPoll(ctx, c, vectorStoreID, paths, chunkingStrategy, opts)
+}
+
+func uploadFilesAndPoll(
+	ctx context.Context,
+	api vectorStoreUploadAPI,
+	vectorStoreID string,
+	paths []string,
+	chunkingStrategy *ChunkingStrategy,
+	opts []RequestOption,
+) (VectorStoreFileBatch, error) {
+	if len(paths) == 0 {
+		return VectorStoreFileBatch{}, ErrVectorStoreNoFiles
+	}
+	fileIDs, err := uploadVectorStoreFiles(ctx, api, paths, opts)
+	if err != nil {
+		return VectorStoreFileBatch{}, err
+	}
+
+	batch, err := api.CreateVectorStoreFileBatch(ctx, vectorStoreID, VectorStoreFileBatchRequest{
+		FileIDs:          fileIDs,
+		ChunkingStrategy: chunkingStrategy,
+	}, opts...)
+	if err != nil {
+		deleteVectorStoreFiles(api, fileIDs, opts)
+		return batch, err
+	}
+
+	interval := vectorStorePollInterval
+	for batch.Status == vectorStoreFileBatchInProgress || batch.FileCounts.InProgress > 0 {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return batch, ctx.Err()
+		case <-timer.C:
+		}
+		batch, err = api.RetrieveVectorStoreFileBatch(ctx, vectorStoreID, batch.ID, opts...)
+		if err != nil {
+			return batch, err
+		}
+		if interval *= 2; interval > vectorStorePollMaxInterval {
+			interval = vectorStorePollMaxInterval
+		}
+	}
+	return batch, nil
+}
+
+// uploadVectorStoreFiles uploads paths concurrently and returns the file IDs
+// in the order of paths. The first error stops the remaining uploads.
+func uploadVectorStoreFiles(
+	ctx context.Context,
+	api vectorStoreUploadAPI,
+	paths []string,
+	opts []RequestOption,
+) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fileIDs := make([]string, len(paths))
+	sem := make(chan struct{}, vectorStoreUploadConcurrency)
+	for i, path := range paths {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			file, uploadErr := api.CreateFile(ctx, FileRequest{
+				FileName: filepath.Base(path),
+				FilePath: path,
+				Purpose:  string(PurposeAssistants),
+			}, opts...)
+			if uploadErr != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("uploading %s: %w", path, uploadErr)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			fileIDs[i] = file.ID
+		}(i, path)
+	}
+	wg.Wait()
+
+	err := firstErr
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		deleteVectorStoreFiles(api, fileIDs, opts)
+		return nil, err
+	}
+	return fileIDs, nil
+}
+
+// deleteVectorStoreFiles deletes the uploaded files of a failed upload, so
+// they aren't left unattached in the account. It runs after ctx may have
+// ended, and the error of the upload is the one returned, so it has a
+// context and ignores errors of its own.
+func deleteVectorStoreFiles(api vectorStoreUploadAPI, fileIDs []string, opts []RequestOption) {
+	ctx, cancel := context.WithTimeout(context.Background(), vectorStoreFileDeleteTimeout)
+	defer cancel()
+	for _, id := range fileIDs {
+		if id != "" {
+			_ = api.DeleteFile(ctx, id, opts...)
+		}
+	}
+}
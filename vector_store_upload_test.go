@@ -0,0 +1,168 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func writeUploadFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		checks.NoError(t, os.WriteFile(path, []byte("content of "+name), 0o600), "WriteFile error")
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestUploadFilesAndPoll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var (
+		mu      sync.Mutex
+		polls   int
+		request openai.VectorStoreFileBatchRequest
+	)
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		if purpose := r.FormValue("purpose"); purpose != string(openai.PurposeAssistants) {
+			t.Errorf("unexpected purpose %q", purpose)
+		}
+		_, header, err := r.FormFile("file")
+		checks.NoError(t, err, "FormFile error")
+		fmt.Fprintf(w, `{"id":"file_%s"}`, strings.TrimSuffix(header.Filename, ".md"))
+	})
+	server.RegisterHandler("/v1/vector_stores/vs_1/file_batches", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"id":"vsfb_1","status":"in_progress","file_counts":{"in_progress":3,"total":3}}`)
+	})
+	server.RegisterHandler("/v1/vector_stores/vs_1/file_batches/vsfb_1", func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		polls++
+		mu.Unlock()
+		fmt.Fprint(w, `{"id":"vsfb_1","status":"completed","file_counts":{"completed":2,"failed":1,"total":3}}`)
+	})
+
+	chunking := &openai.ChunkingStrategy{
+		Type:   openai.ChunkingStrategyTypeStatic,
+		Static: &openai.StaticChunkingStrategy{MaxChunkSizeTokens: 800, ChunkOverlapTokens: 400},
+	}
+	paths := writeUploadFiles(t, "a.md", "b.md", "c.md")
+	batch, err := client.UploadFilesAndPoll(context.Background(), "vs_1", paths, chunking)
+	checks.NoError(t, err, "UploadFilesAndPoll error")
+
+	if !reflect.DeepEqual(request.FileIDs, []string{"file_a", "file_b", "file_c"}) {
+		t.Fatalf("expected the file IDs in the order of the paths, got %v", request.FileIDs)
+	}
+	if request.ChunkingStrategy == nil || request.ChunkingStrategy.Static.MaxChunkSizeTokens != 800 {
+		t.Fatalf("chunking strategy not sent: %+v", request.ChunkingStrategy)
+	}
+	if polls != 1 || batch.Status != "completed" || batch.FileCounts.Failed != 1 {
+		t.Fatalf("unexpected batch %+v after %d polls", batch, polls)
+	}
+}
+
+func TestUploadFilesAndPollUploadError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	batchCreated := false
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"unsupported file","type":"invalid_request_error"}}`)
+	})
+	server.RegisterHandler("/v1/vector_stores/vs_1/file_batches", func(w http.ResponseWriter, _ *http.Request) {
+		batchCreated = true
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := client.UploadFilesAndPoll(context.Background(), "vs_1", writeUploadFiles(t, "a.exe"), nil)
+	apiErr := &openai.APIError{}
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the upload APIError, got %v", err)
+	}
+	if batchCreated {
+		t.Fatal("no file batch should be created when an upload fails")
+	}
+
+	_, err = client.UploadFilesAndPoll(context.Background(), "vs_1", nil, nil)
+	if !errors.Is(err, openai.ErrVectorStoreNoFiles) {
+		t.Fatalf("expected ErrVectorStoreNoFiles, got %v", err)
+	}
+}
+
+func TestUploadFilesAndPollDeletesFilesOnFailure(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	server.RegisterHandler("/v1/files", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, r.ParseMultipartForm(1<<20), "ParseMultipartForm error")
+		_, header, err := r.FormFile("file")
+		checks.NoError(t, err, "FormFile error")
+		if strings.HasSuffix(header.Filename, ".exe") {
+			// Fail after the other uploads answered.
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"unsupported file","type":"invalid_request_error"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":"file_%s"}`, strings.TrimSuffix(header.Filename, ".md"))
+	})
+	server.RegisterHandler("/v1/files/*", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/v1/files/"))
+		}
+		fmt.Fprint(w, `{"deleted":true}`)
+	})
+	server.RegisterHandler("/v1/vector_stores/vs_1/file_batches", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"vector store is full","type":"invalid_request_error"}}`)
+	})
+	checkDeleted := func(want ...string) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		sort.Strings(deleted)
+		if !reflect.DeepEqual(deleted, want) {
+			t.Fatalf("expected %v to be deleted, got %v", want, deleted)
+		}
+		deleted = nil
+	}
+
+	// A failed upload deletes the files uploaded before it.
+	_, err := client.UploadFilesAndPoll(context.Background(), "vs_1", writeUploadFiles(t, "a.md", "z.exe"), nil)
+	if err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+	checkDeleted("file_a")
+
+	// A file batch which can't be created deletes every uploaded file.
+	_, err = client.UploadFilesAndPoll(context.Background(), "vs_1", writeUploadFiles(t, "a.md", "b.md"), nil)
+	if err == nil {
+		t.Fatal("expected the file batch to fail")
+	}
+	checkDeleted("file_a", "file_b")
+}